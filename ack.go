@@ -0,0 +1,62 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNoAckDeadline is returned by ExtendAckDeadline() if the context.Context has no ack deadline
+var ErrNoAckDeadline = errors.New("No ack deadline in context")
+
+type ackDeadlineCtxKey struct{}
+
+// ackDeadline is the ack deadline of a delivery from a message broker
+type ackDeadline struct {
+	lock     sync.Mutex
+	deadline time.Time
+	extend   func(ctx context.Context, by time.Duration) (time.Time, error)
+}
+
+// WithAckDeadline is used by bridges dispatching deliveries from a message broker to expose the delivery's ack
+// deadline, e.g. its visibility timeout, to the handlers through the returned context.Context, which should be
+// used to dispatch the delivery. extend is called by ExtendAckDeadline() to extend the deadline with the broker and
+// returns the new deadline. extend may be nil if the deadline can't be extended.
+func WithAckDeadline(ctx context.Context, deadline time.Time,
+	extend func(ctx context.Context, by time.Duration) (time.Time, error)) context.Context {
+	return context.WithValue(ctx, ackDeadlineCtxKey{}, &ackDeadline{deadline: deadline, extend: extend})
+}
+
+// AckDeadlineFromContext gets the current ack deadline of the delivery being handled. false is returned if the
+// event wasn't dispatched with an ack deadline. See WithAckDeadline()
+func AckDeadlineFromContext(ctx context.Context) (time.Time, bool) {
+	ad, ok := ctx.Value(ackDeadlineCtxKey{}).(*ackDeadline)
+	if !ok {
+		return time.Time{}, false
+	}
+	ad.lock.Lock()
+	defer ad.lock.Unlock()
+	return ad.deadline, true
+}
+
+// ExtendAckDeadline extends the ack deadline of the delivery being handled, so that long running handlers may keep
+// the broker from redelivering it while they're still running. The deadline is shared by all of the handlers of
+// the delivery.
+func ExtendAckDeadline(ctx context.Context, by time.Duration) error {
+	ad, ok := ctx.Value(ackDeadlineCtxKey{}).(*ackDeadline)
+	if !ok {
+		return ErrNoAckDeadline
+	}
+	if ad.extend == nil {
+		return errors.New("Ack deadline can't be extended")
+	}
+	ad.lock.Lock()
+	defer ad.lock.Unlock()
+	deadline, err := ad.extend(ctx, by)
+	if err != nil {
+		return err
+	}
+	ad.deadline = deadline
+	return nil
+}
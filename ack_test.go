@@ -0,0 +1,61 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestAckDeadline(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := thevent.AckDeadlineFromContext(ctx); ok {
+		t.Error("Context should not have an ack deadline")
+	}
+	if err := thevent.ExtendAckDeadline(ctx, time.Second); err != thevent.ErrNoAckDeadline {
+		t.Error("Unexpected error:", err)
+	}
+
+	start := time.Unix(0, 0)
+	var extended []time.Duration
+	handler := func(ctx context.Context, s testStruct) error {
+		deadline, ok := thevent.AckDeadlineFromContext(ctx)
+		if !ok || !deadline.Equal(start.Add(time.Minute)) {
+			t.Error("Unexpected ack deadline:", deadline, ok)
+		}
+		if err := thevent.ExtendAckDeadline(ctx, time.Minute); err != nil {
+			return err
+		}
+		if deadline, _ := thevent.AckDeadlineFromContext(ctx); !deadline.Equal(start.Add(2 * time.Minute)) {
+			t.Error("Unexpected extended ack deadline:", deadline)
+		}
+		return nil
+	}
+	e := thevent.Must(thevent.New(testStruct{}, handler))
+	deliveryCtx := thevent.WithAckDeadline(ctx, start.Add(time.Minute),
+		func(ctx context.Context, by time.Duration) (time.Time, error) {
+			extended = append(extended, by)
+			return start.Add(time.Minute + by), nil
+		})
+	res, err := e.DispatchWithResults(deliveryCtx, testStruct{})
+	if err != nil || res.Erred() {
+		t.Fatal("Unexpected error dispatching:", err, res.Errors)
+	}
+	if len(extended) != 1 || extended[0] != time.Minute {
+		t.Error("Unexpected extensions:", extended)
+	}
+
+	fixed := thevent.WithAckDeadline(ctx, start, nil)
+	errorMatchesGlob(t, thevent.ExtendAckDeadline(fixed, time.Minute), "Ack deadline can't be extended")
+	failing := thevent.WithAckDeadline(ctx, start, func(ctx context.Context, by time.Duration) (time.Time, error) {
+		return time.Time{}, errors.New("broker unavailable")
+	})
+	errorMatchesGlob(t, thevent.ExtendAckDeadline(failing, time.Minute), "broker unavailable")
+	if deadline, _ := thevent.AckDeadlineFromContext(failing); !deadline.Equal(start) {
+		t.Error("Ack deadline should not change when extending fails:", deadline)
+	}
+}
@@ -0,0 +1,143 @@
+package thevent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// AuditEntry is a single dispatch of a named Event in an audit log. Each entry's hash covers its contents and the
+// hash of the previous entry so that modifying, removing or reordering entries breaks the chain.
+type AuditEntry struct {
+	Seq      uint64          `json:"seq"`
+	Time     time.Time       `json:"time"`
+	Event    string          `json:"event"`
+	Data     json.RawMessage `json:"data"`
+	PrevHash string          `json:"prev_hash"`
+	Hash     string          `json:"hash"`
+}
+
+// hash computes the hash of the entry's contents and the previous entry's hash
+func (a AuditEntry) hash() (string, error) {
+	a.Hash = ""
+	b, err := json.Marshal(a)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// AuditLog appends the dispatches of named Events to a hash chained log of JSON lines, providing evidence that the
+// event trail wasn't modified. See VerifyAuditLog(). Event data must be serializable as JSON. The writer should be
+// append-only, e.g. a file opened with os.O_APPEND.
+type AuditLog struct {
+	lock     sync.Mutex
+	w        io.Writer
+	seq      uint64
+	prevHash string
+	err      error
+}
+
+// NewAuditLog creates a new AuditLog starting a new hash chain
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{w: w}
+}
+
+// ResumeAuditLog verifies an existing audit log and creates an AuditLog continuing its hash chain
+func ResumeAuditLog(w io.Writer, existing io.Reader) (*AuditLog, error) {
+	last, _, err := verifyAuditLog(existing)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLog{w: w, seq: last.Seq, prevHash: last.Hash}, nil
+}
+
+// Record appends every dispatch of the Event to the log under the given name, including dispatches made by a parent
+// Event. Dispatches are appended in the order in which they're made, before any of the Event's handlers are run.
+func (a *AuditLog) Record(name string, e *Event) {
+	e.addObserver(func(_ context.Context, data interface{}) {
+		a.append(name, data)
+	})
+}
+
+func (a *AuditLog) append(name string, data interface{}) {
+	b, err := json.Marshal(data)
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.err != nil {
+		// The chain is broken once an entry couldn't be appended
+		return
+	}
+	if err != nil {
+		a.err = fmt.Errorf("Unable to audit dispatch of event %q: %v", name, err)
+		return
+	}
+	entry := AuditEntry{Seq: a.seq + 1, Time: time.Now().UTC(), Event: name, Data: b, PrevHash: a.prevHash}
+	if entry.Hash, err = entry.hash(); err != nil {
+		a.err = fmt.Errorf("Unable to audit dispatch of event %q: %v", name, err)
+		return
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		a.err = fmt.Errorf("Unable to audit dispatch of event %q: %v", name, err)
+		return
+	}
+	if _, err := a.w.Write(append(line, '\n')); err != nil {
+		a.err = fmt.Errorf("Unable to write audit log: %v", err)
+		return
+	}
+	a.seq, a.prevHash = entry.Seq, entry.Hash
+}
+
+// Err returns the first error encountered while auditing, if any. No more dispatches are appended to the log once
+// an error has been encountered.
+func (a *AuditLog) Err() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.err
+}
+
+// VerifyAuditLog verifies the hash chain of an audit log written by an AuditLog and returns the number of verified
+// entries. An error describing the first invalid entry is returned if the log has been tampered with.
+func VerifyAuditLog(r io.Reader) (int, error) {
+	_, n, err := verifyAuditLog(r)
+	return n, err
+}
+
+func verifyAuditLog(r io.Reader) (AuditEntry, int, error) {
+	var prev AuditEntry
+	n := 0
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return prev, n, fmt.Errorf("Unable to read audit log line %d: %v", line, err)
+		}
+		if entry.Seq != prev.Seq+1 || entry.PrevHash != prev.Hash {
+			return prev, n, fmt.Errorf("Audit log broken at line %d: entry %d does not follow entry %d", line,
+				entry.Seq, prev.Seq)
+		}
+		hash, err := entry.hash()
+		if err != nil {
+			return prev, n, fmt.Errorf("Unable to hash audit log line %d: %v", line, err)
+		}
+		if hash != entry.Hash {
+			return prev, n, fmt.Errorf("Audit log tampered at line %d: hash mismatch for entry %d", line, entry.Seq)
+		}
+		prev = entry
+		n++
+	}
+	return prev, n, scanner.Err()
+}
@@ -0,0 +1,77 @@
+package thevent_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestAuditLog(t *testing.T) {
+	ctx := context.Background()
+	var log bytes.Buffer
+	audit := thevent.NewAuditLog(&log)
+	userEvent, err := thevent.New(fixtureUser{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	loginEvent, err := userEvent.New(fixtureLogin{}, "User")
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	audit.Record("user", userEvent)
+	audit.Record("user.login", loginEvent)
+	for _, u := range []fixtureUser{{ID: 1, Name: "Jimi"}, {ID: 2, Name: "Janis"}} {
+		if err := userEvent.Dispatch(ctx, u); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	}
+	if err := audit.Err(); err != nil {
+		t.Fatal("Unexpected error auditing:", err)
+	}
+	if n, err := thevent.VerifyAuditLog(bytes.NewReader(log.Bytes())); err != nil || n != 4 {
+		t.Fatal("Unable to verify audit log:", n, err)
+	}
+
+	// Resuming continues the hash chain
+	resumed, err := thevent.ResumeAuditLog(&log, bytes.NewReader(log.Bytes()))
+	if err != nil {
+		t.Fatal("Unable to resume audit log:", err)
+	}
+	resumedEvent := thevent.Must(thevent.New(fixtureUser{}))
+	resumed.Record("user", resumedEvent)
+	if err := resumedEvent.Dispatch(ctx, fixtureUser{ID: 3, Name: "Kurt"}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if n, err := thevent.VerifyAuditLog(bytes.NewReader(log.Bytes())); err != nil || n != 5 {
+		t.Fatal("Unable to verify resumed audit log:", n, err)
+	}
+
+	lines := strings.SplitAfter(log.String(), "\n")
+	testCases := []struct {
+		name        string
+		log         string
+		expectedErr string
+	}{
+		{name: "modified", log: strings.Replace(log.String(), "Janis", "Janet", 1),
+			expectedErr: "Audit log tampered at line 3: hash mismatch for entry 3"},
+		{name: "removed", log: lines[0] + strings.Join(lines[2:], ""),
+			expectedErr: "Audit log broken at line 2: entry 3 does not follow entry 1"},
+		{name: "reordered", log: lines[1] + lines[0] + strings.Join(lines[2:], ""),
+			expectedErr: "Audit log broken at line 1: entry 2 does not follow entry 0"},
+		{name: "invalid", log: "{", expectedErr: "Unable to read audit log line 1: *"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := thevent.VerifyAuditLog(strings.NewReader(tc.log))
+			errorMatchesGlob(t, err, tc.expectedErr)
+			if _, err := thevent.ResumeAuditLog(&bytes.Buffer{}, strings.NewReader(tc.log)); err == nil {
+				t.Error("Resumed tampered audit log")
+			}
+		})
+	}
+}
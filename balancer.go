@@ -0,0 +1,180 @@
+package thevent
+
+import (
+	"errors"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Balancer selects the single handler which is run for each dispatch of an Event, turning the Event into a
+// task-distribution point across its handlers. Balancers must be safe for concurrent use.
+type Balancer interface {
+	// Select returns the index of the handler to run for the dispatched data. Handlers are indexed in the order
+	// in which they were added to the Event. No handler is run if the index is out of range.
+	Select(data interface{}, numHandlers int) int
+}
+
+// WithBalancer configures the Event to only run the handler selected by the Balancer for each dispatch.
+// Sub-Events are still dispatched.
+func WithBalancer(b Balancer) Option {
+	return func(e *Event) error {
+		if v, ok := b.(interface{ validate() error }); ok {
+			if err := v.validate(); err != nil {
+				return err
+			}
+		}
+		e.balancer = b
+		return nil
+	}
+}
+
+// weight returns the weight of the handler with the given index. Handlers without a configured weight have a
+// weight of 1.
+func weight(weights []uint, i int) int64 {
+	if i < len(weights) {
+		return int64(weights[i])
+	}
+	return 1
+}
+
+type roundRobin struct {
+	lock    sync.Mutex
+	weights []uint
+	current []int64
+}
+
+// RoundRobin returns a Balancer that selects handlers using smooth weighted round-robin. weights are the weights
+// of the handlers in the order in which they were added. Handlers without a configured weight have a weight of 1
+// and handlers with a weight of 0 are never selected.
+func RoundRobin(weights ...uint) Balancer {
+	return &roundRobin{weights: weights}
+}
+
+func (b *roundRobin) Select(_ interface{}, numHandlers int) int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for len(b.current) < numHandlers {
+		b.current = append(b.current, 0)
+	}
+	selected := -1
+	var total int64
+	for i := 0; i < numHandlers; i++ {
+		w := weight(b.weights, i)
+		total += w
+		b.current[i] += w
+		if w > 0 && (selected < 0 || b.current[i] > b.current[selected]) {
+			selected = i
+		}
+	}
+	if selected >= 0 {
+		b.current[selected] -= total
+	}
+	return selected
+}
+
+type weightedRandom struct {
+	lock    sync.Mutex
+	weights []uint
+	rand    *rand.Rand
+}
+
+// WeightedRandom returns a Balancer that randomly selects handlers with a probability proportional to their weight.
+// weights are the weights of the handlers in the order in which they were added. Handlers without a configured
+// weight have a weight of 1 and handlers with a weight of 0 are never selected.
+func WeightedRandom(weights ...uint) Balancer {
+	return &weightedRandom{weights: weights, rand: rand.New(rand.NewSource(time.Now().UnixNano()))} // nolint: gosec
+}
+
+func (b *weightedRandom) Select(_ interface{}, numHandlers int) int {
+	var total int64
+	for i := 0; i < numHandlers; i++ {
+		total += weight(b.weights, i)
+	}
+	if total <= 0 {
+		return -1
+	}
+	b.lock.Lock()
+	n := b.rand.Int63n(total)
+	b.lock.Unlock()
+	for i := 0; i < numHandlers; i++ {
+		if n -= weight(b.weights, i); n < 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// default number of points each handler has on the consistent hash ring
+const defaultHashReplicas = 64
+
+type ringPoint struct {
+	hash    uint32
+	handler int
+}
+
+type consistentHash struct {
+	lock     sync.Mutex
+	key      func(data interface{}) string
+	replicas int
+	// ring is built for numHandlers handlers and is sorted by hash
+	ring        []ringPoint
+	numHandlers int
+}
+
+// ConsistentHash returns a Balancer that selects handlers by consistent hashing the key of the dispatched data, so
+// data with the same key is always handled by the same handler while the Event's handlers don't change. Adding a
+// handler only moves a fraction of the keys to the new handler. replicas is the number of points each handler has
+// on the hash ring, a default is used if replicas isn't positive. WithBalancer() fails if key is nil.
+func ConsistentHash(key func(data interface{}) string, replicas int) Balancer {
+	if replicas <= 0 {
+		replicas = defaultHashReplicas
+	}
+	return &consistentHash{key: key, replicas: replicas}
+}
+
+func (b *consistentHash) validate() error {
+	if b.key == nil {
+		return TypeError{errors.New("ConsistentHash key must not be nil")}
+	}
+	return nil
+}
+
+func hashString(s string) uint32 {
+	h := fnv.New64a()
+	h.Write([]byte(s)) // nolint: errcheck
+	// fold the 64-bit hash since the low bits of FNV hashes of similar short strings are poorly distributed
+	sum := h.Sum64()
+	sum ^= sum >> 33
+	sum *= 0xff51afd7ed558ccd
+	sum ^= sum >> 33
+	return uint32(sum)
+}
+
+func (b *consistentHash) Select(data interface{}, numHandlers int) int {
+	h := hashString(b.key(data))
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.numHandlers != numHandlers {
+		b.ring = make([]ringPoint, 0, numHandlers*b.replicas)
+		for i := 0; i < numHandlers; i++ {
+			for r := 0; r < b.replicas; r++ {
+				b.ring = append(b.ring, ringPoint{hash: hashString(strconv.Itoa(i) + "-" + strconv.Itoa(r)),
+					handler: i})
+			}
+		}
+		sort.Slice(b.ring, func(i, j int) bool { return b.ring[i].hash < b.ring[j].hash })
+		b.numHandlers = numHandlers
+	}
+	if len(b.ring) == 0 {
+		return -1
+	}
+	i := sort.Search(len(b.ring), func(i int) bool { return b.ring[i].hash >= h })
+	if i == len(b.ring) {
+		i = 0
+	}
+	return b.ring[i].handler
+}
@@ -0,0 +1,128 @@
+package thevent_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func newBalancedEvent(t *testing.T, b thevent.Balancer, counts []int) *thevent.Event {
+	e, err := thevent.New(0, thevent.WithBalancer(b),
+		func(ctx context.Context, i int) error { counts[0]++; return nil },
+		func(ctx context.Context, i int) error { counts[1]++; return nil },
+		func(ctx context.Context, i int) error { counts[2]++; return nil },
+	)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	return e
+}
+
+func TestRoundRobin(t *testing.T) {
+	testCases := []struct {
+		name     string
+		weights  []uint
+		expected []int
+	}{
+		{name: "no weights", expected: []int{2, 2, 2}},
+		{name: "partial weights", weights: []uint{4}, expected: []int{4, 1, 1}},
+		{name: "weights", weights: []uint{2, 1, 0}, expected: []int{4, 2, 0}},
+		{name: "zero weights", weights: []uint{0, 0, 0}, expected: []int{0, 0, 0}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			counts := make([]int, 3)
+			e := newBalancedEvent(t, thevent.RoundRobin(tc.weights...), counts)
+			expectedHandlers := uint(1)
+			if tc.expected[0]+tc.expected[1]+tc.expected[2] == 0 {
+				expectedHandlers = 0
+			}
+			for i := 0; i < 6; i++ {
+				res, err := e.DispatchWithResults(context.Background(), i)
+				if err != nil {
+					t.Fatal("Unexpected error dispatching:", err)
+				}
+				if res.NumHandlers != expectedHandlers {
+					t.Error("Expected", expectedHandlers, "handlers to be dispatched, not", res.NumHandlers)
+				}
+			}
+			for i := range counts {
+				if counts[i] != tc.expected[i] {
+					t.Error("Handlers were called:", counts, "expected:", tc.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestWeightedRandom(t *testing.T) {
+	counts := make([]int, 3)
+	e := newBalancedEvent(t, thevent.WeightedRandom(3, 1, 0), counts)
+	for i := 0; i < 1000; i++ {
+		if err := e.Dispatch(context.Background(), i); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	}
+	if counts[0]+counts[1]+counts[2] != 1000 {
+		t.Error("Expected a single handler to be called per dispatch, called:", counts)
+	}
+	if counts[0] <= counts[1] || counts[1] == 0 || counts[2] != 0 {
+		t.Error("Handlers weren't called according to their weights:", counts)
+	}
+
+	counts = make([]int, 3)
+	e = newBalancedEvent(t, thevent.WeightedRandom(0, 0, 0), counts)
+	if err := e.Dispatch(context.Background(), 1); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if counts[0]+counts[1]+counts[2] != 0 {
+		t.Error("Handlers with a weight of 0 shouldn't be called:", counts)
+	}
+}
+
+func TestConsistentHash(t *testing.T) {
+	b := thevent.ConsistentHash(func(data interface{}) string { return data.(string) }, 0)
+	if i := b.Select("key", 0); i != -1 {
+		t.Error("Selected handler:", i, "without any handlers")
+	}
+	_, err := thevent.New("", thevent.WithBalancer(thevent.ConsistentHash(nil, 0)))
+	errorMatchesGlob(t, err, "ConsistentHash key must not be nil")
+
+	keys := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, fmt.Sprint("entity-", i))
+	}
+	selected := make(map[string]int, len(keys))
+	counts := make([]int, 4)
+	for _, k := range keys {
+		i := b.Select(k, 4)
+		selected[k] = i
+		counts[i]++
+		if again := b.Select(k, 4); again != i {
+			t.Fatal("Key:", k, "selected different handlers:", i, again)
+		}
+	}
+	for i, c := range counts {
+		if c == 0 {
+			t.Error("Handler", i, "was never selected:", counts)
+		}
+	}
+
+	moved := 0
+	for _, k := range keys {
+		i := b.Select(k, 5)
+		if i != selected[k] {
+			if i != 4 {
+				t.Error("Key:", k, "moved to an existing handler:", i)
+			}
+			moved++
+		}
+	}
+	if moved == 0 || moved > len(keys)/2 {
+		t.Error("Unexpected number of keys moved after adding a handler:", moved)
+	}
+}
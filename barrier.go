@@ -0,0 +1,91 @@
+package thevent
+
+import (
+	"context"
+	"sync"
+)
+
+// asyncTracker tracks the asynchronously run handlers of an Event
+type asyncTracker struct {
+	lock    sync.Mutex
+	nextSeq uint64
+	pending map[uint64]struct{}
+	waiters []*barrierWaiter
+}
+
+// barrierWaiter waits for the pending handlers started before target to finish
+type barrierWaiter struct {
+	target    uint64
+	remaining int
+	done      chan struct{}
+}
+
+// start records that a handler has started running asynchronously
+func (t *asyncTracker) start() uint64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.pending == nil {
+		t.pending = map[uint64]struct{}{}
+	}
+	seq := t.nextSeq
+	t.nextSeq++
+	t.pending[seq] = struct{}{}
+	return seq
+}
+
+// finish records that the handler started with the given sequence number has finished running
+func (t *asyncTracker) finish(seq uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.pending, seq)
+	waiters := t.waiters[:0]
+	for _, w := range t.waiters {
+		if seq < w.target {
+			w.remaining--
+		}
+		if w.remaining <= 0 {
+			close(w.done)
+			continue
+		}
+		waiters = append(waiters, w)
+	}
+	t.waiters = waiters
+}
+
+// barrier returns a channel which is closed once all of the currently pending handlers have finished running
+func (t *asyncTracker) barrier() <-chan struct{} {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	w := &barrierWaiter{target: t.nextSeq, remaining: len(t.pending), done: make(chan struct{})}
+	if w.remaining == 0 {
+		close(w.done)
+		return w.done
+	}
+	t.waiters = append(t.waiters, w)
+	return w.done
+}
+
+// barriers returns the barriers for the Event and all of its descendants
+func (e *Event) barriers() []<-chan struct{} {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	barriers := []<-chan struct{}{e.async.barrier()}
+	for _, c := range e.children {
+		barriers = append(barriers, c.event.barriers()...)
+	}
+	return barriers
+}
+
+// Barrier blocks until all of the handlers of the Event and its sub-Events, which were run asynchronously by
+// dispatches made before Barrier was called, have finished running. Handlers of later dispatches aren't waited on.
+// The context.Context's error is returned if it's done before the handlers have finished.
+func (e *Event) Barrier(ctx context.Context) error {
+	for _, b := range e.barriers() {
+		select {
+		case <-b:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+type barrierData struct {
+	release chan struct{}
+}
+
+func TestBarrier(t *testing.T) {
+	wait := func(ctx context.Context, d barrierData) error { // nolint: unparam
+		<-d.release
+		return nil
+	}
+	childWait := func(ctx context.Context, d barrierData) error { // nolint: unparam
+		<-d.release
+		return nil
+	}
+	e, err := thevent.New(barrierData{}, wait)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if _, err := e.New(barrierData{}, "", childWait); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	ctx := context.Background()
+
+	if err := e.Barrier(ctx); err != nil {
+		t.Fatal("Unexpected error waiting without any pending handlers:", err)
+	}
+
+	first := barrierData{release: make(chan struct{})}
+	if err := e.DispatchAsync(ctx, first); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := e.Barrier(timeoutCtx); err != context.DeadlineExceeded {
+		t.Fatal("Expected Barrier to time out waiting for pending handlers, got:", err)
+	}
+
+	barrierDone := make(chan error)
+	go func() { barrierDone <- e.Barrier(ctx) }()
+	// handlers of dispatches made after Barrier was called aren't waited on
+	time.Sleep(10 * time.Millisecond)
+	second := barrierData{release: make(chan struct{})}
+	if err := e.DispatchAsync(ctx, second); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	select {
+	case err := <-barrierDone:
+		t.Fatal("Barrier returned before pending handlers finished:", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(first.release)
+	select {
+	case err := <-barrierDone:
+		if err != nil {
+			t.Error("Unexpected error waiting for pending handlers:", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Barrier didn't return after pending handlers finished")
+	}
+	close(second.release)
+	if err := e.Barrier(ctx); err != nil {
+		t.Error("Unexpected error waiting for pending handlers:", err)
+	}
+}
@@ -0,0 +1,108 @@
+package thevent
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// BatchError is returned by DispatchBatch when dispatching some of the items of the batch failed. The handlers'
+// errors are returned in the results instead.
+type BatchError struct {
+	// Errs maps the index of every item whose dispatch failed to the dispatch error
+	Errs map[int]error
+}
+
+func (e BatchError) Error() string {
+	indexes := make([]int, 0, len(e.Errs))
+	for i := range e.Errs {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return fmt.Sprintf("Dispatching %d batch items failed. First failed item %d: %v", len(e.Errs), indexes[0],
+		e.Errs[indexes[0]])
+}
+
+// WithBatchConcurrency dispatches up to n items of a batch concurrently. See Event.DispatchBatch()
+func WithBatchConcurrency(n int) DispatchOption {
+	return func(d *dispatchState) {
+		d.batchConcurrency = n
+	}
+}
+
+// DispatchBatch synchronously dispatches every item of data, which must be a slice of the Event's data type, like
+// DispatchWithResults. The data type is validated and the dispatch options are applied once for the whole batch,
+// instead of for every item. Items are dispatched one at a time in order, unless WithBatchConcurrency() is used.
+//
+// The results of all of the items are aggregated. Items which can't be dispatched, e.g. because they were shed,
+// are reported by a BatchError and don't stop the rest of the batch. The remaining items are skipped once the
+// context.Context is done.
+func (e *Event) DispatchBatch(ctx context.Context, data interface{}, opts ...DispatchOption) (*HandlersResults,
+	error) {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice || v.Type().Elem() != e.dataType {
+		return nil, TypeError{fmt.Errorf("DispatchBatch called with incorrect event data type. Expected: []%s Got: %v",
+			e.dataType.String(), reflect.TypeOf(data))}
+	}
+	return e.dispatchBatch(ctx, v.Len(), func(i int) interface{} { return v.Index(i).Interface() }, opts)
+}
+
+// dispatchBatch dispatches the n items returned by item
+func (e *Event) dispatchBatch(ctx context.Context, n int, item func(i int) interface{},
+	opts []DispatchOption) (*HandlersResults, error) {
+	base := newDispatchState(false, true, opts)
+	results := &HandlersResults{}
+	var errs map[int]error
+	var lock sync.Mutex
+	dispatchItem := func(i int) {
+		data := item(i)
+		var res *HandlersResults
+		err := ctx.Err()
+		if err == nil {
+			d := *base
+			res, _, err = e.dispatchRoot(ctx, &d, data)
+			e.reportDispatchErr(ctx, data, err)
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		if res != nil {
+			results.NumHandlers += res.NumHandlers
+			results.NumSkipped += res.NumSkipped
+			results.Errors = append(results.Errors, res.Errors...)
+			res.Release()
+		}
+		if err != nil {
+			if errs == nil {
+				errs = map[int]error{}
+			}
+			errs[i] = err
+		}
+	}
+
+	if base.batchConcurrency <= 1 {
+		for i := 0; i < n; i++ {
+			dispatchItem(i)
+		}
+	} else {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, base.batchConcurrency)
+		for i := 0; i < n; i++ {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(i int) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				dispatchItem(i)
+			}(i)
+		}
+		wg.Wait()
+	}
+	if len(errs) > 0 {
+		return results, BatchError{Errs: errs}
+	}
+	return results, nil
+}
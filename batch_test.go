@@ -0,0 +1,71 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestDispatchBatch(t *testing.T) {
+	var lock sync.Mutex
+	var handled []int
+	handler := func(ctx context.Context, i int) error {
+		lock.Lock()
+		defer lock.Unlock()
+		handled = append(handled, i)
+		if i%2 == 1 {
+			return errors.New("odd")
+		}
+		return nil
+	}
+	e := thevent.Must(thevent.New(0, handler, thevent.WithMaxDataSize(2, nil)))
+	ctx := context.Background()
+
+	testCases := []struct {
+		name string
+		opts []thevent.DispatchOption
+	}{
+		{name: "sequential"},
+		{name: "concurrent", opts: []thevent.DispatchOption{thevent.WithBatchConcurrency(3)}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handled = nil
+			res, err := e.DispatchBatch(ctx, []int{1, 2, 3, 4, 100, 5}, tc.opts...)
+			batchErr, ok := err.(thevent.BatchError)
+			if !ok || len(batchErr.Errs) != 1 {
+				t.Fatal("Expected BatchError, got:", err)
+			}
+			if _, ok := batchErr.Errs[4].(thevent.DataTooLargeError); !ok {
+				t.Error("Expected oversized item to fail, got:", batchErr.Errs)
+			}
+			errorMatchesGlob(t, err, "Dispatching 1 batch items failed. First failed item 4: Event data size 3 bytes*")
+			if res.NumHandlers != 5 || len(res.Errors) != 3 {
+				t.Error("Unexpected results:", res)
+			}
+			if len(handled) != 5 {
+				t.Error("Unexpected handled items:", handled)
+			}
+			if tc.opts == nil && (handled[0] != 1 || handled[4] != 5) {
+				t.Error("Items handled out of order:", handled)
+			}
+		})
+	}
+
+	_, err := e.DispatchBatch(ctx, []string{"a"})
+	errorMatchesGlob(t, err, "DispatchBatch called with incorrect event data type. Expected: \\[]int Got: \\[]string")
+	_, err = e.DispatchBatch(ctx, 1)
+	errorMatchesGlob(t, err, "DispatchBatch called with incorrect event data type. Expected: \\[]int Got: int")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = e.DispatchBatch(canceled, []int{2, 4})
+	if batchErr, ok := err.(thevent.BatchError); !ok || len(batchErr.Errs) != 2 || batchErr.Errs[0] != context.Canceled {
+		t.Error("Expected canceled items to fail, got:", err)
+	}
+}
@@ -0,0 +1,103 @@
+package thevent
+
+import (
+	"context"
+	"reflect"
+)
+
+// BoundEvent dispatches an Event with data that's merged with partially applied data. See Event.Bind()
+type BoundEvent struct {
+	event   *Event
+	partial interface{}
+	merge   func(partial, data interface{}) interface{}
+}
+
+// Bind pre-fills the data of the Event's dispatches with the partial data so that dispatch sites only need to
+// provide the fields that vary, e.g. for events with large envelopes of mostly static context. The data of each
+// dispatch is merged with the partial data by filling in the zero valued exported fields of the dispatched struct,
+// or pointer to a struct, with the fields of the partial data. Unexported fields aren't merged. For other data
+// types, the partial data is used if the dispatched data is the zero value. Use BindFunc() to merge data
+// differently.
+func (e *Event) Bind(partial interface{}) *BoundEvent {
+	return e.BindFunc(partial, mergeFields)
+}
+
+// BindFunc is the same as Bind but merges the partial data and the data of each dispatch using the merge function
+func (e *Event) BindFunc(partial interface{}, merge func(partial, data interface{}) interface{}) *BoundEvent {
+	return &BoundEvent{event: e, partial: partial, merge: merge}
+}
+
+// Event gets the bound Event
+func (b *BoundEvent) Event() *Event {
+	return b.event
+}
+
+// Data merges the partial data with the given data
+func (b *BoundEvent) Data(data interface{}) interface{} {
+	return b.merge(b.partial, data)
+}
+
+// Dispatch dispatches the Event with the merged data. See Event.Dispatch()
+func (b *BoundEvent) Dispatch(ctx context.Context, data interface{}, opts ...DispatchOption) error {
+	return b.event.Dispatch(ctx, b.Data(data), opts...)
+}
+
+// DispatchWithResults dispatches the Event with the merged data. See Event.DispatchWithResults()
+func (b *BoundEvent) DispatchWithResults(ctx context.Context, data interface{},
+	opts ...DispatchOption) (*HandlersResults, error) {
+	return b.event.DispatchWithResults(ctx, b.Data(data), opts...)
+}
+
+// DispatchAsync dispatches the Event with the merged data. See Event.DispatchAsync()
+func (b *BoundEvent) DispatchAsync(ctx context.Context, data interface{}, opts ...DispatchOption) error {
+	return b.event.DispatchAsync(ctx, b.Data(data), opts...)
+}
+
+// DispatchAsyncWithResults dispatches the Event with the merged data. See Event.DispatchAsyncWithResults()
+func (b *BoundEvent) DispatchAsyncWithResults(ctx context.Context, data interface{},
+	opts ...DispatchOption) (<-chan error, error) {
+	return b.event.DispatchAsyncWithResults(ctx, b.Data(data), opts...)
+}
+
+// mergeFields fills in the zero valued exported fields of the data with the fields of the partial data. The data
+// is returned as is if the types of the data and partial data differ so that the dispatch fails the type check.
+func mergeFields(partial, data interface{}) interface{} {
+	p, d := reflect.ValueOf(partial), reflect.ValueOf(data)
+	if !p.IsValid() {
+		return data
+	}
+	if !d.IsValid() {
+		return partial
+	}
+	if p.Type() != d.Type() {
+		return data
+	}
+	isPtr := d.Kind() == reflect.Ptr
+	if isPtr {
+		if d.IsNil() {
+			return partial
+		}
+		if p.IsNil() {
+			return data
+		}
+		p, d = p.Elem(), d.Elem()
+	}
+	if d.Kind() != reflect.Struct {
+		if d.IsZero() {
+			return partial
+		}
+		return data
+	}
+	// Copy the data so that neither the data nor the partial data is modified
+	merged := reflect.New(d.Type()).Elem()
+	merged.Set(d)
+	for i := 0; i < merged.NumField(); i++ {
+		if f := merged.Field(i); f.CanSet() && f.IsZero() {
+			f.Set(p.Field(i))
+		}
+	}
+	if isPtr {
+		return merged.Addr().Interface()
+	}
+	return merged.Interface()
+}
@@ -0,0 +1,87 @@
+package thevent_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+type envelope struct {
+	Service string
+	Region  string
+	Action  string
+	Count   int
+	private string
+}
+
+func TestBind(t *testing.T) {
+	partial := envelope{Service: "orders", Region: "eu", Action: "default", private: "partial"}
+	testCases := []struct {
+		name     string
+		partial  interface{}
+		data     interface{}
+		expected interface{}
+	}{
+		{name: "struct", partial: partial, data: envelope{Action: "created", Count: 2, private: "data"},
+			expected: envelope{Service: "orders", Region: "eu", Action: "created", Count: 2, private: "data"}},
+		{name: "struct overrides", partial: partial, data: envelope{Region: "us"},
+			expected: envelope{Service: "orders", Region: "us", Action: "default"}},
+		{name: "pointer", partial: &partial, data: &envelope{Count: 1},
+			expected: &envelope{Service: "orders", Region: "eu", Action: "default", Count: 1}},
+		{name: "nil pointer", partial: &partial, data: (*envelope)(nil), expected: &partial},
+		{name: "zero", partial: 5, data: 0, expected: 5},
+		{name: "non-zero", partial: 5, data: 3, expected: 3},
+		{name: "mismatched type", partial: 5, data: "a", expected: "a"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := thevent.Must(thevent.New(tc.data))
+			got := e.Bind(tc.partial).Data(tc.data)
+			if fmt.Sprintf("%+v", got) != fmt.Sprintf("%+v", tc.expected) {
+				t.Errorf("Expected: %+v got: %+v", tc.expected, got)
+			}
+		})
+	}
+	if partial.Count != 0 || partial.Action != "default" {
+		t.Error("Partial data modified:", partial)
+	}
+}
+
+func TestBoundEventDispatch(t *testing.T) {
+	var got []envelope
+	handler := func(ctx context.Context, e envelope) error { // nolint: unparam
+		got = append(got, e)
+		return nil
+	}
+	e := thevent.Must(thevent.New(envelope{}, handler))
+	bound := e.Bind(envelope{Service: "orders"})
+	if bound.Event() != e {
+		t.Error("Unexpected bound event")
+	}
+	ctx := context.Background()
+	if err := bound.Dispatch(ctx, envelope{Action: "created"}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if _, err := bound.DispatchWithResults(ctx, envelope{Action: "updated"}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	err := bound.Dispatch(ctx, 1)
+	errorMatchesGlob(t, err, "Dispatch called with incorrect event data type.*")
+
+	concat := e.BindFunc(envelope{Action: "order."}, func(partial, data interface{}) interface{} {
+		d := data.(envelope)
+		d.Action = partial.(envelope).Action + d.Action
+		return d
+	})
+	if err := concat.Dispatch(ctx, envelope{Action: "deleted"}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(got) != 3 || got[0] != (envelope{Service: "orders", Action: "created"}) ||
+		got[1] != (envelope{Service: "orders", Action: "updated"}) || got[2] != (envelope{Action: "order.deleted"}) {
+		t.Error("Unexpected dispatches:", got)
+	}
+}
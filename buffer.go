@@ -0,0 +1,84 @@
+package thevent
+
+import (
+	"context"
+	"sync"
+)
+
+// DispatchBuffer queues dispatches locally until they're committed. e.g. to only dispatch the events of a
+// unit-of-work once it succeeds. A DispatchBuffer may be reused once it's been committed or rolled back.
+type DispatchBuffer struct {
+	lock       sync.Mutex
+	dispatches []bufferedDispatch
+}
+
+// bufferedDispatch is a queued dispatch
+type bufferedDispatch struct {
+	event *Event
+	data  interface{}
+	async bool
+	opts  []DispatchOption
+}
+
+// Buffer creates a new empty DispatchBuffer
+func Buffer() *DispatchBuffer {
+	return &DispatchBuffer{}
+}
+
+// Dispatch queues a dispatch of the Event which is made using Event.Dispatch() on Commit(). The data is checked
+// immediately, so a TypeError is returned if the data can't be dispatched by the Event.
+func (b *DispatchBuffer) Dispatch(e *Event, data interface{}, opts ...DispatchOption) error {
+	return b.queue(bufferedDispatch{event: e, data: data, opts: opts})
+}
+
+// DispatchAsync is the same as Dispatch but the dispatch is made using Event.DispatchAsync() on Commit()
+func (b *DispatchBuffer) DispatchAsync(e *Event, data interface{}, opts ...DispatchOption) error {
+	return b.queue(bufferedDispatch{event: e, data: data, async: true, opts: opts})
+}
+
+func (b *DispatchBuffer) queue(d bufferedDispatch) error {
+	if err := d.event.checkDataType(d.data); err != nil {
+		return err
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.dispatches = append(b.dispatches, d)
+	return nil
+}
+
+// Len returns the number of queued dispatches
+func (b *DispatchBuffer) Len() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return len(b.dispatches)
+}
+
+// Commit makes the queued dispatches in the order in which they were queued and empties the DispatchBuffer. All of
+// the dispatches are made and the first error is returned.
+func (b *DispatchBuffer) Commit(ctx context.Context) error {
+	b.lock.Lock()
+	dispatches := b.dispatches
+	b.dispatches = nil
+	b.lock.Unlock()
+
+	var firstErr error
+	for _, d := range dispatches {
+		var err error
+		if d.async {
+			err = d.event.DispatchAsync(ctx, d.data, d.opts...)
+		} else {
+			err = d.event.Dispatch(ctx, d.data, d.opts...)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Rollback drops the queued dispatches
+func (b *DispatchBuffer) Rollback() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.dispatches = nil
+}
@@ -0,0 +1,56 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestBuffer(t *testing.T) {
+	var got []int
+	handler := func(ctx context.Context, i int) error { // nolint: unparam
+		got = append(got, i)
+		return nil
+	}
+	e := thevent.Must(thevent.New(0, handler))
+	ctx := context.Background()
+
+	b := thevent.Buffer()
+	errorMatchesGlob(t, b.Dispatch(e, "wrong"), "Dispatch called with incorrect event data type.*")
+	for i := 1; i <= 3; i++ {
+		if err := b.Dispatch(e, i); err != nil {
+			t.Fatal("Unable to queue dispatch:", err)
+		}
+	}
+	if len(got) != 0 || b.Len() != 3 {
+		t.Fatal("Dispatches should be queued. Handled:", got, "queued:", b.Len())
+	}
+	b.Rollback()
+	if err := b.Commit(ctx); err != nil {
+		t.Fatal("Unexpected error committing:", err)
+	}
+	if len(got) != 0 {
+		t.Error("Rolled back dispatches were made:", got)
+	}
+
+	for i := 4; i <= 5; i++ {
+		if err := b.Dispatch(e, i); err != nil {
+			t.Fatal("Unable to queue dispatch:", err)
+		}
+	}
+	if err := b.DispatchAsync(e, 6); err != nil {
+		t.Fatal("Unable to queue dispatch:", err)
+	}
+	if err := b.Commit(ctx); err != nil {
+		t.Fatal("Unexpected error committing:", err)
+	}
+	if err := e.Barrier(ctx); err != nil {
+		t.Fatal("Unexpected error waiting for handlers:", err)
+	}
+	if len(got) != 3 || got[0] != 4 || got[1] != 5 || got[2] != 6 || b.Len() != 0 {
+		t.Error("Unexpected committed dispatches:", got)
+	}
+}
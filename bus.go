@@ -0,0 +1,225 @@
+package thevent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Healther may be implemented by a handler to report whether it's able to handle events. e.g. whether the
+// handler's external dependencies are reachable. Handlers are functions, so a named function type is needed to
+// implement Healther. e.g.
+//
+//	type orderHandler func(ctx context.Context, o Order) error
+//	func (h orderHandler) Health(ctx context.Context) error { return db.PingContext(ctx) }
+type Healther interface {
+	Health(ctx context.Context) error
+}
+
+// Warmer may be implemented by a handler to prepare for handling events. e.g. opening connections or priming
+// caches. See Healther for how a handler may implement Warmer.
+type Warmer interface {
+	Warmup(ctx context.Context) error
+}
+
+// Bus is a registry of named Events
+type Bus struct {
+	lock   sync.RWMutex
+	events map[string]*Event
+	// names are stored in registration order
+	names       []string
+	sequencer   *sequencer
+	resultSinks []ResultSink
+	// id identifies the Bus to federated Buses
+	id         string
+	shedder    *LoadShedder
+	namespaces []namespaceOptions
+	retryQueue *RetryQueue
+	// middlewares run around the handlers of all of the Bus's Events
+	middlewares []Middleware
+}
+
+// BusOption configures a Bus
+type BusOption func(*Bus)
+
+// NewBus creates a new empty Bus
+func NewBus(opts ...BusOption) *Bus {
+	b := &Bus{events: map[string]*Event{}}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// New creates a new Event and registers it on the Bus with the given name. See New()
+//
+// Names may be hierarchical, see Namespace. The default Options of the Event's namespaces are applied first. See
+// WithNamespaceOptions()
+func (b *Bus) New(name string, data interface{}, handlers ...Handler) (*Event, error) {
+	e, err := New(data, b.withNamespaceOptions(name, handlers)...)
+	if err != nil {
+		return nil, err
+	}
+	e.bus, e.busName = b, name
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if _, ok := b.events[name]; ok {
+		return nil, TypeError{fmt.Errorf("Event already registered with name: %s", name)}
+	}
+	b.events[name] = e
+	b.names = append(b.names, name)
+	return e, nil
+}
+
+// Event gets the Event registered with the given name
+func (b *Bus) Event(name string) (*Event, bool) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	e, ok := b.events[name]
+	return e, ok
+}
+
+// Names gets the names of the registered Events in registration order
+func (b *Bus) Names() []string {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return append([]string(nil), b.names...)
+}
+
+// unregister removes the Event if it's registered with the given name
+func (b *Bus) unregister(name string, e *Event) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.events[name] != e {
+		return
+	}
+	delete(b.events, name)
+	for i, n := range b.names {
+		if n == name {
+			b.names = append(b.names[:i:i], b.names[i+1:]...)
+			break
+		}
+	}
+}
+
+// registered gets the registered Events in registration order
+func (b *Bus) registered() ([]string, []*Event) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	events := make([]*Event, 0, len(b.names))
+	for _, name := range b.names {
+		events = append(events, b.events[name])
+	}
+	return append([]string(nil), b.names...), events
+}
+
+// HealthCheck checks the health of every handler implementing Healther of the registered Events and their
+// sub-Events. All of the handlers are checked and a HealthCheckError is returned if any of them are unhealthy.
+func (b *Bus) HealthCheck(ctx context.Context) error {
+	var hcErr HealthCheckError
+	names, events := b.registered()
+	for i, e := range events {
+		for _, h := range e.allHandlers() {
+			if h.healther == nil {
+				continue
+			}
+			if err := h.healther.Health(ctx); err != nil {
+				hcErr = append(hcErr, UnhealthyHandler{Event: names[i], Handler: h.fn.Interface(), Err: err})
+			}
+		}
+	}
+	if len(hcErr) > 0 {
+		return hcErr
+	}
+	return nil
+}
+
+// Warmup warms up every handler implementing Warmer of the registered Events and their sub-Events. Handlers are
+// warmed up sequentially in registration order and the first error is returned. Warmup should be called before
+// any Events are dispatched.
+func (b *Bus) Warmup(ctx context.Context) error {
+	names, events := b.registered()
+	for i, e := range events {
+		for _, h := range e.allHandlers() {
+			if h.warmer == nil {
+				continue
+			}
+			if err := h.warmer.Warmup(ctx); err != nil {
+				return fmt.Errorf("Unable to warm up handler of event %s: %v", names[i], err)
+			}
+		}
+	}
+	return nil
+}
+
+// allHandlers gets the handlers of the Event and all of its descendants
+func (e *Event) allHandlers() []*handler {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	handlers := append([]*handler(nil), e.handlers...)
+	for _, c := range e.children {
+		handlers = append(handlers, c.event.allHandlers()...)
+	}
+	return handlers
+}
+
+// UnhealthyHandler is a failed health check of a handler of the named Event or one of its sub-Events
+type UnhealthyHandler struct {
+	Event   string
+	Handler Handler
+	Err     error
+}
+
+func (u UnhealthyHandler) Error() string {
+	return "Unhealthy handler of event " + u.Event + ": " + u.Err.Error()
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (u UnhealthyHandler) MarshalText() ([]byte, error) {
+	return []byte(u.Error()), nil
+}
+
+// MarshalJSON implements json.Marshaler. UnhealthyHandlers are marshaled as a JSON object with the kind, event,
+// handler and message of the error.
+func (u UnhealthyHandler) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.structured())
+}
+
+func (u UnhealthyHandler) structured() structuredError {
+	return structuredError{Kind: "UnhealthyHandler", Event: u.Event, Handler: handlerName(u.Handler),
+		Message: u.Err.Error()}
+}
+
+// HealthCheckError combines the failed health checks of a Bus.HealthCheck()
+type HealthCheckError []UnhealthyHandler
+
+func (hce HealthCheckError) Error() string {
+	quoted := make([]string, 0, len(hce))
+	for _, u := range hce {
+		quoted = append(quoted, strconv.Quote(u.Error()))
+	}
+	return "HealthCheckError: [" + strings.Join(quoted, ", ") + "]"
+}
+
+// MarshalText implements encoding.TextMarshaler. The messages of the errors are joined by semicolons.
+func (hce HealthCheckError) MarshalText() ([]byte, error) {
+	msgs := make([]string, 0, len(hce))
+	for _, u := range hce {
+		msgs = append(msgs, u.Error())
+	}
+	return []byte(strings.Join(msgs, "; ")), nil
+}
+
+// MarshalJSON implements json.Marshaler. HealthCheckErrors are marshaled as a JSON object with the kind of the
+// error and the structured failed health checks.
+func (hce HealthCheckError) MarshalJSON() ([]byte, error) {
+	text, _ := hce.MarshalText()
+	s := structuredError{Kind: "HealthCheckError", Message: string(text)}
+	for _, u := range hce {
+		s.Errors = append(s.Errors, u.structured())
+	}
+	return json.Marshal(s)
+}
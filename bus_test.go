@@ -0,0 +1,210 @@
+package thevent_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+type checkedHandler func(ctx context.Context, s testStruct) error
+
+func (h checkedHandler) Health(ctx context.Context) error { return h(ctx, testStruct{v: -1}) }
+
+func (h checkedHandler) Warmup(ctx context.Context) error { return h(ctx, testStruct{v: -2}) }
+
+func TestBusNew(t *testing.T) {
+	b := thevent.NewBus()
+	e, err := b.New("a", testStruct{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	_, err = b.New("a", testStruct{})
+	errorMatchesGlob(t, err, "Event already registered with name: a")
+	_, err = b.New("b", testStruct{}, func(ctx context.Context, i int) error { return nil })
+	errorMatchesGlob(t, err, "Handler uses incorrect data type.*")
+	if got, ok := b.Event("a"); !ok || got != e {
+		t.Error("Unable to get registered event")
+	}
+	if _, ok := b.Event("b"); ok {
+		t.Error("Event should not be registered")
+	}
+	if names := b.Names(); len(names) != 1 || names[0] != "a" {
+		t.Error("Unexpected names:", names)
+	}
+}
+
+func TestBusHealthCheckAndWarmup(t *testing.T) {
+	var warmed []string
+	healthy := checkedHandler(func(ctx context.Context, s testStruct) error { // nolint: unparam
+		if s.v == -2 {
+			warmed = append(warmed, "healthy")
+		}
+		return nil
+	})
+	unhealthy := checkedHandler(func(ctx context.Context, s testStruct) error {
+		if s.v == -2 {
+			warmed = append(warmed, "unhealthy")
+			return nil
+		}
+		return errors.New("db unreachable")
+	})
+	called := false
+	plain := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		called = true
+		return nil
+	}
+
+	b := thevent.NewBus()
+	e, err := b.New("a", testStruct{}, healthy, plain)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	ctx := context.Background()
+	if err := b.HealthCheck(ctx); err != nil {
+		t.Error("Unexpected health check error:", err)
+	}
+	if _, err := e.New(testStruct{}, "", unhealthy); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	hcErr := b.HealthCheck(ctx)
+	errorMatchesGlob(t, hcErr, `HealthCheckError: \["Unhealthy handler of event a: db unreachable"\]`)
+	if b, err := json.Marshal(hcErr); err != nil {
+		t.Error("Unable to marshal health check error:", err)
+	} else if expected := `{"kind":"HealthCheckError","message":"Unhealthy handler of event a: db unreachable",` +
+		`"errors":[{"kind":"UnhealthyHandler","event":"a",` +
+		`"handler":"github.com/dhui/thevent_test.TestBusHealthCheckAndWarmup.func2",` +
+		`"message":"db unreachable"}]}`; string(b) != expected {
+		t.Error("Got JSON:", string(b), "instead of:", expected)
+	}
+	if err := b.Warmup(ctx); err != nil {
+		t.Error("Unexpected warmup error:", err)
+	}
+	if len(warmed) != 2 || warmed[0] != "healthy" || warmed[1] != "unhealthy" {
+		t.Error("Unexpected warmed up handlers:", warmed)
+	}
+	if called {
+		t.Error("Handlers not implementing Healther or Warmer should not be called")
+	}
+	if err := e.Dispatch(ctx, testStruct{}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if !called {
+		t.Error("Handler not called")
+	}
+}
+
+func TestSequentialBus(t *testing.T) {
+	var lock sync.Mutex
+	var order []string
+	record := func(s string) {
+		lock.Lock()
+		defer lock.Unlock()
+		order = append(order, s)
+	}
+	slow := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		time.Sleep(10 * time.Millisecond)
+		record("slow")
+		return nil
+	}
+	failing := func(ctx context.Context, s testStruct) error {
+		record("failing")
+		return errors.New("failed")
+	}
+	fast := func(ctx context.Context, i int) error { // nolint: unparam
+		record("fast")
+		return nil
+	}
+
+	b := thevent.NewBus(thevent.Sequential())
+	a, err := b.New("a", testStruct{}, slow)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if _, err := a.New(testStruct{}, "", failing); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	f, err := b.New("b", 0, fast)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+
+	ctx := context.Background()
+	ch, err := a.DispatchAsyncWithResults(ctx, testStruct{})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := f.Dispatch(ctx, 1); err != nil {
+			t.Error("Unexpected error dispatching:", err)
+		}
+	}()
+	res := thevent.HandlersResults{}
+	res.Collect(ch)
+	<-done
+	if res.NumHandlers != 2 || len(res.Errors) != 1 {
+		t.Error("Unexpected results:", res)
+	}
+	if len(order) != 3 || order[0] != "slow" || order[1] != "failing" || order[2] != "fast" {
+		t.Error("Handlers run out of order:", order)
+	}
+	_, err = f.DispatchAsyncWithResults(ctx, "wrong")
+	errorMatchesGlob(t, err, "Dispatch called with incorrect event data type. Expected: int Got: string")
+}
+
+func TestSequentialBusResults(t *testing.T) {
+	shedder := thevent.NewLoadShedder()
+	b := thevent.NewBus(thevent.Sequential(), thevent.WithLoadShedder(shedder))
+	e, err := b.New("a", testStruct{}, thevent.WithPriority(thevent.Optional))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if err := e.AddHandlerWithOptions(func(ctx context.Context, s testStruct) error {
+		return errors.New("failed")
+	}, thevent.WithName("failing")); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	if err := e.AddHandlers(func(ctx context.Context, s testStruct) error { return nil }); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+
+	// A dispatch waiting for its turn isn't shed once it has been admitted
+	release := make(chan struct{})
+	blocking, err := b.New("blocking", 0, func(ctx context.Context, i int) error { // nolint: unparam
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	ctx := context.Background()
+	if err := blocking.DispatchAsync(ctx, 0); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	ch, err := e.DispatchAsyncWithResults(ctx, testStruct{})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	shedder.SetLevel(thevent.ShedOptional)
+	close(release)
+	res := thevent.HandlersResults{}
+	res.Collect(ch)
+	if res.NumHandlers != 2 || len(res.Errors) != 1 {
+		t.Fatal("Unexpected results:", res)
+	}
+	var handlerErr thevent.HandlerError
+	if !errors.As(res.Errors[0], &handlerErr) || handlerErr.Name != "failing" {
+		t.Error("Expected a HandlerError of the failing handler, got:", res.Errors[0])
+	}
+	if stats := shedder.Stats(); stats.Optional != 0 {
+		t.Error("Unexpected stats:", stats)
+	}
+}
@@ -0,0 +1,90 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrInjectedFault is the default error returned by handlers when a fault is injected
+var ErrInjectedFault = errors.New("Injected fault")
+
+// FaultProfile configures the faults randomly injected into an Event's handlers. Rates are probabilities between
+// 0.0 and 1.0. Faults are only injected when Enabled is true, so profiles may be kept in configuration and enabled
+// explicitly, e.g. in a staging environment.
+type FaultProfile struct {
+	Enabled bool
+	// LatencyRate is the probability of delaying a handler by Latency before it's run
+	LatencyRate float64
+	Latency     time.Duration
+	// ErrorRate is the probability of failing a handler with Err instead of running it. ErrInjectedFault is used
+	// if Err is nil.
+	ErrorRate float64
+	Err       error
+	// PanicRate is the probability of panicking instead of running a handler
+	PanicRate float64
+	// Seed seeds the random source used to inject faults. The current time is used if Seed is 0.
+	Seed int64
+}
+
+// faultInjector injects the faults of a FaultProfile
+type faultInjector struct {
+	profile FaultProfile
+	lock    sync.Mutex
+	rand    *rand.Rand
+}
+
+// WithFaultInjection configures the Event to randomly inject latency, errors or panics into its handlers according
+// to the FaultProfile, to test the error handling of event pipelines
+func WithFaultInjection(profile FaultProfile) Option {
+	return func(e *Event) error {
+		for _, rate := range []float64{profile.LatencyRate, profile.ErrorRate, profile.PanicRate} {
+			if rate < 0.0 || rate > 1.0 {
+				return TypeError{errors.New("Fault rates must be between 0.0 and 1.0")}
+			}
+		}
+		if !profile.Enabled {
+			return nil
+		}
+		seed := profile.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		e.faults = &faultInjector{profile: profile, rand: rand.New(rand.NewSource(seed))} // nolint: gosec
+		return nil
+	}
+}
+
+func (f *faultInjector) roll(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.rand.Float64() < rate
+}
+
+// inject injects faults before a handler is run. A non-nil error is returned if the handler should fail instead of
+// being run.
+func (f *faultInjector) inject(ctx context.Context) error {
+	if f.roll(f.profile.LatencyRate) {
+		t := time.NewTimer(f.profile.Latency)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+		}
+	}
+	if f.roll(f.profile.PanicRate) {
+		panic(ErrInjectedFault)
+	}
+	if f.roll(f.profile.ErrorRate) {
+		if f.profile.Err != nil {
+			return f.profile.Err
+		}
+		return ErrInjectedFault
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestWithFaultInjection(t *testing.T) {
+	customErr := errors.New("custom fault")
+	testCases := []struct {
+		name          string
+		profile       thevent.FaultProfile
+		errorGlob     string
+		expectCalled  bool
+		expectedErr   error
+		expectPanic   bool
+		expectLatency time.Duration
+	}{
+		{name: "invalid rate", profile: thevent.FaultProfile{Enabled: true, ErrorRate: 1.5},
+			errorGlob: "Fault rates must be between 0.0 and 1.0"},
+		{name: "disabled", profile: thevent.FaultProfile{ErrorRate: 1.0, PanicRate: 1.0}, expectCalled: true},
+		{name: "errors", profile: thevent.FaultProfile{Enabled: true, ErrorRate: 1.0},
+			expectedErr: thevent.ErrInjectedFault},
+		{name: "custom error", profile: thevent.FaultProfile{Enabled: true, ErrorRate: 1.0, Err: customErr},
+			expectedErr: customErr},
+		{name: "panics", profile: thevent.FaultProfile{Enabled: true, PanicRate: 1.0}, expectPanic: true},
+		{name: "latency", profile: thevent.FaultProfile{Enabled: true, LatencyRate: 1.0,
+			Latency: 5 * time.Millisecond}, expectCalled: true, expectLatency: 5 * time.Millisecond},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+			handler := func(ctx context.Context, i int) error { // nolint: unparam
+				called = true
+				return nil
+			}
+			e, err := thevent.New(0, handler, thevent.WithFaultInjection(tc.profile))
+			errorMatchesGlob(t, err, tc.errorGlob)
+			if err != nil {
+				return
+			}
+
+			defer func() {
+				r := recover()
+				if tc.expectPanic != (r != nil) {
+					t.Error("Unexpected panic:", r)
+				}
+			}()
+			start := time.Now()
+			res, err := e.DispatchWithResults(context.Background(), 1)
+			if err != nil {
+				t.Fatal("Unexpected error dispatching:", err)
+			}
+			if d := time.Since(start); d < tc.expectLatency {
+				t.Error("Expected latency of at least", tc.expectLatency, "got:", d)
+			}
+			if called != tc.expectCalled {
+				t.Error("Handler called:", called, "expected:", tc.expectCalled)
+			}
+			if tc.expectedErr == nil && res.Erred() {
+				t.Error("Unexpected handler errors:", res.Errors)
+			}
+			if tc.expectedErr != nil && (len(res.Errors) != 1 || res.Errors[0] != tc.expectedErr) {
+				t.Error("Expected injected error:", tc.expectedErr, "got:", res.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package thevent
+
+import (
+	"errors"
+)
+
+// CheckHandler checks whether the Handler may be added to the Event without adding it. The same error that
+// Event.AddHandlers() would return is returned.
+func CheckHandler(e *Event, h Handler) error {
+	cH, err := e.newHandler(h)
+	if err != nil {
+		return err
+	}
+	if err := e.identify(cH); err != nil {
+		return err
+	}
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	if _, ok := e.handlerPtrs[cH.key()]; ok {
+		return TypeError{errors.New("Unable to add duplicate handler")}
+	}
+	return nil
+}
+
+// CheckData checks whether the data may be dispatched by the Event without dispatching it. The same error that
+// dispatching the data would return is returned.
+func CheckData(e *Event, data Data) error {
+	return e.checkDataType(data)
+}
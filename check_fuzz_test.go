@@ -0,0 +1,27 @@
+package thevent_test
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func FuzzCheckData(f *testing.F) {
+	e, err := thevent.New("")
+	if err != nil {
+		f.Fatal("Unable to create event:", err)
+	}
+	f.Add("data", 0, true)
+	f.Add("", 1, false)
+	f.Fuzz(func(t *testing.T, s string, i int, useString bool) {
+		var data thevent.Data = i
+		if useString {
+			data = s
+		}
+		if err := thevent.CheckData(e, data); (err == nil) != useString {
+			t.Error("Unexpected check result for data:", data, "error:", err)
+		}
+	})
+}
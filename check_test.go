@@ -0,0 +1,64 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestCheckHandler(t *testing.T) {
+	added := func(ctx context.Context, s testStruct) error { return nil }
+	e, err := thevent.New(testStruct{}, added)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	testCases := []struct {
+		name      string
+		handler   thevent.Handler
+		errorGlob string
+	}{
+		{name: "valid", handler: func(ctx context.Context, s testStruct) error { return nil }},
+		{name: "nil", handler: nil, errorGlob: "Handler must not be nil"},
+		{name: "wrong type", handler: func(ctx context.Context, i int) error { return nil },
+			errorGlob: "Handler uses incorrect data type.*"},
+		{name: "duplicate", handler: added, errorGlob: "Unable to add duplicate handler"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errorMatchesGlob(t, thevent.CheckHandler(e, tc.handler), tc.errorGlob)
+		})
+	}
+	res, err := e.DispatchWithResults(context.Background(), testStruct{})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if res.NumHandlers != 1 {
+		t.Error("Checking handlers shouldn't add them. Handlers:", res.NumHandlers)
+	}
+}
+
+func TestCheckData(t *testing.T) {
+	e, err := thevent.New(testStruct{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	testCases := []struct {
+		name      string
+		data      thevent.Data
+		errorGlob string
+	}{
+		{name: "valid", data: testStruct{}},
+		{name: "nil", data: nil,
+			errorGlob: "Dispatch called with incorrect event data type. Expected: thevent_test.testStruct Got: <nil>"},
+		{name: "wrong type", data: &testStruct{},
+			errorGlob: "Dispatch called with incorrect event data type. Expected: * Got: *thevent_test.testStruct"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errorMatchesGlob(t, thevent.CheckData(e, tc.data), tc.errorGlob)
+		})
+	}
+}
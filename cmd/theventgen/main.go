@@ -0,0 +1,184 @@
+// Command theventgen generates type-safe wrappers around thevent Events for the given event data types. Unlike
+// instantiations of thevent.TypedEvent, the generated wrappers are named types declared in the package of the event
+// data types, so the package may document them and give them domain-specific methods, e.g. to dispatch an
+// OrderCreated from an Order. It's intended to be run by go generate. e.g.
+//
+//	//go:generate theventgen -type=OrderCreated,OrderShipped
+//
+// generates an OrderCreatedEvent and an OrderShippedEvent type in the file thevent_events.go, with Dispatch and
+// AddHandlers methods taking the concrete data types. The handlers are added using thevent.AsHandler(), so like the
+// handlers of a thevent.TypedEvent, they're called without reflection.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("theventgen: ")
+	typeNames := flag.String("type", "", "comma-separated list of event data type names; required")
+	output := flag.String("output", "thevent_events.go", "output file name")
+	dir := flag.String("dir", ".", "directory of the package declaring the types")
+	flag.Parse()
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	pkg, err := parsePackage(*dir, strings.Split(*typeNames, ","))
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(strings.Join(os.Args[1:], " "), pkg, strings.Split(*typeNames, ","))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(*dir, *output), src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parsePackage gets the name of the package in the directory and checks that it declares the types
+func parsePackage(dir string, typeNames []string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	fset := token.NewFileSet()
+	// declared holds the types declared by every package in the directory
+	declared := map[string]map[string]bool{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return "", err
+		}
+		types := declared[f.Name.Name]
+		if types == nil {
+			types = map[string]bool{}
+			declared[f.Name.Name] = types
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				types[spec.(*ast.TypeSpec).Name.Name] = true
+			}
+		}
+	}
+	if len(declared) != 1 {
+		return "", fmt.Errorf("Expected 1 package in %s, found %d", dir, len(declared))
+	}
+	for name, types := range declared {
+		for _, t := range typeNames {
+			if !types[strings.TrimSpace(t)] {
+				return "", fmt.Errorf("Type %s not declared in package %s", t, name)
+			}
+		}
+		return name, nil
+	}
+	return "", nil
+}
+
+// generate generates the source of the wrappers for the types of the package. args are the arguments theventgen was
+// run with.
+func generate(args, pkg string, typeNames []string) ([]byte, error) {
+	types := make([]string, 0, len(typeNames))
+	for _, t := range typeNames {
+		t = strings.TrimSpace(t)
+		if !token.IsIdentifier(t) {
+			return nil, fmt.Errorf("Invalid type name: %q", t)
+		}
+		types = append(types, t)
+	}
+	if len(types) == 0 {
+		return nil, errors.New("No types given")
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct {
+		Args    string
+		Package string
+		Types   []string
+	}{Args: args, Package: pkg, Types: types}); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+var tmpl = template.Must(template.New("").Parse(`// Code generated by theventgen {{.Args}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+
+	"github.com/dhui/thevent"
+)
+{{range .Types}}
+// {{.}}Event is a type-safe wrapper around a thevent.Event whose data is of type {{.}}
+type {{.}}Event struct {
+	*thevent.Event
+}
+
+// New{{.}}Event creates a new {{.}}Event
+func New{{.}}Event(handlers ...func(context.Context, {{.}}) error) (*{{.}}Event, error) {
+	var data {{.}}
+	hs := make([]thevent.Handler, 0, len(handlers))
+	for _, h := range handlers {
+		hs = append(hs, thevent.AsHandler[{{.}}](h))
+	}
+	e, err := thevent.New(data, hs...)
+	if err != nil {
+		return nil, err
+	}
+	return &{{.}}Event{Event: e}, nil
+}
+
+// AddHandlers adds the handlers to the {{.}}Event
+func (e *{{.}}Event) AddHandlers(handlers ...func(context.Context, {{.}}) error) error {
+	hs := make([]thevent.Handler, 0, len(handlers))
+	for _, h := range handlers {
+		hs = append(hs, thevent.AsHandler[{{.}}](h))
+	}
+	return e.Event.AddHandlers(hs...)
+}
+
+// Dispatch is the same as thevent.Event.Dispatch
+func (e *{{.}}Event) Dispatch(ctx context.Context, data {{.}}, opts ...thevent.DispatchOption) error {
+	return e.Event.Dispatch(ctx, data, opts...)
+}
+
+// DispatchWithResults is the same as thevent.Event.DispatchWithResults
+func (e *{{.}}Event) DispatchWithResults(ctx context.Context, data {{.}},
+	opts ...thevent.DispatchOption) (*thevent.HandlersResults, error) {
+	return e.Event.DispatchWithResults(ctx, data, opts...)
+}
+
+// DispatchAsync is the same as thevent.Event.DispatchAsync
+func (e *{{.}}Event) DispatchAsync(ctx context.Context, data {{.}}, opts ...thevent.DispatchOption) error {
+	return e.Event.DispatchAsync(ctx, data, opts...)
+}
+
+// DispatchAsyncWithResults is the same as thevent.Event.DispatchAsyncWithResults
+func (e *{{.}}Event) DispatchAsyncWithResults(ctx context.Context, data {{.}},
+	opts ...thevent.DispatchOption) (<-chan error, error) {
+	return e.Event.DispatchAsyncWithResults(ctx, data, opts...)
+}
+{{end}}`))
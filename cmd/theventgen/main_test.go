@@ -0,0 +1,130 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePackage(t *testing.T) {
+	dir, err := os.MkdirTemp("", "theventgen")
+	if err != nil {
+		t.Fatal("Unable to create temp dir:", err)
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+	src := "package orders\n\ntype OrderCreated struct{ ID int }\n"
+	if err := os.WriteFile(filepath.Join(dir, "orders.go"), []byte(src), 0644); err != nil {
+		t.Fatal("Unable to write package:", err)
+	}
+	testSrc := "package orders_test\n\ntype Fake struct{}\n"
+	if err := os.WriteFile(filepath.Join(dir, "orders_test.go"), []byte(testSrc), 0644); err != nil {
+		t.Fatal("Unable to write package:", err)
+	}
+
+	if pkg, err := parsePackage(dir, []string{"OrderCreated"}); err != nil || pkg != "orders" {
+		t.Error("Unexpected package:", pkg, err)
+	}
+	if _, err := parsePackage(dir, []string{"OrderCreated", "Fake"}); err == nil ||
+		err.Error() != "Type Fake not declared in package orders" {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := generate("-type=OrderCreated,OrderShipped", "orders", []string{"OrderCreated", " OrderShipped"})
+	if err != nil {
+		t.Fatal("Unable to generate:", err)
+	}
+	if !strings.HasPrefix(string(src),
+		"// Code generated by theventgen -type=OrderCreated,OrderShipped; DO NOT EDIT.\n\npackage orders\n") {
+		t.Error("Unexpected header:", string(src))
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "thevent_events.go", src, 0)
+	if err != nil {
+		t.Fatal("Unable to parse generated source:", err)
+	}
+	for _, name := range []string{"OrderCreatedEvent", "NewOrderCreatedEvent", "OrderShippedEvent",
+		"NewOrderShippedEvent"} {
+		if f.Scope.Lookup(name) == nil {
+			t.Error("Generated source doesn't declare:", name)
+		}
+	}
+	expected := "func (e *OrderShippedEvent) Dispatch(ctx context.Context, data OrderShipped, " +
+		"opts ...thevent.DispatchOption) error {"
+	if !strings.Contains(string(src), expected) {
+		t.Error("Generated source doesn't contain:", expected)
+	}
+
+	for _, types := range [][]string{{}, {"Order-Created"}} {
+		if _, err := generate("", "orders", types); err == nil {
+			t.Error("Expected error generating types:", types)
+		}
+	}
+}
+
+// TestGenerateCompiles builds and runs the generated wrappers in a module using the thevent module being tested
+func TestGenerateCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping building the generated source in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found:", err)
+	}
+	theventDir, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal("Unable to get thevent module directory:", err)
+	}
+	dir, err := os.MkdirTemp("", "theventgen")
+	if err != nil {
+		t.Fatal("Unable to create temp dir:", err)
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	src, err := generate("-type=OrderCreated", "orders", []string{"OrderCreated"})
+	if err != nil {
+		t.Fatal("Unable to generate:", err)
+	}
+	files := map[string]string{
+		"go.mod": "module example.com/orders\n\ngo 1.23\n\nrequire github.com/dhui/thevent v0.0.0\n\n" +
+			"replace github.com/dhui/thevent => " + theventDir + "\n",
+		"orders.go":         "package orders\n\ntype OrderCreated struct{ ID int }\n",
+		"thevent_events.go": string(src),
+		"orders_test.go": `package orders
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOrderCreatedEvent(t *testing.T) {
+	var got OrderCreated
+	e, err := NewOrderCreatedEvent(func(ctx context.Context, o OrderCreated) error {
+		got = o
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Dispatch(context.Background(), OrderCreated{ID: 1}); err != nil || got.ID != 1 {
+		t.Fatal(got, err)
+	}
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal("Unable to write module:", err)
+		}
+	}
+	cmd := exec.Command(goBin, "test", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Error("Generated source doesn't build:", err, string(out))
+	}
+}
@@ -0,0 +1,94 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// coalescer shares the dispatches of an Event with equal keys that are in flight at the same time
+type coalescer struct {
+	key     func(data interface{}) interface{}
+	lock    sync.Mutex
+	flights map[interface{}]*flight
+}
+
+// flight is an in-flight coalesced dispatch
+type flight struct {
+	done    chan struct{}
+	results *HandlersResults
+	err     error
+}
+
+// WithCoalescing coalesces concurrent synchronous dispatches of the Event with equal data into a single dispatch
+// whose results and error are shared, e.g. for expensive idempotent handlers like cache rebuilds. A dispatch made
+// while a dispatch with an equal key is in flight doesn't run any handlers, and instead waits for the in-flight
+// dispatch to finish and returns its results, unless its own context.Context is done first. Dispatches made afterwards
+// run the handlers again.
+//
+// key derives the key which identifies equal data, and must return a comparable value. If key is nil, the data
+// itself is used as the key, so the Event's data type must be comparable.
+//
+// Asynchronous dispatches, streamed dispatches, dispatches using WithChildData() and dispatches of the Event as a
+// sub-Event aren't coalesced.
+func WithCoalescing(key func(data interface{}) interface{}) Option {
+	return func(e *Event) error {
+		if key == nil {
+			if !e.dataType.Comparable() {
+				return TypeError{errors.New("Coalescing requires a key function for incomparable data types")}
+			}
+			key = func(data interface{}) interface{} { return data }
+		}
+		e.coalescer = &coalescer{key: key, flights: map[interface{}]*flight{}}
+		return nil
+	}
+}
+
+// dispatchRoot dispatches the Event as a root Event, coalescing the dispatch with equal in-flight dispatches if
+// configured
+func (e *Event) dispatchRoot(ctx context.Context, d *dispatchState, data interface{}) (*HandlersResults,
+	<-chan error, error) {
+	if e.coalescer == nil || d.async || d.onResult != nil || d.callback != nil || d.childData != nil {
+		return e.dispatchSequenced(ctx, d, data)
+	}
+	if err := e.checkDataType(data); err != nil {
+		return nil, nil, err
+	}
+	c := e.coalescer
+	key := c.key(data)
+	c.lock.Lock()
+	if f, ok := c.flights[key]; ok {
+		c.lock.Unlock()
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+		return f.share(d.trackResults), nil, f.err
+	}
+	f := &flight{done: make(chan struct{})}
+	c.flights[key] = f
+	c.lock.Unlock()
+
+	// The results are always tracked since they may be shared with dispatches collecting results
+	trackResults := d.trackResults
+	d.trackResults = true
+	defer func() {
+		c.lock.Lock()
+		delete(c.flights, key)
+		c.lock.Unlock()
+		close(f.done)
+	}()
+	f.results, _, f.err = e.dispatchSequenced(ctx, d, data)
+	return f.share(trackResults), nil, f.err
+}
+
+// share copies the results of the flight for a dispatch, so callers may modify their results
+func (f *flight) share(trackResults bool) *HandlersResults {
+	if !trackResults || f.results == nil {
+		return nil
+	}
+	res := *f.results
+	res.Errors = append([]error(nil), res.Errors...)
+	return &res
+}
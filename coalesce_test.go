@@ -0,0 +1,126 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestWithCoalescing(t *testing.T) {
+	if _, err := thevent.New([]int{}, thevent.WithCoalescing(nil)); err == nil {
+		t.Error("Created event coalescing incomparable data without a key function")
+	}
+
+	var calls int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	rebuild := func(ctx context.Context, u fixtureUser) error {
+		atomic.AddInt32(&calls, 1)
+		started <- struct{}{}
+		<-release
+		return errors.New("rebuild failed")
+	}
+	e := thevent.Must(thevent.New(fixtureUser{}, rebuild, thevent.WithCoalescing(func(data interface{}) interface{} {
+		return data.(fixtureUser).ID
+	})))
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	results := make([]*thevent.HandlersResults, 3)
+	errs := make([]error, 3)
+	dispatch := func(i int, u fixtureUser) {
+		defer wg.Done()
+		results[i], errs[i] = e.DispatchWithResults(ctx, u)
+	}
+	wg.Add(1)
+	go dispatch(0, fixtureUser{ID: 1, Name: "a"})
+	<-started
+	wg.Add(2)
+	go dispatch(1, fixtureUser{ID: 1, Name: "b"})
+	go func() {
+		defer wg.Done()
+		errs[2] = e.Dispatch(ctx, fixtureUser{ID: 1})
+	}()
+	// Wait for the dispatches to join the in-flight dispatch
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatal("Expected coalesced dispatches to run the handler once, ran:", calls)
+	}
+	for i, err := range errs {
+		if err != nil {
+			t.Error("Unexpected error dispatching:", i, err)
+		}
+	}
+	for _, res := range results[:2] {
+		if res == nil || res.NumHandlers != 1 || len(res.Errors) != 1 || res.Errors[0].Error() != "rebuild failed" {
+			t.Error("Unexpected results:", res)
+		}
+	}
+	if results[0] == results[1] {
+		t.Error("Coalesced dispatches should get their own copy of the results")
+	}
+
+	// Dispatches after the in-flight dispatch finished and dispatches with different keys aren't coalesced
+	for _, u := range []fixtureUser{{ID: 1}, {ID: 2}} {
+		if _, err := e.DispatchWithResults(ctx, u); err != nil {
+			t.Error("Unexpected error dispatching:", err)
+		}
+		<-started
+	}
+	if calls != 3 {
+		t.Error("Expected handler to run for dispatches which aren't in flight, ran:", calls)
+	}
+	_, err := e.DispatchWithResults(ctx, "wrong")
+	errorMatchesGlob(t, err, "Dispatch called with incorrect event data type.*")
+}
+
+func TestWithCoalescingWaiters(t *testing.T) {
+	var calls int32
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	rebuild := func(ctx context.Context, u fixtureUser) error { // nolint: unparam
+		atomic.AddInt32(&calls, 1)
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+	e := thevent.Must(thevent.New(fixtureUser{}, rebuild, thevent.WithCoalescing(nil)))
+	child := thevent.Must(e.New(fixtureUser{}, ""))
+
+	done := make(chan error, 1)
+	go func() { done <- e.Dispatch(context.Background(), fixtureUser{ID: 1}) }()
+	<-started
+
+	// A waiting dispatch returns once its context.Context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := e.Dispatch(ctx, fixtureUser{ID: 1}); err != context.DeadlineExceeded {
+		t.Error("Expected the waiting dispatch to time out, got:", err)
+	}
+
+	// Dispatches with their own child data aren't coalesced
+	go func() {
+		done <- e.Dispatch(context.Background(), fixtureUser{ID: 1},
+			thevent.WithChildData(child, fixtureUser{ID: 2}))
+	}()
+	<-started
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Error("Unexpected error dispatching:", err)
+		}
+	}
+	if calls != 2 {
+		t.Error("Expected the handler to run twice, ran:", calls)
+	}
+}
@@ -0,0 +1,188 @@
+package thevent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// EventConfig describes the structure and configuration of an Event and its sub-Events, excluding the handlers'
+// code. EventConfigs may be exported and imported to check that processes are wired identically. Options configured
+// with functions or values which can't be compared across processes, e.g. middleware or worker pools, are only
+// described by whether they're configured or by their number.
+type EventConfig struct {
+	// Name is the name the Event is registered with on a Bus, if any
+	Name     string `json:"name,omitempty"`
+	DataType string `json:"dataType"`
+	// Field is the field of the sub-Event's data which holds the parent Event's data, if any
+	Field      string `json:"field,omitempty"`
+	ViaPointer bool   `json:"viaPointer,omitempty"`
+	// Projected is true if the sub-Event's data is created from the parent Event's data by a function
+	Projected bool `json:"projected,omitempty"`
+	Handlers  int  `json:"handlers"`
+
+	StopOnHandled        bool               `json:"stopOnHandled,omitempty"`
+	FailFast             FailFastBehavior   `json:"failFast,omitempty"`
+	Propagation          Propagation        `json:"propagation,omitempty"`
+	BestEffort           bool               `json:"bestEffort,omitempty"`
+	BestEffortAsync      bool               `json:"bestEffortAsync,omitempty"`
+	Budget               time.Duration      `json:"budget,omitempty"`
+	SlowHandlerThreshold time.Duration      `json:"slowHandlerThreshold,omitempty"`
+	Balanced             bool               `json:"balanced,omitempty"`
+	FaultInjection       bool               `json:"faultInjection,omitempty"`
+	Metadata             map[string]string  `json:"metadata,omitempty"`
+	Isolated             bool               `json:"isolated,omitempty"`
+	RecoverPanics        bool               `json:"recoverPanics,omitempty"`
+	Priority             Priority           `json:"priority,omitempty"`
+	SLO                  *SLO               `json:"slo,omitempty"`
+	Coalesced            bool               `json:"coalesced,omitempty"`
+	MaxDataSize          int                `json:"maxDataSize,omitempty"`
+	DataSizeWarning      int                `json:"dataSizeWarning,omitempty"`
+	Middlewares          int                `json:"middlewares,omitempty"`
+	HandlerIdentity      bool               `json:"handlerIdentity,omitempty"`
+	AllowDuplicates      bool               `json:"allowDuplicates,omitempty"`
+	StaleContextPolicy   StaleContextPolicy `json:"staleContextPolicy,omitempty"`
+	WorkerPool           bool               `json:"workerPool,omitempty"`
+	Owned                bool               `json:"owned,omitempty"`
+	// Providers are the types of the dependencies provided to the handlers, sorted by name
+	Providers []string `json:"providers,omitempty"`
+
+	Children []EventConfig `json:"children,omitempty"`
+}
+
+// Config describes the structure and configuration of the Event and its sub-Events
+func (e *Event) Config() EventConfig {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	cfg := EventConfig{Name: e.busName, DataType: e.dataType.String(), Handlers: len(e.handlers),
+		StopOnHandled: e.stopOnHandled, FailFast: e.failFast, Propagation: e.propagation, BestEffort: e.bestEffort,
+		BestEffortAsync: e.bestEffortAsync, Budget: e.budget, SlowHandlerThreshold: e.slowHandlerThreshold,
+		Balanced: e.balancer != nil, FaultInjection: e.faults != nil, Isolated: e.isolated,
+		RecoverPanics: e.recoverPanics, Priority: e.priority, Coalesced: e.coalescer != nil,
+		Middlewares: len(e.middlewares), HandlerIdentity: e.identity != nil, AllowDuplicates: e.allowDuplicates,
+		StaleContextPolicy: e.staleContextPolicy, WorkerPool: e.pool != nil, Owned: e.owner != nil}
+	if e.slo != nil {
+		slo := e.slo.slo
+		cfg.SLO = &slo
+	}
+	for _, g := range e.sizeGuards {
+		if g.warn {
+			cfg.DataSizeWarning = g.limit
+		} else {
+			cfg.MaxDataSize = g.limit
+		}
+	}
+	if len(e.metadata) > 0 {
+		cfg.Metadata = make(map[string]string, len(e.metadata))
+		for k, v := range e.metadata {
+			cfg.Metadata[k] = v
+		}
+	}
+	for t := range e.providers {
+		cfg.Providers = append(cfg.Providers, t.String())
+	}
+	sort.Strings(cfg.Providers)
+	for _, c := range e.children {
+		childCfg := c.event.Config()
+		if c.field != nil {
+			childCfg.Field = c.field.Name
+			childCfg.ViaPointer = c.field.Type.Kind() == reflect.Ptr
+		}
+		childCfg.Projected = c.project != nil
+		cfg.Children = append(cfg.Children, childCfg)
+	}
+	return cfg
+}
+
+// Config describes the structure and configuration of the registered Events in registration order
+func (b *Bus) Config() []EventConfig {
+	_, events := b.registered()
+	cfgs := make([]EventConfig, 0, len(events))
+	for _, e := range events {
+		cfgs = append(cfgs, e.Config())
+	}
+	return cfgs
+}
+
+// Export writes the configuration of the registered Events as JSON
+func (b *Bus) Export(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b.Config())
+}
+
+// Import reads the configuration of Events written by Bus.Export()
+func Import(r io.Reader) ([]EventConfig, error) {
+	var cfgs []EventConfig
+	if err := json.NewDecoder(r).Decode(&cfgs); err != nil {
+		return nil, fmt.Errorf("Unable to import config: %v", err)
+	}
+	return cfgs, nil
+}
+
+// DiffConfigs compares the expected and actual configurations and describes every difference. No differences are
+// returned if the configurations match.
+func DiffConfigs(expected, actual []EventConfig) []string {
+	e, a := map[string]string{}, map[string]string{}
+	flattenConfig(e, "events", expected)
+	flattenConfig(a, "events", actual)
+	paths := make([]string, 0, len(e))
+	for p := range e {
+		paths = append(paths, p)
+	}
+	for p := range a {
+		if _, ok := e[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	var diffs []string
+	for _, p := range paths {
+		ev, eOk := e[p]
+		av, aOk := a[p]
+		switch {
+		case !aOk:
+			diffs = append(diffs, fmt.Sprintf("%s: missing %s", p, ev))
+		case !eOk:
+			diffs = append(diffs, fmt.Sprintf("%s: unexpected %s", p, av))
+		case ev != av:
+			diffs = append(diffs, fmt.Sprintf("%s: expected %s, got %s", p, ev, av))
+		}
+	}
+	return diffs
+}
+
+// flattenConfig flattens the JSON representation of the value into the paths of its leaf values
+func flattenConfig(flattened map[string]string, path string, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		flattened[path] = err.Error()
+		return
+	}
+	var generic interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		flattened[path] = err.Error()
+		return
+	}
+	flattenJSON(flattened, path, generic)
+}
+
+func flattenJSON(flattened map[string]string, path string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			flattenJSON(flattened, path+"."+k, child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenJSON(flattened, fmt.Sprintf("%s[%d]", path, i), child)
+		}
+	default:
+		b, _ := json.Marshal(v)
+		flattened[path] = string(b)
+	}
+}
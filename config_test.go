@@ -0,0 +1,87 @@
+package thevent_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestExportImport(t *testing.T) {
+	type orderData struct{ Order *testStruct }
+	handler := func(ctx context.Context, s testStruct) error { return nil }
+	childHandler := func(ctx context.Context, o orderData) error { return nil }
+
+	b := thevent.NewBus()
+	e, err := b.New("orders", testStruct{}, handler, thevent.StopOnHandled(), thevent.WithMetadata("team", "a"))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	child, err := e.New(orderData{}, "Order", childHandler, thevent.WithBudget(time.Second))
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+
+	var buf bytes.Buffer
+	if err := b.Export(&buf); err != nil {
+		t.Fatal("Unable to export:", err)
+	}
+	cfgs, err := thevent.Import(&buf)
+	if err != nil {
+		t.Fatal("Unable to import:", err)
+	}
+	if diffs := thevent.DiffConfigs(cfgs, b.Config()); len(diffs) > 0 {
+		t.Error("Imported config differs:", diffs)
+	}
+	if len(cfgs) != 1 || cfgs[0].Name != "orders" || !cfgs[0].StopOnHandled || len(cfgs[0].Children) != 1 ||
+		cfgs[0].Children[0].Field != "Order" || !cfgs[0].Children[0].ViaPointer ||
+		cfgs[0].Children[0].Budget != time.Second {
+		t.Error("Unexpected imported config:", cfgs)
+	}
+
+	if err := child.AddHandlers(func(ctx context.Context, o orderData) error { return nil }); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	if _, err := b.New("payments", 0); err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	diffs := thevent.DiffConfigs(cfgs, b.Config())
+	expected := []string{
+		"events[0].children[0].handlers: expected 1, got 2",
+		`events[1].dataType: unexpected "int"`,
+		"events[1].handlers: unexpected 0",
+		`events[1].name: unexpected "payments"`,
+	}
+	if strings.Join(diffs, "\n") != strings.Join(expected, "\n") {
+		t.Error("Unexpected diffs:", diffs, "expected:", expected)
+	}
+
+	_, err = thevent.Import(strings.NewReader("{"))
+	errorMatchesGlob(t, err, "Unable to import config: *")
+}
+
+func TestDiffConfigsOptions(t *testing.T) {
+	passthrough := func(ctx context.Context, call thevent.HandlerCall, next thevent.Next) (thevent.Outcome, error) {
+		return next(ctx)
+	}
+	plain := thevent.Must(thevent.New(testStruct{})).Config()
+	configured := thevent.Must(thevent.New(testStruct{}, thevent.Isolated(), thevent.WithPriority(thevent.Critical),
+		thevent.WithMiddleware(passthrough), thevent.WithStaleContextPolicy(thevent.StaleContextSkip),
+		thevent.OwnedBy(thevent.NewCapability()))).Config()
+	diffs := thevent.DiffConfigs([]thevent.EventConfig{plain}, []thevent.EventConfig{configured})
+	expected := []string{
+		"events[0].isolated: unexpected true",
+		"events[0].middlewares: unexpected 1",
+		"events[0].owned: unexpected true",
+		"events[0].priority: unexpected 2",
+		"events[0].staleContextPolicy: unexpected 2",
+	}
+	if strings.Join(diffs, "\n") != strings.Join(expected, "\n") {
+		t.Error("Unexpected diffs:", diffs, "expected:", expected)
+	}
+}
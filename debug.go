@@ -0,0 +1,35 @@
+//go:build thevent_debug
+// +build thevent_debug
+
+package thevent
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// callerStack captures the stack of the caller in debug builds
+func callerStack() []byte {
+	return debug.Stack()
+}
+
+// registrationSite gets the file and line outside of thevent where a handler is being registered in debug builds
+func registrationSite() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, "github.com/dhui/thevent.") && !strings.HasPrefix(f.Function, "reflect.") &&
+			!strings.HasPrefix(f.Function, "runtime.") {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// usageAccounting enables accounting the resource consumption of handlers in debug builds. See HandlerUsage
+const usageAccounting = true
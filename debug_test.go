@@ -0,0 +1,57 @@
+//go:build thevent_debug
+// +build thevent_debug
+
+package thevent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestHandlerRegisteredAt(t *testing.T) {
+	panicking := func(ctx context.Context, s testStruct) error {
+		panic("boom")
+	}
+	e := thevent.Must(thevent.New(testStruct{}, panicking, thevent.Isolated()))
+	res, err := e.DispatchWithResults(context.Background(), testStruct{})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(res.Errors) != 1 {
+		t.Fatal("Unexpected results:", res)
+	}
+	pe, ok := res.Errors[0].(thevent.PanicError)
+	if !ok || !strings.HasSuffix(pe.RegisteredAt, "/debug_test.go:20") {
+		t.Fatal("Expected PanicError with the handler's registration site, got:", res.Errors[0])
+	}
+	if expected := "Handler registered at " + pe.RegisteredAt + " panicked: boom"; pe.Error() != expected {
+		t.Error("Got error:", pe.Error(), "instead of:", expected)
+	}
+}
+
+func TestHandlerUsage(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	var sink []byte
+	allocating := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		sink = make([]byte, 1<<20)
+		go func() { <-release }()
+		return nil
+	}
+	e := thevent.Must(thevent.New(testStruct{}, allocating))
+	for i := 0; i < 2; i++ {
+		if err := e.Dispatch(context.Background(), testStruct{}); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	}
+	_ = sink
+	usage := e.Handlers()[0].Usage
+	if usage.Calls != 2 || usage.Allocs < 2 || usage.AllocBytes < 2<<20 || usage.Goroutines != 2 {
+		t.Error("Unexpected handler usage:", usage)
+	}
+}
@@ -0,0 +1,99 @@
+package thevent
+
+import (
+	"errors"
+)
+
+// ErrDestroyed is returned when using an Event which has been destroyed
+var ErrDestroyed = errors.New("Event destroyed")
+
+// DetachChildrenOnDestroy configures the Event to detach its sub-Events when it's destroyed, instead of destroying
+// them. Detached sub-Events are no longer dispatched with the Event and may still be dispatched directly.
+func DetachChildrenOnDestroy() Option {
+	return func(e *Event) error {
+		e.detachChildren = true
+		return nil
+	}
+}
+
+// Destroy destroys the Event so dynamic hierarchies don't accumulate unused Events. The Event is unregistered from
+// its Bus and detached from its parent Event, and its handlers are released. The sub-Events of the Event are also
+// destroyed, unless the Event was configured with DetachChildrenOnDestroy().
+//
+// Dispatching a destroyed Event, adding handlers to it or creating sub-Events of it returns ErrDestroyed. In-flight
+// dispatches of the Event aren't affected. Destroying an Event more than once is a no-op. Destroying an owned Event
+// is ignored, unless it's destroyed by its owner. See OwnedBy()
+func (e *Event) Destroy() {
+	e.destroyOwned(nil)
+}
+
+// destroyOwned destroys the Event, if the Event may be mutated using the Capability
+func (e *Event) destroyOwned(owner *Capability) {
+	// Detach from the parent first so later dispatches of the parent don't reach the destroyed sub-Event
+	e.lock.RLock()
+	parent, ownerErr := e.parent, e.checkOwner(owner)
+	e.lock.RUnlock()
+	if ownerErr != nil {
+		return
+	}
+	if parent != nil {
+		parent.removeChild(e)
+	}
+	children, ok := e.destroy()
+	if !ok {
+		return
+	}
+	if e.bus != nil {
+		e.bus.unregister(e.busName, e)
+	}
+	for _, c := range children {
+		if e.detachChildren {
+			c.event.detach()
+			continue
+		}
+		c.event.destroyTree()
+	}
+}
+
+// destroy marks the Event as destroyed and releases its handlers and sub-Events, which are returned. false is
+// returned if the Event has already been destroyed.
+func (e *Event) destroy() ([]child, bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.destroyed {
+		return nil, false
+	}
+	e.destroyed = true
+	children := e.children
+	e.parent, e.children = nil, nil
+	e.handlers, e.handlerPtrs = nil, map[interface{}]struct{}{}
+	e.providers, e.observers = nil, nil
+	return children, true
+}
+
+// destroyTree destroys the sub-Event and its descendants
+func (e *Event) destroyTree() {
+	children, _ := e.destroy()
+	for _, c := range children {
+		c.event.destroyTree()
+	}
+}
+
+// detach makes the sub-Event a root Event
+func (e *Event) detach() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.parent = nil
+}
+
+// removeChild removes the sub-Event from the Event
+func (e *Event) removeChild(subEvent *Event) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for i, c := range e.children {
+		if c.event == subEvent {
+			e.children = append(e.children[:i:i], e.children[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestDestroy(t *testing.T) {
+	var called []string
+	handler := func(name string) func(context.Context, testStruct) error {
+		return func(ctx context.Context, s testStruct) error {
+			called = append(called, name)
+			return nil
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		opts           []thevent.Handler
+		expectedCalled []string
+	}{
+		{name: "destroy children"},
+		{name: "detach children", opts: []thevent.Handler{thevent.DetachChildrenOnDestroy()},
+			expectedCalled: []string{"grandchild"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := thevent.NewBus()
+			root, err := b.New("root", testStruct{}, handler("root"))
+			if err != nil {
+				t.Fatal("Unable to create event:", err)
+			}
+			sibling, err := root.New(testStruct{}, "", handler("sibling"))
+			if err != nil {
+				t.Fatal("Unable to create sub-Event:", err)
+			}
+			child, err := root.New(testStruct{}, "", append(tc.opts, handler("child"))...)
+			if err != nil {
+				t.Fatal("Unable to create sub-Event:", err)
+			}
+			grandChild, err := child.New(testStruct{}, "", handler("grandchild"))
+			if err != nil {
+				t.Fatal("Unable to create sub-Event:", err)
+			}
+
+			ctx := context.Background()
+			child.Destroy()
+			child.Destroy()
+			called = nil
+			if err := root.Dispatch(ctx, testStruct{}); err != nil {
+				t.Fatal("Unexpected error dispatching:", err)
+			}
+			if len(called) != 2 || called[0] != "root" || called[1] != "sibling" {
+				t.Error("Destroyed sub-Event was dispatched. Called handlers:", called)
+			}
+			if _, _, ok := root.ChildMapping(child); ok {
+				t.Error("Destroyed sub-Event wasn't detached")
+			}
+			if err := child.Dispatch(ctx, testStruct{}); err != thevent.ErrDestroyed {
+				t.Error("Expected ErrDestroyed dispatching destroyed event, got:", err)
+			}
+			if err := child.AddHandlers(handler("new")); err != thevent.ErrDestroyed {
+				t.Error("Expected ErrDestroyed adding handlers, got:", err)
+			}
+			if _, err := child.New(testStruct{}, ""); err != thevent.ErrDestroyed {
+				t.Error("Expected ErrDestroyed creating sub-Event, got:", err)
+			}
+
+			called = nil
+			err = grandChild.Dispatch(ctx, testStruct{})
+			if tc.expectedCalled == nil && err != thevent.ErrDestroyed {
+				t.Error("Expected ErrDestroyed dispatching destroyed sub-Event, got:", err)
+			}
+			if len(called) != len(tc.expectedCalled) {
+				t.Error("Called handlers:", called, "expected:", tc.expectedCalled)
+			}
+
+			root.Destroy()
+			if _, ok := b.Event("root"); ok {
+				t.Error("Destroyed event wasn't unregistered")
+			}
+			if err := sibling.Dispatch(ctx, testStruct{}); err != thevent.ErrDestroyed {
+				t.Error("Expected ErrDestroyed dispatching destroyed sub-Event, got:", err)
+			}
+		})
+	}
+}
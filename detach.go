@@ -0,0 +1,39 @@
+package thevent
+
+import (
+	"context"
+	"time"
+)
+
+// detachedContext has the values of its parent context.Context, but isn't canceled with it and has no deadline
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// DetachContext creates a context.Context which has the values of ctx, e.g. the request's logger or trace, but
+// isn't canceled when ctx is canceled and has no deadline. e.g. so fire-and-forget work reacting to a request
+// scoped Event isn't canceled once the request finishes.
+func DetachContext(ctx context.Context) context.Context {
+	if _, ok := ctx.(detachedContext); ok {
+		return ctx
+	}
+	return detachedContext{parent: ctx}
+}
+
+// WithDetachedContext runs the handler with a context.Context detached from the dispatch's context.Context using
+// DetachContext(), so the handler isn't canceled when the dispatch's context.Context is, e.g. once the request
+// dispatching the Event finishes. Handlers with a detached context.Context should bound their own run time.
+func WithDetachedContext() HandlerOption {
+	return func(h *handler) error {
+		h.detached = true
+		return nil
+	}
+}
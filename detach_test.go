@@ -0,0 +1,62 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+type detachCtxKey struct{}
+
+func TestDetachContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), detachCtxKey{}, "v"), time.Hour)
+	detached := thevent.DetachContext(ctx)
+	cancel()
+	if ctx.Err() == nil {
+		t.Fatal("Expected parent context.Context to be canceled")
+	}
+	if detached.Err() != nil || detached.Done() != nil {
+		t.Error("Detached context.Context should not be canceled")
+	}
+	if _, ok := detached.Deadline(); ok {
+		t.Error("Detached context.Context should not have a deadline")
+	}
+	if v := detached.Value(detachCtxKey{}); v != "v" {
+		t.Error("Detached context.Context should have the parent's values, got:", v)
+	}
+	if thevent.DetachContext(detached) != detached {
+		t.Error("Detaching a detached context.Context should return it as is")
+	}
+}
+
+func TestWithDetachedContext(t *testing.T) {
+	var detachedErr, attachedErr error
+	e := thevent.Must(thevent.New(0))
+	if err := e.AddHandlerWithOptions(func(ctx context.Context, i int) error { // nolint: unparam
+		detachedErr = ctx.Err()
+		return nil
+	}, thevent.WithDetachedContext()); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	if err := e.AddHandlers(func(ctx context.Context, i int) error { // nolint: unparam
+		attachedErr = ctx.Err()
+		return nil
+	}); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := e.Dispatch(ctx, 1); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if detachedErr != nil {
+		t.Error("Handler with a detached context.Context got a canceled context.Context")
+	}
+	if attachedErr != context.Canceled {
+		t.Error("Expected other handlers to get the canceled context.Context, got:", attachedErr)
+	}
+}
@@ -0,0 +1,67 @@
+package thevent
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// WithAbandonedResultsTimeout configures the Event to drain the channel returned by DispatchAsyncWithResults if the
+// caller doesn't receive a result within the timeout, so handlers aren't leaked when the caller forgets to range
+// over the channel. The remaining results are discarded and the abandoned channel is logged with the logger, or the
+// standard logger if logger is nil. The channel is closed once it's been drained.
+//
+// In builds with the thevent_debug build tag, the stack of the DispatchAsyncWithResults call is also logged.
+func WithAbandonedResultsTimeout(timeout time.Duration, logger *log.Logger) Option {
+	return func(e *Event) error {
+		if timeout <= 0 {
+			return TypeError{errors.New("Abandoned results timeout must be positive")}
+		}
+		e.abandonedResultsTimeout = timeout
+		e.abandonedResultsLogger = logger
+		return nil
+	}
+}
+
+// drainAbandoned forwards the results to the returned channel until a result isn't received within the Event's
+// abandoned results timeout, after which the remaining results are discarded
+func (e *Event) drainAbandoned(results <-chan error) <-chan error {
+	if e.abandonedResultsTimeout <= 0 || results == nil {
+		return results
+	}
+	stack := callerStack()
+	out := make(chan error)
+	go func() {
+		defer close(out)
+		// The timer only runs while a result is waiting to be received, since slow handlers don't abandon the results
+		t := time.NewTimer(e.abandonedResultsTimeout)
+		t.Stop()
+		defer t.Stop()
+		for err := range results {
+			t.Reset(e.abandonedResultsTimeout)
+			select {
+			case out <- err:
+				t.Stop()
+				continue
+			case <-t.C:
+			}
+			e.logAbandoned(stack)
+			for range results {
+			}
+			return
+		}
+	}()
+	return out
+}
+
+func (e *Event) logAbandoned(stack []byte) {
+	logf := log.Printf
+	if e.abandonedResultsLogger != nil {
+		logf = e.abandonedResultsLogger.Printf
+	}
+	if len(stack) > 0 {
+		logf("thevent: draining abandoned results of event %s dispatched at:\n%s", e.dataType, stack)
+		return
+	}
+	logf("thevent: draining abandoned results of event %s", e.dataType)
+}
@@ -0,0 +1,112 @@
+package thevent_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+func TestWithAbandonedResultsTimeout(t *testing.T) {
+	_, err := thevent.New(testStruct{}, thevent.WithAbandonedResultsTimeout(0, nil))
+	errorMatchesGlob(t, err, "Abandoned results timeout must be positive")
+
+	testCases := []struct {
+		name      string
+		abandon   bool
+		expectLog bool
+	}{
+		{name: "received", expectLog: false},
+		{name: "abandoned", abandon: true, expectLog: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf syncBuffer
+			e, err := thevent.New(testStruct{},
+				func(ctx context.Context, s testStruct) error { return nil },
+				func(ctx context.Context, s testStruct) error { return nil },
+				thevent.WithAbandonedResultsTimeout(10*time.Millisecond, log.New(&buf, "", 0)))
+			if err != nil {
+				t.Fatal("Unable to create event:", err)
+			}
+			ch, err := e.DispatchAsyncWithResults(context.Background(), testStruct{})
+			if err != nil {
+				t.Fatal("Unexpected error dispatching:", err)
+			}
+			if !tc.abandon {
+				res := thevent.HandlersResults{}
+				res.Collect(ch)
+				if res.NumHandlers != 2 {
+					t.Error("Expected 2 results, got:", res.NumHandlers)
+				}
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			if err := e.Barrier(ctx); err != nil {
+				t.Fatal("Handlers leaked:", err)
+			}
+			if logged := strings.Contains(buf.String(), "draining abandoned results of event "+
+				"thevent_test.testStruct"); logged != tc.expectLog {
+				t.Error("Unexpected log output:", buf.String())
+			}
+			if tc.abandon {
+				for range ch {
+				}
+			}
+		})
+	}
+}
+
+func TestWithAbandonedResultsTimeoutSlowHandler(t *testing.T) {
+	var buf syncBuffer
+	e, err := thevent.New(testStruct{},
+		func(ctx context.Context, s testStruct) error {
+			time.Sleep(30 * time.Millisecond)
+			return errors.New("slow")
+		},
+		thevent.WithAbandonedResultsTimeout(10*time.Millisecond, log.New(&buf, "", 0)))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	for i := 0; i < 10; i++ {
+		ch, err := e.DispatchAsyncWithResults(context.Background(), testStruct{})
+		if err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+		var errs []error
+		for err := range ch {
+			errs = append(errs, err)
+		}
+		if len(errs) != 1 {
+			t.Fatal("Slow handler's result was discarded:", errs)
+		}
+	}
+	if buf.String() != "" {
+		t.Error("Unexpected log:", buf.String())
+	}
+}
@@ -1,6 +1,9 @@
 package thevent
 
 import (
+	"encoding/json"
+	"reflect"
+	"runtime"
 	"strconv"
 	"strings"
 )
@@ -8,6 +11,24 @@ import (
 // TypeError is used to signal an event or handler type mismatch/misconfiguration
 type TypeError struct{ error }
 
+// MarshalText implements encoding.TextMarshaler
+func (te TypeError) MarshalText() ([]byte, error) {
+	return []byte(te.Error()), nil
+}
+
+// MarshalJSON implements json.Marshaler. TypeErrors are marshaled as a JSON object with the kind and message of the
+// error. A TypeError wrapping a MultiTypeError includes the wrapped errors.
+func (te TypeError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(te.structured())
+}
+
+func (te TypeError) structured() structuredError {
+	if mte, ok := te.error.(MultiTypeError); ok {
+		return mte.structured()
+	}
+	return structuredError{Kind: "TypeError", Message: te.Error()}
+}
+
 // MultiTypeError combines/wraps multiple TypeErrors into a single error
 type MultiTypeError []TypeError
 
@@ -18,3 +39,49 @@ func (mte MultiTypeError) Error() string {
 	}
 	return "MultiTypeError: [" + strings.Join(quoted, ", ") + "]"
 }
+
+// MarshalText implements encoding.TextMarshaler. The messages of the errors are joined by semicolons.
+func (mte MultiTypeError) MarshalText() ([]byte, error) {
+	msgs := make([]string, 0, len(mte))
+	for _, e := range mte {
+		msgs = append(msgs, e.Error())
+	}
+	return []byte(strings.Join(msgs, "; ")), nil
+}
+
+// MarshalJSON implements json.Marshaler. MultiTypeErrors are marshaled as a JSON object with the kind of the error
+// and the structured errors.
+func (mte MultiTypeError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(mte.structured())
+}
+
+func (mte MultiTypeError) structured() structuredError {
+	text, _ := mte.MarshalText()
+	s := structuredError{Kind: "MultiTypeError", Message: string(text)}
+	for _, e := range mte {
+		s.Errors = append(s.Errors, e.structured())
+	}
+	return s
+}
+
+// structuredError is the structured representation of an error used for marshaling
+type structuredError struct {
+	Kind    string            `json:"kind"`
+	Event   string            `json:"event,omitempty"`
+	Handler string            `json:"handler,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Message string            `json:"message"`
+	Errors  []structuredError `json:"errors,omitempty"`
+}
+
+// handlerName gets the name of the handler's function, if available
+func handlerName(h Handler) string {
+	v := reflect.ValueOf(h)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	if f := runtime.FuncForPC(v.Pointer()); f != nil {
+		return f.Name()
+	}
+	return ""
+}
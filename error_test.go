@@ -1,6 +1,7 @@
 package thevent
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 )
@@ -15,3 +16,41 @@ func TestMultiTypeError(t *testing.T) {
 		t.Error("Got error string:", errStr, "instead of:", expectedErrStr)
 	}
 }
+
+func TestErrorMarshaling(t *testing.T) {
+	te := TypeError{errors.New("Test error 1")}
+	mte := MultiTypeError{te, TypeError{errors.New("Test error 2")}}
+	testCases := []struct {
+		name         string
+		err          error
+		expectedText string
+		expectedJSON string
+	}{
+		{name: "TypeError", err: te, expectedText: "Test error 1",
+			expectedJSON: `{"kind":"TypeError","message":"Test error 1"}`},
+		{name: "MultiTypeError", err: mte, expectedText: "Test error 1; Test error 2",
+			expectedJSON: `{"kind":"MultiTypeError","message":"Test error 1; Test error 2","errors":[` +
+				`{"kind":"TypeError","message":"Test error 1"},{"kind":"TypeError","message":"Test error 2"}]}`},
+		{name: "wrapped MultiTypeError", err: TypeError{mte}, expectedText: mte.Error(),
+			expectedJSON: `{"kind":"MultiTypeError","message":"Test error 1; Test error 2","errors":[` +
+				`{"kind":"TypeError","message":"Test error 1"},{"kind":"TypeError","message":"Test error 2"}]}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			text, err := tc.err.(interface{ MarshalText() ([]byte, error) }).MarshalText()
+			if err != nil {
+				t.Fatal("Unable to marshal text:", err)
+			}
+			if string(text) != tc.expectedText {
+				t.Error("Got text:", string(text), "instead of:", tc.expectedText)
+			}
+			b, err := json.Marshal(tc.err)
+			if err != nil {
+				t.Fatal("Unable to marshal JSON:", err)
+			}
+			if string(b) != tc.expectedJSON {
+				t.Error("Got JSON:", string(b), "instead of:", tc.expectedJSON)
+			}
+		})
+	}
+}
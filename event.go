@@ -5,8 +5,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var (
@@ -23,12 +26,17 @@ type Data interface{}
 //
 // A handler should have the following function signature:
 //      func(ctx context.Context, data interface{}) error
+//
+// A handler may also return an Outcome to control the dispatch:
+//      func(ctx context.Context, data interface{}) (Outcome, error)
 type Handler interface{}
 
 // Event is used to represent an event which may be handled and dispatched
 type Event struct {
 	dataType    reflect.Type
 	handlerType reflect.Type
+	// outcomeHandlerType is the type of handlers which return an Outcome
+	outcomeHandlerType reflect.Type
 
 	// Not using sync.Map since we need to protect 2 fields at the same time. Also, by not using sync.Map,
 	// we get compile-time type checks
@@ -36,24 +44,121 @@ type Event struct {
 
 	// Must use reflect.Value to represent a handler since func(int) != func(interface{})
 	// e.g. the empty interface has it's own distinct type. https://golang.org/ref/spec#Type_identity
-	handlers map[uintptr]reflect.Value
-	children map[*Event]*reflect.StructField
+	// handlers are stored in registration order, handlerPtrs is used to detect duplicate handlers
+	handlers    []*handler
+	handlerPtrs map[interface{}]struct{}
+	// children are stored in creation order
+	children []child
+
+	stopOnHandled bool
+	failFast      FailFastBehavior
+	propagation   Propagation
+	providers     map[reflect.Type]provider
+	balancer      Balancer
+	async         asyncTracker
+	faults        *faultInjector
+	// observers are notified of every dispatch before the handlers are run
+	observers []*observer
+	metadata  map[string]string
+	// contextDecorators are applied to the context.Context passed to the handlers on every dispatch
+	contextDecorators []func(context.Context) context.Context
+	// contextChecks validate the context.Context of every dispatch
+	contextChecks []func(context.Context) error
+	// middlewares wrap every run of the handlers
+	middlewares []Middleware
+	// withoutMiddleware is true if the Event opted out of its parent's middleware. See WithoutMiddleware()
+	withoutMiddleware bool
+	// resultSinks record the result of every handler
+	resultSinks             []ResultSink
+	abandonedResultsTimeout time.Duration
+	abandonedResultsLogger  *log.Logger
+	budget                  time.Duration
+	bestEffort              bool
+	bestEffortAsync         bool
+	isolated                bool
+	recoverPanics           bool
+
+	// parent is nil for root Events. bus is set for all of the Events in the hierarchy of an Event registered on a
+	// Bus and busName is set for the registered Event.
+	parent         *Event
+	bus            *Bus
+	busName        string
+	detachChildren bool
+	destroyed      bool
+
+	errorRateWatchdog    *errorRateWatchdog
+	errorRateMinSamples  uint
+	slowHandlerThreshold time.Duration
+	slo                  *sloTracker
+	priority             Priority
+	sizeGuards           []sizeGuard
+	coalescer            *coalescer
+	owner                *Capability
+	staleContextPolicy   StaleContextPolicy
+	pool                 *WorkerPool
+	trackHandling        bool
+	identity             HandlerIdentity
+	allowDuplicates      bool
+	// inherited are the Options inherited by sub-Events
+	inherited []Option
+	// inFlight is the number of handlers currently running
+	inFlight int32
+	// meta is true for meta-Events
+	meta bool
 }
 
+// child is a sub-Event and the field of the sub-Event's data used to hold the parent Event's data
+type child struct {
+	event *Event
+	// field is nil if the sub-Event uses the same data as the parent Event
+	field *reflect.StructField
+	// project creates the sub-Event's data from the parent Event's data, if set. Used instead of field.
+	project func(interface{}) interface{}
+}
+
+// ErrSkipped is sent instead of a handler's error by the results of asynchronous dispatches when the handler was
+// skipped, so that HandlersResults.Collect() counts the handler as skipped instead of handled. See Skipped
+var ErrSkipped = errors.New("Handler skipped")
+
 // HandlersResults contains the results of handlers handling a dispatched event
 type HandlersResults struct {
+	// NumHandlers is the number of handlers which handled the event, which doesn't include skipped handlers
 	NumHandlers uint
-	// Errors contains all of the non-nil errors returned by Handlers
+	// NumSkipped is the number of skipped handlers. See Skipped
+	NumSkipped uint
+	// Errors contains all of the non-nil errors returned by Handlers. The errors of named or labeled handlers are
+	// wrapped in HandlerErrors.
 	Errors []error
 }
 
+// HandlerResult contains the result of a single handler handling a dispatched event
+type HandlerResult struct {
+	// Event is the Event or sub-Event the handler was added to
+	Event   *Event
+	Handler Handler
+	// Name and Labels are the handler's name and labels, if any. See WithName() and WithLabels()
+	Name   string
+	Labels map[string]string
+	Err    error
+	// Outcome is the Outcome returned by the handler, if any
+	Outcome Outcome
+	// Attempts is the number of times the handler was run, including retries
+	Attempts int
+	// Duration is the total time spent running the handler, including retries and the backoff between them
+	Duration    time.Duration
+	Disposition Disposition
+	// RegisteredAt is the file and line where the handler was registered, in builds with the thevent_debug build
+	// tag. e.g. to locate a failing anonymous handler
+	RegisteredAt string
+}
+
 // Erred returns true if any Handler for the Event erred
 func (r *HandlersResults) Erred() bool {
 	return len(r.Errors) > 0
 }
 
 // ErrorRate returns the error rate of handlers' for a dispatched event. An error rate of 0.0 means that no errors
-// occurred and an error rate of 1.0 means that every handler errored
+// occurred and an error rate of 1.0 means that every handler errored. Skipped handlers aren't included.
 func (r *HandlersResults) ErrorRate() float32 {
 	if r.NumHandlers <= 0 {
 		return 0.0
@@ -65,6 +170,10 @@ func (r *HandlersResults) ErrorRate() float32 {
 // Designed to be used with Event.DispatchAsyncWithResults()
 func (r *HandlersResults) Collect(ch <-chan error) {
 	for err := range ch {
+		if err == ErrSkipped {
+			r.NumSkipped++
+			continue
+		}
 		r.NumHandlers++
 		if err != nil {
 			r.Errors = append(r.Errors, err)
@@ -72,11 +181,12 @@ func (r *HandlersResults) Collect(ch <-chan error) {
 	}
 }
 
+// convertToError converts the results returned by a handler into an error. The error is the last result.
 func convertToError(results []reflect.Value) error {
-	if len(results) != 1 {
+	if len(results) != 1 && len(results) != 2 {
 		return TypeError{fmt.Errorf("Expected handler to return a single value, not %d", len(results))}
 	}
-	res := results[0].Interface()
+	res := results[len(results)-1].Interface()
 	if res == nil {
 		return nil
 	}
@@ -84,33 +194,243 @@ func convertToError(results []reflect.Value) error {
 	if !ok {
 		return TypeError{fmt.Errorf("Expected handler to return an error type, not: %T", res)}
 	}
+	if errors.Is(err, Handled) || errors.Is(err, ErrStopPropagation) {
+		return nil
+	}
 	return err
 }
 
-func (r *HandlersResults) addResult(results []reflect.Value) error {
+// convertToOutcome converts the results returned by a handler into an Outcome. Handlers returning Handled have
+// the Handled flag set and handlers returning ErrStopPropagation have the StopPropagation flag set, even if the
+// errors are wrapped.
+func convertToOutcome(results []reflect.Value) Outcome {
+	var outcome Outcome
+	if len(results) == 2 {
+		outcome, _ = results[0].Interface().(Outcome)
+	}
+	if len(results) > 0 {
+		if err, ok := results[len(results)-1].Interface().(error); ok {
+			switch {
+			case errors.Is(err, Handled):
+				outcome.Handled = true
+			case errors.Is(err, ErrStopPropagation):
+				outcome.StopPropagation = true
+			}
+		}
+	}
+	return outcome
+}
+
+func (r *HandlersResults) addResult(h *handler, results []reflect.Value) error {
 	err := convertToError(results)
 	if _, ok := err.(TypeError); ok {
 		return err
 	}
+	if err == nil && convertToOutcome(results).Skipped {
+		r.NumSkipped++
+		return nil
+	}
 	r.NumHandlers++
 	if err != nil {
-		r.Errors = append(r.Errors, err)
+		r.Errors = append(r.Errors, h.wrapError(err))
+	}
+	return nil
+}
+
+// callHandlerAsync runs the handler in a new goroutine. The handler's error is sent to errorsCh, if set.
+func (e *Event) callHandlerAsync(ctx context.Context, d *dispatchState, h *handler, data reflect.Value,
+	wg *sync.WaitGroup, errorsCh chan<- error, pairs []*shadowPair) {
+	wg.Add(1)
+	if d.pending != nil {
+		d.pending.Add(1)
+	}
+	callback, pending := d.callback, d.pending
+	seq := e.async.start()
+	turn := h.turn()
+	e.goAsync(h, func() {
+		defer wg.Done()
+		defer e.async.finish(seq)
+		if pending != nil {
+			defer pending.Done()
+		}
+		hr := e.callFresh(ctx, h, data, turn)
+		primaryDone(pairs, h, hr)
+		if callback != nil {
+			callback(hr)
+		}
+		if errorsCh != nil {
+			if hr.Disposition == Skipped {
+				errorsCh <- ErrSkipped
+			} else {
+				errorsCh <- h.wrapError(hr.Err)
+			}
+		}
+	})
+}
+
+// callHandlerInTurn waits for the handler's turn to run, if its deliveries are ordered, before calling it
+func (e *Event) callHandlerInTurn(ctx context.Context, h *handler, args []reflect.Value,
+	turn uint64) ([]reflect.Value, HandlerResult) {
+	if h.ordered != nil {
+		h.ordered.wait(turn)
+		defer h.ordered.done()
+	}
+	return e.callHandler(ctx, h, args)
+}
+
+// callHandler runs the handler, retrying it according to its RetryPolicy, and records its result
+func (e *Event) callHandler(ctx context.Context, h *handler, args []reflect.Value) ([]reflect.Value, HandlerResult) {
+	if h.detached {
+		ctx = DetachContext(ctx)
+		args = []reflect.Value{reflect.ValueOf(ctx), args[1]}
+	}
+	atomic.AddInt32(&e.inFlight, 1)
+	defer func() {
+		atomic.AddInt32(&e.inFlight, -1)
+		if r := recover(); r != nil {
+			e.reportPanic(ctx, h, r)
+			panic(r)
+		}
+	}()
+	start := time.Now()
+	var res []reflect.Value
+	attempts := 0
+	for {
+		attempts++
+		res = e.callWithMiddleware(ctx, h, args)
+		if !h.retry.shouldRetry(ctx, res, attempts) {
+			break
+		}
+	}
+	d := time.Since(start)
+	if e.slowHandlerThreshold > 0 && d > e.slowHandlerThreshold {
+		e.dispatchMeta(ctx, HandlerSlow, SlowHandler{Event: e, Handler: h.fn.Interface(), Duration: d,
+			Threshold: e.slowHandlerThreshold})
+	}
+	err := convertToError(res)
+	hr := HandlerResult{Event: e, Handler: h.fn.Interface(), Name: h.name, Labels: h.labels, Err: err,
+		Outcome: convertToOutcome(res), Attempts: attempts, Duration: d,
+		Disposition: e.park(ctx, h, args[1], res, h.retry.disposition(res, attempts)), RegisteredAt: h.registeredAt}
+	if h.shadow != nil {
+		h.shadow(hr)
+		return res, hr
+	}
+	// Skipped handlers didn't handle the event, so they don't count towards the error rate or the SLO
+	if e.errorRateWatchdog != nil && hr.Disposition != Skipped {
+		e.errorRateWatchdog.record(err)
+	}
+	if e.slo != nil && hr.Disposition != Skipped {
+		e.slo.record(err, d)
+	}
+	e.recordResult(ctx, hr)
+	return res, hr
+}
+
+// callOnce runs the handler a single time
+func (e *Event) callOnce(ctx context.Context, h *handler, args []reflect.Value) []reflect.Value {
+	if h.serial != nil {
+		h.serial.Lock()
+		defer h.serial.Unlock()
+	}
+	if e.isolated {
+		return e.callIsolated(ctx, h, args)
+	}
+	if e.recoverPanics {
+		return e.callRecovered(ctx, h, args)
+	}
+	return e.invoke(ctx, h, args)
+}
+
+// invoke runs the handler, injecting faults if configured
+func (e *Event) invoke(ctx context.Context, h *handler, args []reflect.Value) []reflect.Value {
+	if e.faults != nil {
+		if err := e.faults.inject(ctx); err != nil {
+			return errorResults(err)
+		}
+	}
+	if usageAccounting && h.usage != nil {
+		defer h.usage.record(sampleUsage())
+	}
+	return h.call(e.providers, args)
+}
+
+// dispatchState holds the configuration and state of a single dispatch, which is shared with the dispatches of
+// the sub-Events
+type dispatchState struct {
+	async        bool
+	trackResults bool
+	// onResult is called with the result of every synchronously run handler, if set. The dispatch is stopped if
+	// onResult returns false.
+	onResult func(HandlerResult) bool
+	stopped  bool
+	// callback is called with the result of every handler, including asynchronously run handlers, if set
+	callback func(HandlerResult)
+	// pending tracks the asynchronously run handlers, if set
+	pending *sync.WaitGroup
+	// failed and skipSiblings are used by a sub-Event to signal a fail-fast failure to the parent Event
+	failed       bool
+	skipSiblings bool
+	// childData overrides the data sub-Events are dispatched with
+	childData map[*Event]interface{}
+	// batchConcurrency is the number of items of a batch dispatched concurrently
+	batchConcurrency int
+}
+
+func newDispatchState(async, trackResults bool, opts []DispatchOption) *dispatchState {
+	d := &dispatchState{async: async, trackResults: trackResults}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// checkDataType checks that the data may be dispatched by the Event
+func (e *Event) checkDataType(data interface{}) error {
+	if dataType := reflect.TypeOf(data); dataType != e.dataType {
+		return TypeError{fmt.Errorf("Dispatch called with incorrect event data type. Expected: %s Got: %v",
+			e.dataType.String(), dataType)}
 	}
 	return nil
 }
 
-func (e *Event) dispatch(ctx context.Context, async bool, trackResults bool,
-	data interface{}) (*HandlersResults, <-chan error, error) {
+func (e *Event) dispatch(ctx context.Context, d *dispatchState, data interface{}) (*HandlersResults, <-chan error,
+	error) {
+	if err := e.checkDataType(data); err != nil {
+		return nil, nil, err
+	}
+	if e.shed() {
+		return nil, nil, ErrShed
+	}
+	return e.dispatchAdmitted(ctx, d, data)
+}
+
+// dispatchAdmitted dispatches the Event once the data type has been checked and the dispatch hasn't been shed
+func (e *Event) dispatchAdmitted(ctx context.Context, d *dispatchState, data interface{}) (*HandlersResults,
+	<-chan error, error) {
+	async, trackResults := d.async, d.trackResults
 	dataValue := reflect.ValueOf(data)
 	dataType := dataValue.Type()
-	if dataType != e.dataType {
-		return nil, nil, TypeError{fmt.Errorf("Dispatch called with incorrect event data type. Expected: %s Got: %s",
-			e.dataType.String(), dataType.String())}
+	ctx = e.markHandling(e.decorateContext(e.withMetadata(ctx)))
+	if err := e.checkContext(ctx); err != nil {
+		return nil, nil, err
+	}
+	if err := e.checkDataSize(ctx, data); err != nil {
+		return nil, nil, err
+	}
+	ctx, release := e.withBudget(ctx, async)
+	defer release()
+	// args, results and wg are only allocated when needed so that synchronous dispatches allocate as little as
+	// possible
+	var argsArr [2]reflect.Value
+	args := append(argsArr[:0], reflect.ValueOf(ctx), dataValue)
+	var results *HandlersResults
+	if trackResults {
+		results = newHandlersResults()
+	}
+	var wg *sync.WaitGroup
+	if async {
+		wg = &sync.WaitGroup{}
 	}
-	args := []reflect.Value{reflect.ValueOf(ctx), dataValue}
-
-	var results HandlersResults
-	wg := sync.WaitGroup{}
 	var errorsCh chan error
 	if async && trackResults {
 		errorsCh = make(chan error)
@@ -122,25 +442,60 @@ func (e *Event) dispatch(ctx context.Context, async bool, trackResults bool,
 		}()
 	}
 	var errs MultiTypeError
+	// failed is true if a handler erred and the Event is configured to fail fast
+	var failed bool
+	// shared is a copy of the data shared by all sub-Events propagated by reference
+	var shared reflect.Value
+	// skipChildren is true if a handler's Outcome requested the sub-Events to be skipped
+	var skipChildren bool
 
+	// Snapshot the handlers and sub-Events so that the lock isn't held while they run. Handlers and sub-Events
+	// added or removed while the Event is being dispatched only affect later dispatches.
 	e.lock.RLock()
-	defer e.lock.RUnlock()
-	// Fine to hold onto read lock while handlers and all sub-Event handlers run
-	for _, h := range e.handlers {
+	if e.destroyed {
+		e.lock.RUnlock()
+		return nil, nil, ErrDestroyed
+	}
+	handlers, observers := e.handlers, e.observers
+	children := append([]child(nil), e.children...)
+	e.lock.RUnlock()
+
+	for _, o := range observers {
+		o.observe(ctx, data)
+	}
+	if e.balancer != nil && len(handlers) > 0 {
+		if i := e.balancer.Select(data, len(handlers)); i >= 0 && i < len(handlers) {
+			handlers = handlers[i : i+1]
+		} else {
+			handlers = nil
+		}
+	}
+	pairs := e.shadowPairs(ctx, data, handlers)
+	for _, h := range handlers {
+		if !async && budgetExceeded(ctx) {
+			break
+		}
+		if !h.shouldRun(ctx) {
+			e.skip(d, h, results, wg, errorsCh)
+			continue
+		}
+		if h.expiry != nil && !e.claim(h) {
+			continue
+		}
+		if h.shadow != nil {
+			e.callShadow(ctx, h, args, async, shadowPairOf(pairs, h))
+			continue
+		}
 		if async {
-			wg.Add(1)
-			go func(_h reflect.Value) {
-				defer wg.Done()
-				res := _h.Call(args)
-				if trackResults {
-					err := convertToError(res)
-					errorsCh <- err
-				}
-			}(h)
+			e.callHandlerAsync(ctx, d, h, dataValue, wg, errorsCh, pairs)
 		} else {
-			res := h.Call(args)
+			res, hr := e.callHandlerInTurn(ctx, h, args, h.turn())
+			primaryDone(pairs, h, hr)
+			if d.callback != nil {
+				d.callback(hr)
+			}
 			if trackResults {
-				if err := results.addResult(res); err != nil {
+				if err := results.addResult(h, res); err != nil {
 					e, ok := err.(TypeError)
 					if ok {
 						errs = append(errs, e)
@@ -150,12 +505,38 @@ func (e *Event) dispatch(ctx context.Context, async bool, trackResults bool,
 					}
 				}
 			}
+			if hr.Outcome.SkipChildren {
+				skipChildren = true
+			}
+			if d.onResult != nil && !d.onResult(hr) || hr.Outcome.StopPropagation {
+				d.stopped = true
+				break
+			}
+			if e.stopOnHandled && hr.Outcome.Handled {
+				// The event has been consumed so the remaining handlers are skipped
+				break
+			}
+			if e.failFast != 0 && hr.Err != nil {
+				failed = true
+				if e.failFast&SkipRemainingHandlers != 0 {
+					break
+				}
+			}
 		}
 	}
 	// Dispatch children after the parents
-	for subEvent, field := range e.children {
+	for _, c := range children {
+		if d.stopped || skipChildren || (failed && e.failFast&SkipRemainingHandlers != 0) ||
+			(!async && budgetExceeded(ctx)) {
+			break
+		}
+		subEvent, field := c.event, c.field
 		dataForChild := data // default to same event data as parent
-		if field != nil {
+		if overriddenData, ok := d.childData[subEvent]; ok {
+			dataForChild = overriddenData
+		} else if c.project != nil {
+			dataForChild = c.project(data)
+		} else if field != nil {
 			// Use reflection to populate the child struct w/ the parent event data
 			subDataPtr := reflect.New(subEvent.dataType)
 			subDataStruct := subDataPtr.Elem()
@@ -170,8 +551,12 @@ func (e *Event) dispatch(ctx context.Context, async bool, trackResults bool,
 					subEvent.dataType.String())}
 			}
 			if f.Kind() == reflect.Ptr {
-				if dataValue.CanAddr() {
-					f.Set(dataValue.Addr())
+				if subEvent.propagation == ByReference {
+					if !shared.IsValid() {
+						shared = reflect.New(dataType)
+						shared.Elem().Set(dataValue)
+					}
+					f.Set(shared)
 				} else {
 					// copy parent event struct data over
 					c := reflect.New(dataType)
@@ -184,8 +569,22 @@ func (e *Event) dispatch(ctx context.Context, async bool, trackResults bool,
 			}
 			dataForChild = subDataStruct.Interface()
 		}
-		// RWMutexes aren't re-entrant but we don't have this problem since each sub-Event has its own RWMutex
-		res, ch, err := subEvent.dispatch(ctx, async, trackResults, dataForChild)
+		if subEvent.bestEffort {
+			// Best-effort sub-Events are dispatched separately so they never affect the outcome of the dispatch
+			bd := &dispatchState{async: async || subEvent.bestEffortAsync, childData: d.childData}
+			if _, _, err := subEvent.dispatch(ctx, bd, dataForChild); err != nil && err != ErrDestroyed &&
+				err != ErrShed {
+				subEvent.reportDispatchErr(ctx, dataForChild, err)
+			}
+			continue
+		}
+		res, ch, err := subEvent.dispatch(ctx, d, dataForChild)
+		if err == ErrDestroyed || err == ErrShed {
+			// The sub-Event was destroyed after the dispatch started or was shed
+			continue
+		}
+		childFailed, skipSiblings := d.failed, d.skipSiblings
+		d.failed, d.skipSiblings = false, false
 		if err != nil {
 			e, ok := err.(TypeError)
 			if ok {
@@ -197,89 +596,304 @@ func (e *Event) dispatch(ctx context.Context, async bool, trackResults bool,
 		}
 		if trackResults {
 			// propagate sub-Event results
-			if async {
-				for e := range ch {
-					errorsCh <- e
-				}
-			} else {
+			if async && ch != nil {
+				// Forward in the background since the caller only starts receiving once the dispatch returns
+				wg.Add(1)
+				go func(ch <-chan error) {
+					defer wg.Done()
+					for e := range ch {
+						errorsCh <- e
+					}
+				}(ch)
+			} else if res != nil {
 				results.NumHandlers += res.NumHandlers
+				results.NumSkipped += res.NumSkipped
 				results.Errors = append(results.Errors, res.Errors...)
+				res.Release()
 			}
 		}
+		if childFailed && e.failFast != 0 {
+			failed = true
+		}
+		if skipSiblings {
+			break
+		}
+	}
+	if failed {
+		d.failed = e.failFast&PropagateError != 0
+		d.skipSiblings = e.failFast&SkipRemainingSiblings != 0
 	}
 	if async && trackResults {
+		results.Release()
 		return nil, errorsCh, nil
 	}
 	if len(errs) > 0 {
+		results.Release()
 		return nil, errorsCh, TypeError{errs}
 	}
-	return &results, nil, nil
+	return results, nil, nil
 }
 
 // Dispatch will notify all handlers of the Event and sub-Events using depth-first pre-order traversal.
 // Dispatch will not return until all Event and sub-Event handlers have finished running. Any errors encountered
 // which dispatching a
-func (e *Event) Dispatch(ctx context.Context, data interface{}) error {
-	_, _, err := e.dispatch(ctx, false, false, data)
+func (e *Event) Dispatch(ctx context.Context, data interface{}, opts ...DispatchOption) error {
+	var err error
+	if len(opts) == 0 {
+		// The dispatch state doesn't escape without options, so it isn't allocated
+		var d dispatchState
+		_, _, err = e.dispatchRoot(ctx, &d, data)
+	} else {
+		_, _, err = e.dispatchRoot(ctx, newDispatchState(false, false, opts), data)
+	}
+	e.reportDispatchErr(ctx, data, err)
 	return err
 }
 
 // DispatchWithResults is the same as Dispatch but collects the results
-func (e *Event) DispatchWithResults(ctx context.Context, data interface{}) (*HandlersResults, error) {
-	res, _, err := e.dispatch(ctx, false, true, data)
+func (e *Event) DispatchWithResults(ctx context.Context, data interface{},
+	opts ...DispatchOption) (*HandlersResults, error) {
+	res, _, err := e.dispatchRoot(ctx, newDispatchState(false, true, opts), data)
+	e.reportDispatchErr(ctx, data, err)
 	return res, err
 }
 
 // DispatchAsync will asynchronously notify all handlers of the Event and sub-Events. All handlers may not be
 // finished running when DispatchAsync returns.
-func (e *Event) DispatchAsync(ctx context.Context, data interface{}) error {
-	_, _, err := e.dispatch(ctx, true, false, data)
+func (e *Event) DispatchAsync(ctx context.Context, data interface{}, opts ...DispatchOption) error {
+	_, _, err := e.dispatchRoot(ctx, newDispatchState(true, false, opts), data)
+	e.reportDispatchErr(ctx, data, err)
 	return err
 }
 
 // DispatchAsyncWithResults is the same as DispatchAsync but additionally provides a channel that streams the
-// returned error from every handler for the event. It's the caller's responsibility to range over the channel as
-// the channel will be closed when all handlers are finished running. Not ranging over the returned channel will
-// leave dangling handlers. To "join" all of the errors use, HandlersResults.Collect().
-func (e *Event) DispatchAsyncWithResults(ctx context.Context, data interface{}) (<-chan error, error) {
-	_, ch, err := e.dispatch(ctx, true, true, data)
-	return ch, err
+// returned error from every handler for the event, or ErrSkipped for skipped handlers. It's the caller's
+// responsibility to range over the channel as the channel will be closed when all handlers are finished running. Not
+// ranging over the returned channel will leave dangling handlers. To "join" all of the errors use,
+// HandlersResults.Collect().
+func (e *Event) DispatchAsyncWithResults(ctx context.Context, data interface{},
+	opts ...DispatchOption) (<-chan error, error) {
+	_, ch, err := e.dispatchRoot(ctx, newDispatchState(true, true, opts), data)
+	e.reportDispatchErr(ctx, data, err)
+	return e.drainAbandoned(ch), err
+}
+
+// DispatchAsyncWithCallback is the same as DispatchAsync but calls onResult with the result of every handler of the
+// Event and sub-Events once the handler finishes, and calls onComplete once all of the handlers have finished.
+// onResult may be called concurrently by multiple goroutines. onComplete is always called exactly once, even if the
+// dispatch fails. Either callback may be nil.
+func (e *Event) DispatchAsyncWithCallback(ctx context.Context, data interface{}, onResult func(HandlerResult),
+	onComplete func(), opts ...DispatchOption) error {
+	d := newDispatchState(true, false, opts)
+	d.callback, d.pending = onResult, &sync.WaitGroup{}
+	// Hold the pending handlers until the dispatch returns so onComplete isn't called before all of the handlers
+	// have started
+	d.pending.Add(1)
+	_, _, err := e.dispatchRoot(ctx, d, data)
+	e.reportDispatchErr(ctx, data, err)
+	d.pending.Done()
+	go func() {
+		d.pending.Wait()
+		if onComplete != nil {
+			onComplete()
+		}
+	}()
+	return err
 }
 
-// AddHandlers adds the Handlers to the Event
+// AddHandlers adds the Handlers to the Event. Handlers are run in the order in which they're added. Handlers are
+// called using reflection, which allocates on every call. Handlers adapted using AsHandler() or added to a
+// TypedEvent are called without reflection, so synchronously dispatching pointer data to them doesn't allocate.
 func (e *Event) AddHandlers(handlers ...Handler) error {
-	convertedHandlers := make(map[uintptr]reflect.Value, len(handlers))
+	convertedHandlers, err := e.convertHandlers(handlers)
+	if err != nil {
+		return err
+	}
+	return e.addHandlers(nil, convertedHandlers)
+}
+
+// convertHandlers converts the Handlers to handlers. Duplicates are rejected once the handlers are added.
+func (e *Event) convertHandlers(handlers []Handler) ([]*handler, error) {
+	convertedHandlers := make([]*handler, 0, len(handlers))
 	for _, h := range handlers {
-		hV := reflect.ValueOf(h)
-		hT := hV.Type()
-		if hT != e.handlerType {
-			return TypeError{fmt.Errorf("Handler uses incorrect data type. Expected: %s Got: %s",
-				e.handlerType.String(), hT.String())}
+		cH, err := e.newHandler(h)
+		if err != nil {
+			return nil, err
 		}
-		if _, ok := convertedHandlers[hV.Pointer()]; ok {
-			return TypeError{errors.New("Unable to add duplicate handler")}
+		convertedHandlers = append(convertedHandlers, cH)
+	}
+	return convertedHandlers, nil
+}
+
+// AddHandlerWithOptions adds the Handler configured with the HandlerOptions to the Event
+func (e *Event) AddHandlerWithOptions(h Handler, opts ...HandlerOption) error {
+	cH, err := e.newHandlerWithOptions(h, opts)
+	if err != nil {
+		return err
+	}
+	return e.addHandlers(nil, []*handler{cH})
+}
+
+// newHandlerWithOptions converts the Handler to a handler configured with the HandlerOptions
+func (e *Event) newHandlerWithOptions(h Handler, opts []HandlerOption) (*handler, error) {
+	cH, err := e.newHandler(h)
+	if err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		if err := opt(cH); err != nil {
+			return nil, err
+		}
+	}
+	return cH, nil
+}
+
+// addHandlers adds the converted handlers to the Event. owner is the Capability used to add the handlers, if any.
+func (e *Event) addHandlers(owner *Capability, convertedHandlers []*handler) error {
+	// The handlers are identified before locking the Event since the HandlerIdentity may be slow
+	for _, cH := range convertedHandlers {
+		if err := e.identify(cH); err != nil {
+			return err
 		}
-		convertedHandlers[hV.Pointer()] = hV
 	}
 	e.lock.Lock()
 	defer e.lock.Unlock()
+	if e.destroyed {
+		return ErrDestroyed
+	}
+	if err := e.checkOwner(owner); err != nil {
+		return err
+	}
+	added := make(map[interface{}]struct{}, len(convertedHandlers))
 	for _, cH := range convertedHandlers {
-		if _, ok := e.handlers[cH.Pointer()]; ok {
+		if _, ok := e.handlerPtrs[cH.key()]; ok {
+			return TypeError{errors.New("Unable to add duplicate handler")}
+		}
+		if _, ok := added[cH.key()]; ok {
 			return TypeError{errors.New("Unable to add duplicate handler")}
 		}
+		added[cH.key()] = struct{}{}
+	}
+	handlers, err := orderHandlers(append(e.handlers, convertedHandlers...))
+	if err != nil {
+		return err
 	}
 	for _, cH := range convertedHandlers {
-		e.handlers[cH.Pointer()] = cH
+		e.handlerPtrs[cH.key()] = struct{}{}
 	}
+	e.handlers = handlers
 	return nil
 }
 
+// RemoveHandlers removes the Handlers from the Event by their function identity, so long-lived Events don't
+// accumulate handlers which are no longer used. In-flight dispatches aren't affected. Either all of the handlers are
+// removed, or none are and an error is returned, e.g. if one of the handlers hasn't been added to the Event.
+// Handlers added using RegisterMethods() or Subscribe() can't be removed using RemoveHandlers().
+func (e *Event) RemoveHandlers(handlers ...Handler) error {
+	return e.removeHandlers(nil, handlers)
+}
+
+// removeHandlers removes the handlers from the Event. owner is the Capability used to remove the handlers, if any.
+func (e *Event) removeHandlers(owner *Capability, handlers []Handler) error {
+	ptrs := make(map[uintptr]struct{}, len(handlers))
+	for _, h := range handlers {
+		if dh, ok := h.(directHandler); ok {
+			h = dh.handler
+		}
+		if h == nil {
+			return TypeError{errors.New("Handler must not be nil")}
+		}
+		hV := reflect.ValueOf(h)
+		if hV.Kind() != reflect.Func {
+			return TypeError{fmt.Errorf("Handler must be a func, not: %s", hV.Type().String())}
+		}
+		ptrs[hV.Pointer()] = struct{}{}
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.destroyed {
+		return ErrDestroyed
+	}
+	if err := e.checkOwner(owner); err != nil {
+		return err
+	}
+	// The handlers are copied since in-flight dispatches may be using them
+	remaining := make([]*handler, 0, len(e.handlers))
+	var removed []*handler
+	for _, h := range e.handlers {
+		if _, ok := ptrs[h.fn.Pointer()]; ok && h.method == nil && h.subscription == nil {
+			delete(ptrs, h.fn.Pointer())
+			removed = append(removed, h)
+			continue
+		}
+		remaining = append(remaining, h)
+	}
+	if len(ptrs) > 0 {
+		return TypeError{errors.New("Unable to remove handler which hasn't been added")}
+	}
+	for _, h := range removed {
+		delete(e.handlerPtrs, h.key())
+	}
+	e.handlers = remaining
+	return nil
+}
+
+// removeHandler removes the added handler from the Event, if it hasn't been removed yet
+func (e *Event) removeHandler(target *handler) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for i, h := range e.handlers {
+		if h == target {
+			// The handlers are copied since in-flight dispatches may be using them
+			e.handlers = append(e.handlers[:i:i], e.handlers[i+1:]...)
+			delete(e.handlerPtrs, h.key())
+			return
+		}
+	}
+}
+
+// observer is notified of every dispatch of an Event. Observers are identified by their pointers.
+type observer struct {
+	observe func(ctx context.Context, data interface{})
+}
+
+// addObserver notifies observe of every dispatch of the Event before the handlers are run
+func (e *Event) addObserver(observe func(ctx context.Context, data interface{})) *observer {
+	o := &observer{observe: observe}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.observers = append(e.observers, o)
+	return o
+}
+
+// removeObserver removes the added observer from the Event, if it hasn't been removed yet
+func (e *Event) removeObserver(target *observer) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for i, o := range e.observers {
+		if o == target {
+			// The observers are copied since in-flight dispatches may be using them
+			e.observers = append(e.observers[:i:i], e.observers[i+1:]...)
+			return
+		}
+	}
+}
+
 // New creates a new sub-Event that's also dispatched whenever the "parent" Event is dispatched.
 //
 // data must be a struct which either:
 //   - is the same as the parent Event's data (fieldName should be an empty string)
 //   - has a field with the parent Event's data specified by the fieldName
+//
+// Options used to configure the sub-Event may be passed along with the handlers.
 func (e *Event) New(data interface{}, fieldName string, handlers ...Handler) (*Event, error) {
+	return e.newChild(nil, data, fieldName, handlers)
+}
+
+// newChild creates a new sub-Event. owner is the Capability used to create the sub-Event, if any.
+func (e *Event) newChild(owner *Capability, data interface{}, fieldName string, handlers []Handler) (*Event,
+	error) {
 	if e.dataType.Kind() != reflect.Struct {
 		return nil, TypeError{fmt.Errorf("New() can only be used on Events with event type: %s, not %s",
 			reflect.Struct.String(), e.dataType.Kind().String())}
@@ -289,48 +903,236 @@ func (e *Event) New(data interface{}, fieldName string, handlers ...Handler) (*E
 		return nil, TypeError{fmt.Errorf("data type must be a %s, not %s",
 			reflect.Struct.String(), dataType.Kind().String())}
 	}
-	var matchedField *reflect.StructField
+	matchedField, err := e.matchField(dataType, fieldName)
+	if err != nil {
+		return nil, err
+	}
+
+	subEvent, err := New(data, e.inherit(handlers)...)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPropagation(subEvent, matchedField); err != nil {
+		return nil, err
+	}
+	if err := e.addChild(owner, child{event: subEvent, field: matchedField}); err != nil {
+		return nil, err
+	}
+	return subEvent, nil
+}
+
+// addChild adds the sub-Event to the Event. owner is the Capability used to add the sub-Event, if any.
+func (e *Event) addChild(owner *Capability, c child) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.destroyed {
+		return ErrDestroyed
+	}
+	if err := e.checkOwner(owner); err != nil {
+		return err
+	}
+	c.event.bus = e.bus
+	c.event.parent = e
+	e.children = append(e.children, c)
+	return nil
+}
+
+// matchField finds the field with the given name in the sub-Event's data type which holds the Event's data.
+// A nil field is returned if fieldName is empty and the sub-Event's data type is the same as the Event's.
+func (e *Event) matchField(dataType reflect.Type, fieldName string) (*reflect.StructField, error) {
+	return matchParentField(e.dataType, dataType, fieldName)
+}
+
+// matchParentField gets the field of the sub-Event's data type used to hold data of the parent's data type
+func matchParentField(parentType, dataType reflect.Type, fieldName string) (*reflect.StructField, error) {
+	if fieldName == "" {
+		if dataType != parentType { // && dataType != reflect.PtrTo(parentType) {
+			return nil, TypeError{fmt.Errorf("sub-Event's data type (%s) doesn't match parent's (%s)",
+				dataType.String(), parentType.String())}
+		}
+		return nil, nil
+	}
+	f, ok := dataType.FieldByName(fieldName)
+	if !ok {
+		return nil, TypeError{fmt.Errorf("No such field with name: %s in data", fieldName)}
+	}
+	if f.Type != parentType && f.Type != reflect.PtrTo(parentType) {
+		return nil, TypeError{fmt.Errorf("Field with name: %s has wrong type: %s. Should be: %s",
+			fieldName, f.Type.String(), parentType.String())}
+	}
+	if f.PkgPath != "" {
+		return nil, TypeError{fmt.Errorf("Field with name: %s has correct data type but must be exported",
+			fieldName)}
+	}
+	return &f, nil
+}
+
+// checkPropagation verifies that the sub-Event's Propagation can be used with the field
+func checkPropagation(subEvent *Event, field *reflect.StructField) error {
+	if subEvent.propagation == ByReference && (field == nil || field.Type.Kind() != reflect.Ptr) {
+		return TypeError{
+			errors.New("Propagating by reference requires a field which is a pointer to the parent's data")}
+	}
+	return nil
+}
+
+// RemapChild changes the field of the sub-Event's data used to hold the Event's data. An empty fieldName maps the
+// Event's data directly to the sub-Event, which requires both Events to have the same data type. Invalid mappings
+// may be found using ValidateChildren().
+func (e *Event) RemapChild(child *Event, fieldName string) error {
+	return e.remapChild(nil, child, fieldName)
+}
+
+// remapChild changes the field of the sub-Event's data. owner is the Capability used to remap the sub-Event, if any.
+func (e *Event) remapChild(owner *Capability, child *Event, fieldName string) error {
+	field, err := e.matchField(child.dataType, fieldName)
+	if err != nil {
+		return err
+	}
+	if err := checkPropagation(child, field); err != nil {
+		return err
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if err := e.checkOwner(owner); err != nil {
+		return err
+	}
+	for i, c := range e.children {
+		if c.event != child {
+			continue
+		}
+		if c.project != nil {
+			return TypeError{errors.New("Unable to remap a sub-Event with projected data")}
+		}
+		e.children[i].field = field
+		return nil
+	}
+	return TypeError{errors.New("Unable to remap an Event which isn't a sub-Event")}
+}
 
-	if fieldName != "" {
-		f, ok := dataType.FieldByName(fieldName)
-		if !ok {
-			return nil, TypeError{fmt.Errorf("No such field with name: %s in data", fieldName)}
+// ValidateChildren checks the mappings of the Event's sub-Events against a sample of the Event's data, e.g. of a
+// hot-reloaded data type, so that the invalid mappings may be repaired using RemapChild() before dispatches fail. A
+// sub-Event's mapping is valid if the field of the sub-Event's data holds the sample's data type or a pointer to it,
+// or if the sub-Event uses the same data as the Event and has the sample's data type. Sub-Events with projected data
+// aren't checked.
+func (e *Event) ValidateChildren(sample interface{}) error {
+	sampleType := reflect.TypeOf(sample)
+	if sampleType == nil {
+		return TypeError{errors.New("Sample data must not be nil")}
+	}
+	e.lock.RLock()
+	children := append([]child(nil), e.children...)
+	e.lock.RUnlock()
+	var errs MultiTypeError
+	for _, c := range children {
+		if c.project != nil {
+			continue
 		}
-		if f.Type != e.dataType && f.Type != reflect.PtrTo(e.dataType) {
-			return nil, TypeError{fmt.Errorf("Field with name: %s has wrong type: %s. Should be: %s",
-				fieldName, f.Type.String(), e.dataType.String())}
+		fieldName := ""
+		if c.field != nil {
+			fieldName = c.field.Name
 		}
-		if f.PkgPath != "" {
-			return nil, TypeError{fmt.Errorf("Field with name: %s has correct data type but must be exported",
-				fieldName)}
+		if _, err := matchParentField(sampleType, c.event.dataType, fieldName); err != nil {
+			errs = append(errs, TypeError{fmt.Errorf("Invalid mapping of sub-Event: %s. %v",
+				c.event.dataType.String(), err)})
 		}
-		matchedField = &f
-	} else if dataType != e.dataType { // && dataType != reflect.PtrTo(e.dataType) {
-		return nil, TypeError{fmt.Errorf("sub-Event's data type (%s) doesn't match parent's (%s)", dataType.String(),
-			e.dataType.String())}
 	}
+	if len(errs) > 0 {
+		return TypeError{errs}
+	}
+	return nil
+}
 
-	subEvent, err := New(data, handlers...)
+// ChildMapping returns how the parent Event's data is mapped into the data of the given sub-Event. fieldName is the
+// name of the sub-Event's data field holding the parent's data and viaPointer is true if the field is a pointer to
+// the parent's data. fieldName is empty if the sub-Event uses the same data as the parent or if the sub-Event's data
+// is projected. ok is false if the given Event isn't a sub-Event of the Event.
+func (e *Event) ChildMapping(child *Event) (fieldName string, viaPointer bool, ok bool) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	for _, c := range e.children {
+		if c.event != child {
+			continue
+		}
+		if c.field == nil {
+			return "", false, true
+		}
+		return c.field.Name, c.field.Type.Kind() == reflect.Ptr, true
+	}
+	return "", false, false
+}
+
+// newProjected creates a new sub-Event whose data is created from the parent Event's data by project. owner is the
+// Capability used to create the sub-Event, if any.
+func (e *Event) newProjected(owner *Capability, data interface{}, project func(interface{}) interface{},
+	handlers ...Handler) (*Event, error) {
+	subEvent, err := New(data, e.inherit(handlers)...)
 	if err != nil {
 		return nil, err
 	}
-	e.lock.Lock()
-	defer e.lock.Unlock()
-	e.children[subEvent] = matchedField
+	if err := e.addChild(owner, child{event: subEvent, project: project}); err != nil {
+		return nil, err
+	}
 	return subEvent, nil
 }
 
+// NewVersion creates a new sub-Event for another version of the Event's data, which is also dispatched whenever the
+// Event is dispatched. e.g. so handlers of the old and new versions of the data may coexist during a migration.
+//
+// convert creates the sub-Event's data from the Event's data and must have the signature:
+//
+//	func(old OldData) NewData
+//
+// where OldData is the Event's data type and NewData is the sub-Event's data type.
+func (e *Event) NewVersion(data interface{}, convert interface{}, handlers ...Handler) (*Event, error) {
+	return e.newVersion(nil, data, convert, handlers)
+}
+
+// newVersion creates a new sub-Event for another version of the Event's data. owner is the Capability used to
+// create the sub-Event, if any.
+func (e *Event) newVersion(owner *Capability, data interface{}, convert interface{}, handlers []Handler) (*Event,
+	error) {
+	if convert == nil {
+		return nil, TypeError{errors.New("Converter must not be nil")}
+	}
+	convertV := reflect.ValueOf(convert)
+	convertT := convertV.Type()
+	if expected := reflect.FuncOf([]reflect.Type{e.dataType}, []reflect.Type{reflect.TypeOf(data)},
+		false); convertT != expected {
+		return nil, TypeError{fmt.Errorf("Converter has incorrect type. Expected: %s Got: %s", expected.String(),
+			convertT.String())}
+	}
+	return e.newProjected(owner, data, func(d interface{}) interface{} {
+		return convertV.Call([]reflect.Value{reflect.ValueOf(d)})[0].Interface()
+	}, handlers...)
+}
+
 // New creates a new Event
 //
 // data is a sample of the event Data that handlers will receive. The empty/zero value of the event Data
-// should be used.
+// should be used. Options used to configure the Event may be passed along with the handlers.
 func New(data interface{}, handlers ...Handler) (*Event, error) {
+	opts, handlers := splitOptions(handlers)
 	dataType := reflect.TypeOf(data)
 	handlerType := reflect.FuncOf([]reflect.Type{ctxType, dataType}, []reflect.Type{errType}, false)
-	event := &Event{dataType: dataType, handlerType: handlerType, lock: &sync.RWMutex{},
-		handlers: make(map[uintptr]reflect.Value, len(handlers)),
-		children: map[*Event]*reflect.StructField{}}
-	if err := event.AddHandlers(handlers...); err != nil {
+	outcomeHandlerType := reflect.FuncOf([]reflect.Type{ctxType, dataType}, []reflect.Type{outcomeType, errType},
+		false)
+	event := &Event{dataType: dataType, handlerType: handlerType, outcomeHandlerType: outcomeHandlerType,
+		lock:        &sync.RWMutex{},
+		handlers:    make([]*handler, 0, len(handlers)),
+		handlerPtrs: make(map[interface{}]struct{}, len(handlers)),
+	}
+	for _, opt := range opts {
+		if err := opt(event); err != nil {
+			return nil, err
+		}
+	}
+	// The handlers passed to New() are added by the creator, who may own the Event
+	convertedHandlers, err := event.convertHandlers(handlers)
+	if err != nil {
+		return nil, err
+	}
+	if err := event.addHandlers(event.owner, convertedHandlers); err != nil {
 		return nil, err
 	}
 	return event, nil
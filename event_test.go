@@ -3,7 +3,9 @@ package thevent_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"path"
+	"sync"
 	"testing"
 )
 
@@ -174,6 +176,49 @@ func TestAddHandlers(t *testing.T) {
 	errorMatchesGlob(t, err, "Unable to add duplicate handler")
 }
 
+func TestRemoveHandlers(t *testing.T) {
+	called := 0
+	counting := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		called++
+		return nil
+	}
+	e, err := thevent.New(testStruct{}, testStructHandler, counting)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+
+	testCases := []struct {
+		name      string
+		handlers  []thevent.Handler
+		errorGlob string
+	}{
+		{name: "nil handler", handlers: []thevent.Handler{nil}, errorGlob: "Handler must not be nil"},
+		{name: "non-function handler", handlers: []thevent.Handler{5}, errorGlob: "Handler must be a func, not: int"},
+		{name: "handler not added", handlers: []thevent.Handler{testStructHandler, intHandler},
+			errorGlob: "Unable to remove handler which hasn't been added"},
+		{name: "valid handler", handlers: []thevent.Handler{testStructHandler}},
+		{name: "removed handler", handlers: []thevent.Handler{testStructHandler},
+			errorGlob: "Unable to remove handler which hasn't been added"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errorMatchesGlob(t, e.RemoveHandlers(tc.handlers...), tc.errorGlob)
+		})
+	}
+
+	res, err := e.DispatchWithResults(context.Background(), testStruct{})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if res.NumHandlers != 1 || called != 1 {
+		t.Error("Expected only the remaining handler to be called:", res, called)
+	}
+	// Removed handlers may be added again
+	if err := e.AddHandlers(testStructHandler); err != nil {
+		t.Error("Unable to add removed handler:", err)
+	}
+}
+
 func TestDispatch(t *testing.T) {
 	e, err := thevent.New(5)
 	if err != nil {
@@ -736,3 +781,317 @@ func TestHandlersResultsErrorRate(t *testing.T) {
 		})
 	}
 }
+
+func TestChildMapping(t *testing.T) {
+	e, err := thevent.New(TestStruct{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	other, err := thevent.New(TestStruct{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	sameChild, err := e.New(TestStruct{}, "")
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	embeddedChild, err := e.New(testExportedEmbeddedStruct{}, "TestStruct")
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	ptrChild, err := e.New(testExportedNamedExportedPtrStruct{}, "Test")
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	grandChild, err := sameChild.New(TestStruct{}, "")
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+
+	testCases := []struct {
+		name       string
+		child      *thevent.Event
+		fieldName  string
+		viaPointer bool
+		ok         bool
+	}{
+		{name: "same data", child: sameChild, ok: true},
+		{name: "embedded field", child: embeddedChild, fieldName: "TestStruct", ok: true},
+		{name: "pointer field", child: ptrChild, fieldName: "Test", viaPointer: true, ok: true},
+		{name: "grandchild", child: grandChild},
+		{name: "unrelated event", child: other},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fieldName, viaPointer, ok := e.ChildMapping(tc.child)
+			if fieldName != tc.fieldName || viaPointer != tc.viaPointer || ok != tc.ok {
+				t.Error("Got child mapping:", fieldName, viaPointer, ok, "expected:", tc.fieldName, tc.viaPointer,
+					tc.ok)
+			}
+		})
+	}
+}
+
+func TestRemapChild(t *testing.T) {
+	type twoFields struct {
+		A TestStruct
+		B *TestStruct
+		C int
+	}
+	e, err := thevent.New(TestStruct{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	other, err := thevent.New(twoFields{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	var got []string
+	child, err := e.New(twoFields{}, "A", func(ctx context.Context, d twoFields) error {
+		if d.B != nil {
+			got = append(got, "B")
+		} else {
+			got = append(got, "A")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	refChild, err := e.New(twoFields{}, "B", thevent.WithPropagation(thevent.ByReference))
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+
+	testCases := []struct {
+		name      string
+		child     *thevent.Event
+		fieldName string
+		errorGlob string
+	}{
+		{name: "remap to pointer field", child: child, fieldName: "B"},
+		{name: "remap to missing field", child: child, fieldName: "D",
+			errorGlob: "No such field with name: D in data"},
+		{name: "remap to wrong field type", child: child, fieldName: "C",
+			errorGlob: "Field with name: C has wrong type: int. Should be: thevent_test.TestStruct"},
+		{name: "remap to same data", child: child,
+			errorGlob: "sub-Event's data type (thevent_test.twoFields) doesn't match parent's (*)"},
+		{name: "remap by reference to value field", child: refChild, fieldName: "A",
+			errorGlob: "Propagating by reference requires a field which is a pointer to the parent's data"},
+		{name: "remap non sub-Event", child: other, fieldName: "A",
+			errorGlob: "Unable to remap an Event which isn't a sub-Event"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := e.RemapChild(tc.child, tc.fieldName)
+			errorMatchesGlob(t, err, tc.errorGlob)
+		})
+	}
+
+	if fieldName, viaPointer, _ := e.ChildMapping(child); fieldName != "B" || !viaPointer {
+		t.Error("Sub-Event wasn't remapped. Field:", fieldName)
+	}
+	if err := e.Dispatch(context.Background(), TestStruct{}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(got) != 1 || got[0] != "B" {
+		t.Error("Remapped sub-Event got unexpected data:", got)
+	}
+}
+
+func TestValidateChildren(t *testing.T) {
+	type orderV1 struct{ Total int }
+	// orderV2 is a reloaded version of orderV1
+	type orderV2 struct{ TotalCents int64 }
+	type shipment struct{ Order orderV1 }
+	typed, err := thevent.NewTyped[orderV1]()
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	e := typed.Event
+	if _, err := e.New(shipment{}, "Order"); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if _, err := e.New(orderV1{}, ""); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	// Sub-Events with projected data aren't validated
+	if _, err := thevent.Sub(typed, func(o orderV1) int { return o.Total }); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+
+	if err := e.ValidateChildren(orderV1{}); err != nil {
+		t.Error("Unexpected error validating sub-Events:", err)
+	}
+	expected := `MultiTypeError: ["Invalid mapping of sub-Event: thevent_test.shipment. Field with name: Order ` +
+		`has wrong type: thevent_test.orderV1. Should be: thevent_test.orderV2", "Invalid mapping of sub-Event: ` +
+		`thevent_test.orderV1. sub-Event's data type (thevent_test.orderV1) doesn't match parent's ` +
+		`(thevent_test.orderV2)"]`
+	if err := e.ValidateChildren(orderV2{}); err == nil || err.Error() != expected {
+		t.Errorf("Got error: %v expected: %s", err, expected)
+	}
+	errorMatchesGlob(t, e.ValidateChildren(nil), "Sample data must not be nil")
+}
+
+func TestNewVersion(t *testing.T) {
+	type orderV1 struct{ Total int }
+	type orderV2 struct{ TotalCents int64 }
+	var got []string
+	v1Handler := func(ctx context.Context, o orderV1) error { // nolint: unparam
+		got = append(got, fmt.Sprint("v1:", o.Total))
+		return nil
+	}
+	v2Handler := func(ctx context.Context, o orderV2) error { // nolint: unparam
+		got = append(got, fmt.Sprint("v2:", o.TotalCents))
+		return nil
+	}
+	e, err := thevent.New(orderV1{}, v1Handler)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+
+	testCases := []struct {
+		name      string
+		convert   interface{}
+		errorGlob string
+	}{
+		{name: "nil converter", errorGlob: "Converter must not be nil"},
+		{name: "wrong converter", convert: func(o orderV1) int { return o.Total },
+			errorGlob: "Converter has incorrect type. Expected: func(thevent_test.orderV1) thevent_test.orderV2 " +
+				"Got: func(thevent_test.orderV1) int"},
+		{name: "not a function", convert: 5, errorGlob: "Converter has incorrect type.*"},
+		{name: "success", convert: func(o orderV1) orderV2 { return orderV2{TotalCents: int64(o.Total) * 100} }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := e.NewVersion(orderV2{}, tc.convert, v2Handler)
+			errorMatchesGlob(t, err, tc.errorGlob)
+		})
+	}
+
+	if err := e.Dispatch(context.Background(), orderV1{Total: 3}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(got) != 2 || got[0] != "v1:3" || got[1] != "v2:300" {
+		t.Error("Handlers got unexpected data:", got)
+	}
+}
+
+func TestRegistrationDuringDispatch(t *testing.T) {
+	var called []string
+	added := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		called = append(called, "added")
+		return nil
+	}
+	addedChild := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		called = append(called, "added child")
+		return nil
+	}
+	var e *thevent.Event
+	registering := func(ctx context.Context, s testStruct) error {
+		called = append(called, "registering")
+		if s.v > 0 {
+			return nil
+		}
+		if err := e.AddHandlers(added); err != nil {
+			return err
+		}
+		_, err := e.New(testStruct{}, "", addedChild)
+		return err
+	}
+	e = thevent.Must(thevent.New(testStruct{}, registering))
+
+	ctx := context.Background()
+	res, err := e.DispatchWithResults(ctx, testStruct{})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if res.Erred() {
+		t.Fatal("Unexpected handler errors:", res.Errors)
+	}
+	if len(called) != 1 {
+		t.Error("Registrations during a dispatch should only affect later dispatches. Called:", called)
+	}
+	called = nil
+	if err := e.Dispatch(ctx, testStruct{v: 1}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(called) != 3 || called[0] != "registering" || called[1] != "added" || called[2] != "added child" {
+		t.Error("Unexpected called handlers:", called)
+	}
+}
+
+func TestDispatchAsyncWithResultsSubEvents(t *testing.T) {
+	e := thevent.Must(thevent.New(testStruct{}, func(ctx context.Context, s testStruct) error { return nil }))
+	child := thevent.Must(e.New(testStruct{}, "", func(ctx context.Context, s testStruct) error { return nil }))
+	thevent.Must(child.New(testStruct{}, "", func(ctx context.Context, s testStruct) error {
+		return errors.New("grandchild failed")
+	}))
+	ch, err := e.DispatchAsyncWithResults(context.Background(), testStruct{})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	res := thevent.HandlersResults{}
+	res.Collect(ch)
+	if res.NumHandlers != 3 || len(res.Errors) != 1 {
+		t.Error("Unexpected results:", res)
+	}
+}
+
+func TestDispatchAsyncWithCallback(t *testing.T) {
+	for _, sequential := range []bool{false, true} {
+		t.Run(fmt.Sprint("sequential=", sequential), func(t *testing.T) {
+			var opts []thevent.BusOption
+			if sequential {
+				opts = append(opts, thevent.Sequential())
+			}
+			bus := thevent.NewBus(opts...)
+			e, err := bus.New("a", testStruct{}, func(ctx context.Context, s testStruct) error { return nil },
+				func(ctx context.Context, s testStruct) error { return errors.New("failed") })
+			if err != nil {
+				t.Fatal("Unable to create event:", err)
+			}
+			if _, err := e.New(testStruct{}, "", func(ctx context.Context, s testStruct) error {
+				return errors.New("sub-Event failed")
+			}); err != nil {
+				t.Fatal("Unable to create sub-Event:", err)
+			}
+
+			var lock sync.Mutex
+			var errs []string
+			completed := make(chan struct{})
+			err = e.DispatchAsyncWithCallback(context.Background(), testStruct{}, func(hr thevent.HandlerResult) {
+				lock.Lock()
+				defer lock.Unlock()
+				if hr.Err != nil {
+					errs = append(errs, hr.Err.Error())
+				} else {
+					errs = append(errs, "")
+				}
+			}, func() { close(completed) })
+			if err != nil {
+				t.Fatal("Unexpected error dispatching:", err)
+			}
+			<-completed
+			lock.Lock()
+			defer lock.Unlock()
+			if len(errs) != 3 {
+				t.Fatal("Expected 3 results, got:", errs)
+			}
+			found := map[string]bool{}
+			for _, err := range errs {
+				found[err] = true
+			}
+			if !found[""] || !found["failed"] || !found["sub-Event failed"] {
+				t.Error("Unexpected results:", errs)
+			}
+		})
+	}
+
+	e := thevent.Must(thevent.New(testStruct{}))
+	completed := make(chan struct{})
+	err := e.DispatchAsyncWithCallback(context.Background(), 1, nil, func() { close(completed) })
+	errorMatchesGlob(t, err, "Dispatch called with incorrect event data type.*")
+	// onComplete is called even if the dispatch fails
+	<-completed
+}
@@ -0,0 +1,67 @@
+package thevent
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// expiry expires a handler after a number of invocations or once a deadline has passed
+type expiry struct {
+	// maxCalls is the number of invocations after which the handler expires, if positive
+	maxCalls int64
+	calls    int64
+	// deadline is when the handler expires, if set
+	deadline time.Time
+}
+
+// expiring gets the handler's expiry, creating it if needed
+func (h *handler) expiring() *expiry {
+	if h.expiry == nil {
+		h.expiry = &expiry{}
+	}
+	return h.expiry
+}
+
+// MaxCalls removes the handler from its Event once the handler has been invoked n times, e.g. for one-off handlers.
+// Invocations are counted when they're started, so concurrent dispatches never invoke the handler more than n
+// times. Retries of an invocation aren't counted, and neither are dispatches skipping the handler.
+func MaxCalls(n int) HandlerOption {
+	return func(h *handler) error {
+		if n <= 0 {
+			return TypeError{errors.New("Maximum number of handler calls must be positive")}
+		}
+		h.expiring().maxCalls = int64(n)
+		return nil
+	}
+}
+
+// ExpireAfter removes the handler from its Event once d has passed since the handler was added, e.g. for handlers
+// scoped to a session. An expired handler isn't invoked anymore, and is removed by the Event's next dispatch.
+func ExpireAfter(d time.Duration) HandlerOption {
+	return func(h *handler) error {
+		if d <= 0 {
+			return TypeError{errors.New("Handler expiry must be positive")}
+		}
+		h.expiring().deadline = time.Now().Add(d)
+		return nil
+	}
+}
+
+// claim claims an invocation of the handler for a dispatch, removing the handler from the Event once it has
+// expired. false is returned if the handler has expired and must not be invoked.
+func (e *Event) claim(h *handler) bool {
+	x := h.expiry
+	if !x.deadline.IsZero() && !time.Now().Before(x.deadline) {
+		e.removeHandler(h)
+		return false
+	}
+	if x.maxCalls <= 0 {
+		return true
+	}
+	calls := atomic.AddInt64(&x.calls, 1)
+	if calls >= x.maxCalls {
+		e.removeHandler(h)
+	}
+	return calls <= x.maxCalls
+}
@@ -0,0 +1,68 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestMaxCalls(t *testing.T) {
+	var calls int
+	e := thevent.Must(thevent.New(0, intHandler))
+	if err := e.AddHandlerWithOptions(func(ctx context.Context, i int) error { // nolint: unparam
+		calls++
+		return nil
+	}, thevent.MaxCalls(2)); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	for _, expected := range []uint{2, 2, 1} {
+		results, err := e.DispatchWithResults(context.Background(), 1)
+		if err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+		if results.NumHandlers != expected {
+			t.Error("Got handlers run:", results.NumHandlers, "instead of:", expected)
+		}
+	}
+	if calls != 2 {
+		t.Error("Expected the handler to be called twice, got:", calls)
+	}
+
+	errorMatchesGlob(t, e.AddHandlerWithOptions(intHandler, thevent.MaxCalls(0)),
+		"Maximum number of handler calls must be positive")
+}
+
+func TestExpireAfter(t *testing.T) {
+	var calls int
+	handler := func(ctx context.Context, i int) error { // nolint: unparam
+		calls++
+		return nil
+	}
+	e := thevent.Must(thevent.New(0))
+	if err := e.AddHandlerWithOptions(handler, thevent.ExpireAfter(10*time.Millisecond),
+		thevent.MaxCalls(5)); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	ctx := context.Background()
+	if err := e.Dispatch(ctx, 1); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := e.Dispatch(ctx, 1); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if calls != 1 {
+		t.Error("Expected the handler to be called once, got:", calls)
+	}
+	// The expired handler has been removed, so it may be added again
+	if err := e.AddHandlers(handler); err != nil {
+		t.Error("Unable to add handler again:", err)
+	}
+
+	errorMatchesGlob(t, e.AddHandlerWithOptions(intHandler, thevent.ExpireAfter(0)),
+		"Handler expiry must be positive")
+}
@@ -0,0 +1,120 @@
+package thevent
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// FederatedEvent is a dispatch of a named Event forwarded between federated Buses
+type FederatedEvent struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+	// Path is the IDs of the Buses the dispatch has been forwarded through. The first ID is the origin of the
+	// dispatch.
+	Path []string `json:"path"`
+}
+
+// Origin gets the ID of the Bus where the Event was originally dispatched
+func (fe FederatedEvent) Origin() string {
+	if len(fe.Path) == 0 {
+		return ""
+	}
+	return fe.Path[0]
+}
+
+// Transport connects a Bus to a peer Bus. e.g. over a message queue or HTTP
+type Transport interface {
+	// Send sends the dispatch to the peer
+	Send(ctx context.Context, fe FederatedEvent) error
+	// Receive calls receive for every dispatch sent by the peer until the context is done or the transport fails
+	Receive(ctx context.Context, receive func(context.Context, FederatedEvent) error) error
+}
+
+// WithBusID sets the ID of the Bus used as the origin of federated dispatches. IDs must be unique amongst federated
+// Buses. A random ID is used by default.
+func WithBusID(id string) BusOption {
+	return func(b *Bus) {
+		b.id = id
+	}
+}
+
+// ID gets the ID of the Bus. See WithBusID()
+func (b *Bus) ID() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.id == "" {
+		var r [8]byte
+		rand.Read(r[:]) // nolint: errcheck
+		b.id = hex.EncodeToString(r[:])
+	}
+	return b.id
+}
+
+type federationCtxKey struct{}
+
+// Federate shares the dispatches of the registered Events selected by the filter with a peer Bus over the
+// Transport. Dispatches of selected Events are sent to the peer before the Event's handlers are run and dispatches
+// received from the peer are dispatched synchronously to the local Events with the same name. A nil filter selects
+// every Event. Event data must be serializable as JSON.
+//
+// Dispatches are never forwarded back to a Bus they've already passed through, so federated Buses may forward each
+// other's dispatches, e.g. in a chain or ring of Buses. Since dispatching an Event dispatches its sub-Events, the
+// filter should not select both an Event and one of its sub-Events. Failures to send a dispatch are reported using
+// the DispatchFailed meta-Event.
+//
+// Federate blocks until the context is done or the Transport fails, after which dispatches are no longer forwarded,
+// so Federate may be retried with a new Transport. Events registered after Federate is called aren't federated.
+func (b *Bus) Federate(ctx context.Context, t Transport, filter func(name string) bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	id := b.ID()
+	selected := map[string]*Event{}
+	names, events := b.registered()
+	for i, e := range events {
+		if filter != nil && !filter(names[i]) {
+			continue
+		}
+		selected[names[i]] = e
+		o := b.forward(ctx, t, id, names[i], e)
+		defer e.removeObserver(o)
+	}
+	return t.Receive(ctx, func(rCtx context.Context, fe FederatedEvent) error {
+		for _, p := range fe.Path {
+			if p == id {
+				// The dispatch has looped back
+				return nil
+			}
+		}
+		e, ok := selected[fe.Event]
+		if !ok {
+			return nil
+		}
+		data, err := e.decode(fe.Data)
+		if err != nil {
+			return fmt.Errorf("Unable to decode data for federated event %q: %v", fe.Event, err)
+		}
+		return e.Dispatch(context.WithValue(rCtx, federationCtxKey{}, fe.Path), data)
+	})
+}
+
+// forward sends the dispatches of the Event to the Transport until the context is done or the returned observer is
+// removed
+func (b *Bus) forward(ctx context.Context, t Transport, id, name string, e *Event) *observer {
+	return e.addObserver(func(dCtx context.Context, data interface{}) {
+		if ctx.Err() != nil {
+			return
+		}
+		path, _ := dCtx.Value(federationCtxKey{}).([]string)
+		fe := FederatedEvent{Event: name, Path: append(path[:len(path):len(path)], id)}
+		var err error
+		if fe.Data, err = json.Marshal(data); err == nil {
+			err = t.Send(dCtx, fe)
+		}
+		if err != nil {
+			e.reportDispatchErr(dCtx, data, fmt.Errorf("Unable to forward event %q: %v", name, err))
+		}
+	})
+}
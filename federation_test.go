@@ -0,0 +1,171 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+// chanTransport sends dispatches to a peer chanTransport over a channel
+type chanTransport struct {
+	in, out chan thevent.FederatedEvent
+	ready   chan struct{}
+}
+
+func newChanTransports() (*chanTransport, *chanTransport) {
+	a, b := make(chan thevent.FederatedEvent, 10), make(chan thevent.FederatedEvent, 10)
+	return &chanTransport{in: a, out: b, ready: make(chan struct{})},
+		&chanTransport{in: b, out: a, ready: make(chan struct{})}
+}
+
+func (t *chanTransport) Send(ctx context.Context, fe thevent.FederatedEvent) error {
+	t.out <- fe
+	return nil
+}
+
+func (t *chanTransport) Receive(ctx context.Context,
+	receive func(context.Context, thevent.FederatedEvent) error) error {
+	close(t.ready)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case fe := <-t.in:
+			if err := receive(ctx, fe); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func TestBusFederate(t *testing.T) {
+	received := map[string]chan fixtureUser{"a": make(chan fixtureUser, 10), "b": make(chan fixtureUser, 10)}
+	newBus := func(id string) (*thevent.Bus, *thevent.Event, *thevent.Event) {
+		b := thevent.NewBus(thevent.WithBusID(id))
+		shared, err := b.New("shared", fixtureUser{}, func(ctx context.Context, u fixtureUser) error { // nolint: unparam
+			received[id] <- u
+			return nil
+		})
+		if err != nil {
+			t.Fatal("Unable to create event:", err)
+		}
+		local, err := b.New("local", fixtureUser{}, func(ctx context.Context, u fixtureUser) error { // nolint: unparam
+			received[id] <- u
+			return nil
+		})
+		if err != nil {
+			t.Fatal("Unable to create event:", err)
+		}
+		return b, shared, local
+	}
+	busA, sharedA, localA := newBus("a")
+	busB, _, _ := newBus("b")
+	if busA.ID() != "a" {
+		t.Error("Unexpected bus ID:", busA.ID())
+	}
+	if thevent.NewBus().ID() == "" {
+		t.Error("Bus ID should be generated")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	transportA, transportB := newChanTransports()
+	filter := func(name string) bool { return name == "shared" }
+	errs := make(chan error, 2)
+	go func() { errs <- busA.Federate(ctx, transportA, filter) }()
+	go func() { errs <- busB.Federate(ctx, transportB, filter) }()
+	<-transportA.ready
+	<-transportB.ready
+
+	if err := localA.Dispatch(ctx, fixtureUser{ID: 1}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if err := sharedA.Dispatch(ctx, fixtureUser{ID: 2}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if s := <-received["a"]; s.ID != 1 {
+		t.Error("Unexpected local dispatch:", s)
+	}
+	if s := <-received["a"]; s.ID != 2 {
+		t.Error("Unexpected local dispatch:", s)
+	}
+	select {
+	case s := <-received["b"]:
+		if s.ID != 2 {
+			t.Error("Unexpected federated dispatch:", s)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Federated dispatch not received")
+	}
+	// The dispatch forwarded back to a is dropped
+	select {
+	case s := <-received["a"]:
+		t.Error("Dispatch looped back:", s)
+	case s := <-received["b"]:
+		t.Error("Unexpected federated dispatch:", s)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	cancel()
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != context.Canceled {
+			t.Error("Unexpected federation error:", err)
+		}
+	}
+}
+
+// countingTransport counts the dispatches sent and fails receiving once failed is closed
+type countingTransport struct {
+	sent   int32
+	failed chan struct{}
+}
+
+func (t *countingTransport) Send(ctx context.Context, fe thevent.FederatedEvent) error {
+	atomic.AddInt32(&t.sent, 1)
+	return nil
+}
+
+func (t *countingTransport) Receive(ctx context.Context,
+	receive func(context.Context, thevent.FederatedEvent) error) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.failed:
+		return errors.New("transport failed")
+	}
+}
+
+func TestBusFederateRetry(t *testing.T) {
+	b := thevent.NewBus()
+	e, err := b.New("shared", fixtureUser{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	failed := &countingTransport{failed: make(chan struct{})}
+	close(failed.failed)
+	if err := b.Federate(ctx, failed, nil); err == nil || err.Error() != "transport failed" {
+		t.Fatal("Expected the transport to fail, got:", err)
+	}
+	retried := &countingTransport{failed: make(chan struct{})}
+	errs := make(chan error, 1)
+	go func() { errs <- b.Federate(ctx, retried, nil) }()
+	// Wait for the dispatches to be forwarded
+	time.Sleep(20 * time.Millisecond)
+	if err := e.Dispatch(context.Background(), fixtureUser{ID: 1}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	close(retried.failed)
+	<-errs
+	if failed.sent != 0 || retried.sent != 1 {
+		t.Error("Expected the dispatch to only be forwarded by the retried transport, sent:", failed.sent, retried.sent)
+	}
+}
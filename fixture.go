@@ -0,0 +1,153 @@
+package thevent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// RecordedDispatch is a single recorded dispatch of a named Event
+type RecordedDispatch struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// Recorder records the dispatches of named Events so event flows can be stored as golden fixtures, replayed and
+// compared in tests. Event data must be serializable as JSON.
+type Recorder struct {
+	lock       sync.Mutex
+	dispatches []RecordedDispatch
+	err        error
+}
+
+// NewRecorder creates a new Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Record records every dispatch of the Event under the given name, including dispatches made by a parent Event.
+// Dispatches are recorded in the order in which they're made, before any of the Event's handlers are run.
+func (r *Recorder) Record(name string, e *Event) {
+	e.addObserver(func(_ context.Context, data interface{}) {
+		b, err := json.Marshal(data)
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		if err != nil {
+			if r.err == nil {
+				r.err = fmt.Errorf("Unable to record dispatch of event %q: %v", name, err)
+			}
+			return
+		}
+		r.dispatches = append(r.dispatches, RecordedDispatch{Event: name, Data: b})
+	})
+}
+
+// Dispatches returns the recorded dispatches
+func (r *Recorder) Dispatches() []RecordedDispatch {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	dispatches := make([]RecordedDispatch, len(r.dispatches))
+	copy(dispatches, r.dispatches)
+	return dispatches
+}
+
+// Err returns the first error encountered while recording, if any
+func (r *Recorder) Err() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.err
+}
+
+// WriteFixture writes the dispatches as a fixture of JSON lines
+func WriteFixture(w io.Writer, dispatches []RecordedDispatch) error {
+	enc := json.NewEncoder(w)
+	for _, d := range dispatches {
+		if err := enc.Encode(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadFixture reads dispatches from a fixture written by WriteFixture()
+func ReadFixture(r io.Reader) ([]RecordedDispatch, error) {
+	var dispatches []RecordedDispatch
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var d RecordedDispatch
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			return nil, fmt.Errorf("Unable to read fixture line %d: %v", line, err)
+		}
+		dispatches = append(dispatches, d)
+	}
+	return dispatches, scanner.Err()
+}
+
+// Replay synchronously dispatches the recorded dispatches to the Events with matching names. Dispatches of Events
+// without a matching name are skipped, so only the root Events of recorded hierarchies should usually be given
+// since dispatching a parent also dispatches the sub-Events.
+func Replay(ctx context.Context, dispatches []RecordedDispatch, events map[string]*Event) error {
+	for i, d := range dispatches {
+		e, ok := events[d.Event]
+		if !ok {
+			continue
+		}
+		data, err := e.decode(d.Data)
+		if err != nil {
+			return fmt.Errorf("Unable to decode data for dispatch %d of event %q: %v", i, d.Event, err)
+		}
+		if err := e.Dispatch(ctx, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DiffFixtures compares the expected and actual dispatches and describes every difference. Data is compared as
+// compacted JSON. No differences are returned if the dispatches match.
+func DiffFixtures(expected, actual []RecordedDispatch) []string {
+	var diffs []string
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		switch {
+		case i >= len(actual):
+			diffs = append(diffs, fmt.Sprintf("dispatch %d: missing event %q with data %s", i, expected[i].Event,
+				compactJSON(expected[i].Data)))
+		case i >= len(expected):
+			diffs = append(diffs, fmt.Sprintf("dispatch %d: unexpected event %q with data %s", i, actual[i].Event,
+				compactJSON(actual[i].Data)))
+		default:
+			e, a := expected[i], actual[i]
+			if e.Event != a.Event || compactJSON(e.Data) != compactJSON(a.Data) {
+				diffs = append(diffs, fmt.Sprintf("dispatch %d: expected event %q with data %s, got event %q with data %s",
+					i, e.Event, compactJSON(e.Data), a.Event, compactJSON(a.Data)))
+			}
+		}
+	}
+	return diffs
+}
+
+// decode decodes JSON event data for the Event
+func (e *Event) decode(b []byte) (interface{}, error) {
+	data := reflect.New(e.dataType)
+	if err := json.Unmarshal(b, data.Interface()); err != nil {
+		return nil, err
+	}
+	return data.Elem().Interface(), nil
+}
+
+func compactJSON(b []byte) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return string(b)
+	}
+	return buf.String()
+}
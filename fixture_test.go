@@ -0,0 +1,112 @@
+package thevent_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+type fixtureUser struct {
+	ID   int
+	Name string
+}
+
+type fixtureLogin struct {
+	User fixtureUser
+}
+
+func newFixtureHierarchy(t *testing.T, r *thevent.Recorder) *thevent.Event {
+	userEvent, err := thevent.New(fixtureUser{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	loginEvent, err := userEvent.New(fixtureLogin{}, "User")
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	r.Record("user", userEvent)
+	r.Record("user.login", loginEvent)
+	return userEvent
+}
+
+func TestRecordReplay(t *testing.T) {
+	ctx := context.Background()
+	recorder := thevent.NewRecorder()
+	userEvent := newFixtureHierarchy(t, recorder)
+	for _, u := range []fixtureUser{{ID: 1, Name: "Jimi"}, {ID: 2, Name: "Janis"}} {
+		if err := userEvent.Dispatch(ctx, u); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	}
+	if err := recorder.Err(); err != nil {
+		t.Fatal("Unexpected error recording:", err)
+	}
+
+	var fixture bytes.Buffer
+	if err := thevent.WriteFixture(&fixture, recorder.Dispatches()); err != nil {
+		t.Fatal("Unable to write fixture:", err)
+	}
+	expectedFixture := `{"event":"user","data":{"ID":1,"Name":"Jimi"}}
+{"event":"user.login","data":{"User":{"ID":1,"Name":"Jimi"}}}
+{"event":"user","data":{"ID":2,"Name":"Janis"}}
+{"event":"user.login","data":{"User":{"ID":2,"Name":"Janis"}}}
+`
+	if fixture.String() != expectedFixture {
+		t.Fatal("Got fixture:", fixture.String(), "expected:", expectedFixture)
+	}
+
+	golden, err := thevent.ReadFixture(&fixture)
+	if err != nil {
+		t.Fatal("Unable to read fixture:", err)
+	}
+	replayRecorder := thevent.NewRecorder()
+	replayEvent := newFixtureHierarchy(t, replayRecorder)
+	if err := thevent.Replay(ctx, golden, map[string]*thevent.Event{"user": replayEvent}); err != nil {
+		t.Fatal("Unable to replay fixture:", err)
+	}
+	if diffs := thevent.DiffFixtures(golden, replayRecorder.Dispatches()); len(diffs) > 0 {
+		t.Error("Replayed dispatches don't match the fixture:", diffs)
+	}
+}
+
+func TestReadFixtureInvalid(t *testing.T) {
+	_, err := thevent.ReadFixture(strings.NewReader(`{"event":"user","data":{}}` + "\nnot json\n"))
+	errorMatchesGlob(t, err, "Unable to read fixture line 2: *")
+}
+
+func TestDiffFixtures(t *testing.T) {
+	expected := []thevent.RecordedDispatch{
+		{Event: "a", Data: []byte(`{"ID": 1}`)},
+		{Event: "b", Data: []byte(`{"ID":2}`)},
+	}
+	testCases := []struct {
+		name     string
+		actual   []thevent.RecordedDispatch
+		expected []string
+	}{
+		{name: "same", actual: []thevent.RecordedDispatch{
+			{Event: "a", Data: []byte(`{"ID":1}`)}, {Event: "b", Data: []byte(`{"ID":2}`)}}},
+		{name: "different data", actual: []thevent.RecordedDispatch{
+			{Event: "a", Data: []byte(`{"ID":1}`)}, {Event: "b", Data: []byte(`{"ID":3}`)}},
+			expected: []string{`dispatch 1: expected event "b" with data {"ID":2}, got event "b" with data {"ID":3}`}},
+		{name: "missing", actual: []thevent.RecordedDispatch{{Event: "a", Data: []byte(`{"ID":1}`)}},
+			expected: []string{`dispatch 1: missing event "b" with data {"ID":2}`}},
+		{name: "unexpected", actual: []thevent.RecordedDispatch{
+			{Event: "a", Data: []byte(`{"ID":1}`)}, {Event: "b", Data: []byte(`{"ID":2}`)},
+			{Event: "c", Data: []byte(`{}`)}},
+			expected: []string{`dispatch 2: unexpected event "c" with data {}`}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diffs := thevent.DiffFixtures(expected, tc.actual)
+			if strings.Join(diffs, "\n") != strings.Join(tc.expected, "\n") {
+				t.Error("Got diffs:", diffs, "expected:", tc.expected)
+			}
+		})
+	}
+}
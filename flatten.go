@@ -0,0 +1,93 @@
+package thevent
+
+import (
+	"reflect"
+)
+
+// projection runs a handler of a sub-Event with the sub-Event's data projected from the data of a flattened Event
+type projection struct {
+	target    *handler
+	providers map[reflect.Type]provider
+	project   func(interface{}) interface{}
+}
+
+// Flatten creates a new Event with the same data type as root whose handlers are the handlers of root and of all of
+// its sub-Events, in the order in which dispatching root runs them, e.g. for the operational simplicity of a flat
+// dispatch once the wiring of an Event hierarchy has stabilized. The handlers of sub-Events are run with the data
+// their sub-Event would be dispatched with, projected from the flattened Event's data. The handlers keep their
+// HandlerOptions, while the flattened Event is configured using opts instead of the Options of root and its
+// sub-Events.
+//
+// The flattened Event is a snapshot: handlers and sub-Events added to the hierarchy later aren't added to it. Since
+// there are no sub-Events to skip, Outcomes with SkipChildren set don't affect the flattened Event's dispatches,
+// and sub-Events with ByReference propagation don't share their data.
+func Flatten(root *Event, opts ...Option) (*Event, error) {
+	hs := make([]Handler, 0, len(opts))
+	for _, opt := range opts {
+		hs = append(hs, opt)
+	}
+	flat, err := New(reflect.Zero(root.dataType).Interface(), hs...)
+	if err != nil {
+		return nil, err
+	}
+	handlers := root.flatten(nil, nil)
+	flat.lock.Lock()
+	defer flat.lock.Unlock()
+	for _, h := range handlers {
+		flat.handlerPtrs[h.key()] = struct{}{}
+	}
+	flat.handlers = handlers
+	return flat, nil
+}
+
+// flatten copies the handlers of the Event and its sub-Events in the order in which they're run. project creates
+// the Event's data from the flattened Event's data, and is nil for the flattened Event itself.
+func (e *Event) flatten(flattened []*handler, project func(interface{}) interface{}) []*handler {
+	e.lock.RLock()
+	handlers := e.handlers
+	children := append([]child(nil), e.children...)
+	e.lock.RUnlock()
+	for _, h := range handlers {
+		cp := *h
+		cp.subscription = nil
+		if project != nil {
+			cp.projection = &projection{target: h, providers: e.providers, project: project}
+		}
+		// Handlers of different Events of the hierarchy may share a function, so every copy is distinct
+		cp.id = &cp
+		flattened = append(flattened, &cp)
+	}
+	for _, c := range children {
+		childProject := c.projector(e.dataType)
+		if project != nil {
+			parentProject, projectChild := project, childProject
+			childProject = func(d interface{}) interface{} { return projectChild(parentProject(d)) }
+		}
+		flattened = c.event.flatten(flattened, childProject)
+	}
+	return flattened
+}
+
+// projector creates the sub-Event's data from the data of the parent Event with the given data type, in the same
+// way as dispatching the parent Event does
+func (c child) projector(parentType reflect.Type) func(interface{}) interface{} {
+	if c.project != nil {
+		return c.project
+	}
+	if c.field == nil {
+		return func(d interface{}) interface{} { return d }
+	}
+	subType, index := c.event.dataType, c.field.Index
+	return func(d interface{}) interface{} {
+		subData := reflect.New(subType).Elem()
+		f := subData.FieldByIndex(index)
+		if f.Kind() == reflect.Ptr {
+			p := reflect.New(parentType)
+			p.Elem().Set(reflect.ValueOf(d))
+			f.Set(p)
+		} else {
+			f.Set(reflect.ValueOf(d))
+		}
+		return subData.Interface()
+	}
+}
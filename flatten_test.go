@@ -0,0 +1,81 @@
+package thevent_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+type flatOrder struct{ ID int }
+type flatOrderShipped struct{ Order flatOrder }
+type flatOrderAudited struct{ Order *flatOrder }
+type flatShippingNotice struct{ Shipped flatOrderShipped }
+
+func TestFlatten(t *testing.T) {
+	var calls []string
+	root := thevent.Must(thevent.New(flatOrder{}, func(ctx context.Context, o flatOrder) error { // nolint: unparam
+		calls = append(calls, fmt.Sprint("root:", o.ID))
+		return nil
+	}))
+	shipped, err := root.New(flatOrderShipped{}, "Order",
+		func(ctx context.Context, s flatOrderShipped) error { // nolint: unparam
+			calls = append(calls, fmt.Sprint("shipped:", s.Order.ID))
+			return nil
+		})
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if _, err := shipped.New(flatShippingNotice{}, "Shipped",
+		func(ctx context.Context, n flatShippingNotice) error { // nolint: unparam
+			calls = append(calls, fmt.Sprint("notice:", n.Shipped.Order.ID))
+			return nil
+		}); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if _, err := root.New(flatOrderAudited{}, "Order",
+		func(ctx context.Context, a flatOrderAudited) error { // nolint: unparam
+			calls = append(calls, fmt.Sprint("audited:", a.Order.ID))
+			return nil
+		}); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if _, err := root.NewVersion(0, func(o flatOrder) int { return o.ID * 10 },
+		func(ctx context.Context, id int) error { // nolint: unparam
+			calls = append(calls, fmt.Sprint("v2:", id))
+			return nil
+		}); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+
+	ctx := context.Background()
+	if err := root.Dispatch(ctx, flatOrder{ID: 4}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	expected := calls
+	calls = nil
+
+	flat, err := thevent.Flatten(root)
+	if err != nil {
+		t.Fatal("Unable to flatten:", err)
+	}
+	if n := len(flat.Handlers()); n != 5 {
+		t.Error("Expected 5 flattened handlers, got:", n)
+	}
+	results, err := flat.DispatchWithResults(ctx, flatOrder{ID: 4})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if results.NumHandlers != 5 {
+		t.Error("Expected 5 handlers to run, got:", results.NumHandlers)
+	}
+	if fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Error("Got calls:", calls, "instead of:", expected)
+	}
+	if fmt.Sprint(expected) != "[root:4 shipped:4 notice:4 audited:4 v2:40]" {
+		t.Error("Unexpected dispatch order:", expected)
+	}
+}
@@ -1 +1,3 @@
 module github.com/dhui/thevent
+
+go 1.23
@@ -0,0 +1,332 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var outcomeType = reflect.TypeOf(Outcome{})
+
+// handler is a Handler that has been added to an Event
+type handler struct {
+	fn reflect.Value
+	// deps are the types of the dependencies injected into the handler before the event data
+	deps []reflect.Type
+
+	leaderElector Elector
+	healther      Healther
+	warmer        Warmer
+	snapshotter   Snapshotter
+	retry         *RetryPolicy
+	// delayedRetry parks the handler's failed invocations in the Bus's RetryQueue
+	delayedRetry bool
+	// serial is set for serialized handlers
+	serial *sync.Mutex
+	// ordered is set for handlers whose deliveries are ordered
+	ordered *sequencer
+	// method identifies handlers which are methods registered by RegisterMethods()
+	method interface{}
+	// direct calls the handler without reflection, if set
+	direct func(context.Context, interface{}) error
+	// registeredAt is where the handler was registered in debug builds
+	registeredAt string
+	// usage accounts the handler's resource consumption in debug builds
+	usage *handlerUsage
+	// addedAt is when the handler was added
+	addedAt time.Time
+	// lazy materializes the handler when it's first called, if set
+	lazy *lazy
+	// projection runs the handler of a sub-Event of a flattened Event, if set
+	projection *projection
+	// name identifies the handler for ordering, after are the names of the handlers it must run after
+	name  string
+	after []string
+	// labels are the handler's labels
+	labels map[string]string
+	// priority orders the handler relative to the handlers it isn't constrained with
+	priority int
+	// expiry is set for handlers which expire
+	expiry *expiry
+	// detached runs the handler with a detached context.Context
+	detached bool
+	// shadow records the results of shadow handlers, which never affect dispatches
+	shadow func(HandlerResult)
+	// comparison is set for shadow handlers compared with their primary handler
+	comparison *shadowComparison
+	// id is the handler's identity assigned by the Event's HandlerIdentity, if any
+	id interface{}
+	// subscription is set for handlers added using Event.Subscribe()
+	subscription *Subscription
+}
+
+// directHandler is a Handler which is also provided as a function that may be called without reflection, so that
+// running it doesn't allocate. See TypedEvent.
+type directHandler struct {
+	handler Handler
+	call    func(context.Context, interface{}) error
+}
+
+// nilErrorResults are the results of a handler returning a nil error. The results must not be modified.
+var nilErrorResults = []reflect.Value{reflect.Zero(errType)}
+
+// key identifies the handler to detect duplicate handlers. Method values created using reflection share a code
+// pointer, so methods are identified separately. Lazy handlers and subscriptions are never duplicates, unless
+// identified using the Event's HandlerIdentity.
+func (h *handler) key() interface{} {
+	if h.id != nil {
+		return h.id
+	}
+	if h.subscription != nil {
+		return h.subscription
+	}
+	if h.lazy != nil {
+		return h.lazy
+	}
+	if h.method != nil {
+		return h.method
+	}
+	return h.fn.Pointer()
+}
+
+// HandlerOption configures a single handler. See Event.AddHandlerWithOptions()
+type HandlerOption func(*handler) error
+
+// Elector reports whether this instance currently holds a leadership lease. Implementations are pluggable, e.g.
+// backed by a database lock or a consensus system, and must be safe for concurrent use.
+type Elector interface {
+	IsLeader(ctx context.Context) bool
+}
+
+// WithLeaderOnly only runs the handler on the instance which currently holds the leadership lease, so singleton
+// side effects don't happen on every replica handling the event. The handler is skipped on other instances.
+func WithLeaderOnly(elector Elector) HandlerOption {
+	return func(h *handler) error {
+		if elector == nil {
+			return TypeError{errors.New("Elector must not be nil")}
+		}
+		h.leaderElector = elector
+		return nil
+	}
+}
+
+// WithSerialized never runs the handler concurrently, e.g. so the handler may use resources which aren't safe for
+// concurrent use. Invocations of the handler by concurrent or asynchronous dispatches wait for the running
+// invocation to return. The Event's other handlers are still run concurrently.
+func WithSerialized() HandlerOption {
+	return func(h *handler) error {
+		h.serial = &sync.Mutex{}
+		return nil
+	}
+}
+
+// WithOrderedDelivery runs the handler for the dispatches of the Event in the order in which they were made, even
+// for asynchronous dispatches, e.g. for handlers maintaining incremental state. Deliveries to the handler are
+// serialized. A handler with ordered delivery must not synchronously dispatch its own Event.
+func WithOrderedDelivery() HandlerOption {
+	return func(h *handler) error {
+		h.ordered = newSequencer()
+		return nil
+	}
+}
+
+// turn reserves the handler's turn to run, if its deliveries are ordered. The turn must be used.
+func (h *handler) turn() uint64 {
+	if h.ordered == nil {
+		return 0
+	}
+	return h.ordered.ticket()
+}
+
+// shouldRun returns false if the handler should be skipped for the dispatch
+func (h *handler) shouldRun(ctx context.Context) bool {
+	if h.leaderElector != nil && !h.leaderElector.IsLeader(ctx) {
+		return false
+	}
+	return true
+}
+
+// skip records that the handler was skipped by the dispatch. Skipped handlers are counted by the results of
+// synchronous dispatches, sent as ErrSkipped to the results of asynchronous dispatches and passed to the dispatch's
+// callback, if any.
+func (e *Event) skip(d *dispatchState, h *handler, results *HandlersResults, wg *sync.WaitGroup,
+	errorsCh chan<- error) {
+	if d.trackResults && !d.async {
+		results.NumSkipped++
+	}
+	if errorsCh != nil {
+		// Sent in the background since the caller only starts receiving once the dispatch returns
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errorsCh <- ErrSkipped
+		}()
+	}
+	if d.callback != nil {
+		d.callback(HandlerResult{Event: e, Handler: h.fn.Interface(), Name: h.name, Labels: h.labels,
+			Disposition: Skipped, RegisteredAt: h.registeredAt})
+	}
+}
+
+// newHandler validates the Handler against the Event's data type and dependency providers
+func (e *Event) newHandler(h Handler) (*handler, error) {
+	var direct func(context.Context, interface{}) error
+	if dh, ok := h.(directHandler); ok {
+		h, direct = dh.handler, dh.call
+	}
+	var cH *handler
+	if factory, ok := h.(lazyFactory); ok {
+		cH = e.newLazyHandler(factory)
+	} else {
+		var err error
+		if cH, err = e.convertHandler(h); err != nil {
+			return nil, err
+		}
+	}
+	cH.direct = direct
+	cH.registeredAt = registrationSite()
+	if usageAccounting {
+		cH.usage = &handlerUsage{}
+	}
+	cH.addedAt = time.Now()
+	cH.healther, _ = h.(Healther)
+	cH.warmer, _ = h.(Warmer)
+	cH.snapshotter, _ = h.(Snapshotter)
+	return cH, nil
+}
+
+// convertHandler converts the Handler to a handler. Named function types are accepted so that handlers may
+// implement optional interfaces such as Healther and Warmer.
+func (e *Event) convertHandler(h Handler) (*handler, error) {
+	if h == nil {
+		return nil, TypeError{errors.New("Handler must not be nil")}
+	}
+	hV := reflect.ValueOf(h)
+	hT := hV.Type()
+	if hT.Kind() == reflect.Func && (hT.ConvertibleTo(e.handlerType) || hT.ConvertibleTo(e.outcomeHandlerType)) {
+		return &handler{fn: hV}, nil
+	}
+	incorrectTypeErr := TypeError{fmt.Errorf("Handler uses incorrect data type. Expected: %s Got: %s",
+		e.handlerType.String(), hT.String())}
+	if len(e.providers) == 0 || hT.Kind() != reflect.Func || hT.IsVariadic() || hT.NumIn() <= 2 ||
+		hT.In(0) != ctxType || hT.In(hT.NumIn()-1) != e.dataType {
+		return nil, incorrectTypeErr
+	}
+	if !(hT.NumOut() == 1 && hT.Out(0) == errType) &&
+		!(hT.NumOut() == 2 && hT.Out(0) == outcomeType && hT.Out(1) == errType) {
+		return nil, incorrectTypeErr
+	}
+	deps := make([]reflect.Type, 0, hT.NumIn()-2)
+	for i := 1; i < hT.NumIn()-1; i++ {
+		dep := hT.In(i)
+		if _, ok := e.providers[dep]; !ok {
+			return nil, TypeError{fmt.Errorf("Handler requires dependency with no provider: %s", dep.String())}
+		}
+		deps = append(deps, dep)
+	}
+	return &handler{fn: hV, deps: deps}, nil
+}
+
+// call calls the handler with the context.Context and event data arguments, injecting any dependencies
+func (h *handler) call(providers map[reflect.Type]provider, args []reflect.Value) []reflect.Value {
+	if p := h.projection; p != nil {
+		return p.target.call(p.providers, []reflect.Value{args[0], reflect.ValueOf(p.project(args[1].Interface()))})
+	}
+	if h.lazy != nil {
+		target, err := h.lazy.get()
+		if err != nil {
+			return errorResults(err)
+		}
+		return target.call(providers, args)
+	}
+	if h.direct != nil {
+		ctx, _ := args[0].Interface().(context.Context)
+		if err := h.direct(ctx, args[1].Interface()); err != nil {
+			return errorResults(err)
+		}
+		return nilErrorResults
+	}
+	if len(h.deps) == 0 {
+		return h.fn.Call(args)
+	}
+	ctx := args[0].Interface().(context.Context)
+	injectedArgs := make([]reflect.Value, 0, len(h.deps)+2)
+	injectedArgs = append(injectedArgs, args[0])
+	for _, dep := range h.deps {
+		v, err := providers[dep](ctx)
+		if err != nil {
+			return errorResults(err)
+		}
+		injectedArgs = append(injectedArgs, v)
+	}
+	injectedArgs = append(injectedArgs, args[1])
+	return h.fn.Call(injectedArgs)
+}
+
+// Outcome may be returned by a handler along with an error to control the dispatch. e.g.
+//
+//	func(ctx context.Context, data interface{}) (Outcome, error)
+type Outcome struct {
+	// Handled signals that the handler consumed the event. Same as returning Handled.
+	Handled bool
+	// SkipChildren skips dispatching the sub-Events. Only affects synchronous dispatches.
+	SkipChildren bool
+	// Retryable signals that the returned error is transient and the handler may be retried
+	Retryable bool
+	// StopPropagation stops the dispatch. Same as returning ErrStopPropagation.
+	StopPropagation bool
+	// Skipped signals that the handler didn't handle the event, e.g. since it was filtered out, so that it's counted
+	// as skipped instead of succeeded. Ignored if an error is returned.
+	Skipped bool
+}
+
+// errorResults creates handler results which only return the error
+func errorResults(err error) []reflect.Value {
+	return []reflect.Value{reflect.ValueOf(&err).Elem()}
+}
+
+// provider provides a dependency injected into handlers
+type provider func(context.Context) (reflect.Value, error)
+
+// WithProvider configures the Event to inject a dependency into handlers which take additional parameters between
+// the context.Context and the event data parameters. e.g.
+//
+//	func(ctx context.Context, db *sql.DB, data OrderCreated) error
+//
+// provider is either a function with the signature func(context.Context) (T, error), which is called to provide
+// the dependency of type T every time a handler requiring it is run, or a value of type T which is always
+// provided as is. An error returned by the provider function is returned as the handler's error.
+// Providers must be configured before adding handlers which depend on them.
+func WithProvider(p interface{}) Option {
+	return func(e *Event) error {
+		if p == nil {
+			return TypeError{errors.New("Provider must not be nil")}
+		}
+		pV := reflect.ValueOf(p)
+		pT := pV.Type()
+		depType := pT
+		prov := func(context.Context) (reflect.Value, error) { return pV, nil }
+		if pT.Kind() == reflect.Func && pT.NumIn() == 1 && pT.In(0) == ctxType && pT.NumOut() == 2 &&
+			pT.Out(1) == errType {
+			depType = pT.Out(0)
+			prov = func(ctx context.Context) (reflect.Value, error) {
+				res := pV.Call([]reflect.Value{reflect.ValueOf(ctx)})
+				if err, _ := res[1].Interface().(error); err != nil {
+					return reflect.Value{}, err
+				}
+				return res[0], nil
+			}
+		}
+		if depType == ctxType || depType == e.dataType {
+			return TypeError{fmt.Errorf("Unable to provide dependency with type: %s", depType.String())}
+		}
+		if e.providers == nil {
+			e.providers = map[reflect.Type]provider{}
+		}
+		e.providers[depType] = prov
+		return nil
+	}
+}
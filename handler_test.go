@@ -0,0 +1,349 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+type testDB struct{ name string }
+type testClock struct{ now int }
+
+func TestWithProvider(t *testing.T) {
+	db := &testDB{name: "db"}
+	var gotDB *testDB
+	var gotClock testClock
+	var gotData int
+	handler := func(ctx context.Context, db *testDB, clock testClock, i int) error { // nolint: unparam
+		gotDB, gotClock, gotData = db, clock, i
+		return nil
+	}
+	e, err := thevent.New(0, thevent.WithProvider(db),
+		thevent.WithProvider(func(ctx context.Context) (testClock, error) { return testClock{now: 42}, nil }),
+		handler, intHandler)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	res, err := e.DispatchWithResults(context.Background(), 5)
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if res.NumHandlers != 2 || res.Erred() {
+		t.Error("Got unexpected results:", res)
+	}
+	if gotDB != db || gotClock.now != 42 || gotData != 5 {
+		t.Error("Handler got unexpected arguments:", gotDB, gotClock, gotData)
+	}
+}
+
+func TestWithProviderErrors(t *testing.T) {
+	depHandler := func(ctx context.Context, db *testDB, i int) error { return nil }
+	testCases := []struct {
+		name      string
+		handlers  []thevent.Handler
+		errorGlob string
+	}{
+		{name: "nil provider", handlers: []thevent.Handler{thevent.WithProvider(nil)},
+			errorGlob: "Provider must not be nil"},
+		{name: "context provider", handlers: []thevent.Handler{thevent.WithProvider(
+			func(ctx context.Context) (context.Context, error) { return ctx, nil })},
+			errorGlob: "Unable to provide dependency with type: context.Context"},
+		{name: "event data provider", handlers: []thevent.Handler{thevent.WithProvider(5)},
+			errorGlob: "Unable to provide dependency with type: int"},
+		{name: "missing provider", handlers: []thevent.Handler{thevent.WithProvider(testClock{}), depHandler},
+			errorGlob: "Handler requires dependency with no provider: *thevent_test.testDB"},
+		{name: "no providers", handlers: []thevent.Handler{depHandler},
+			errorGlob: "Handler uses incorrect data type. Expected: * Got: *"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := thevent.New(0, tc.handlers...)
+			errorMatchesGlob(t, err, tc.errorGlob)
+		})
+	}
+
+	t.Run("provider error", func(t *testing.T) {
+		called := false
+		handler := func(ctx context.Context, db *testDB, i int) error { // nolint: unparam
+			called = true
+			return nil
+		}
+		e, err := thevent.New(0, handler, thevent.WithProvider(func(ctx context.Context) (*testDB, error) {
+			return nil, errors.New("unable to connect")
+		}))
+		if err != nil {
+			t.Fatal("Unable to create event:", err)
+		}
+		res, err := e.DispatchWithResults(context.Background(), 5)
+		if err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+		if called {
+			t.Error("Handler shouldn't be called when the provider fails")
+		}
+		if len(res.Errors) != 1 || res.Errors[0].Error() != "unable to connect" {
+			t.Error("Expected the provider error, instead have errors:", res.Errors)
+		}
+	})
+}
+
+func TestOutcome(t *testing.T) {
+	var called []string
+	testCases := []struct {
+		name           string
+		outcome        thevent.Outcome
+		err            error
+		expectedCalled []string
+		expectedErrors int
+	}{
+		{name: "empty outcome", expectedCalled: []string{"outcome", "last", "child"}},
+		{name: "empty outcome - error", err: errors.New("failed"),
+			expectedCalled: []string{"outcome", "last", "child"}, expectedErrors: 1},
+		{name: "handled", outcome: thevent.Outcome{Handled: true}, expectedCalled: []string{"outcome", "child"}},
+		{name: "skip children", outcome: thevent.Outcome{SkipChildren: true},
+			expectedCalled: []string{"outcome", "last"}},
+		{name: "handled and skip children", outcome: thevent.Outcome{Handled: true, SkipChildren: true},
+			expectedCalled: []string{"outcome"}},
+		{name: "retryable", outcome: thevent.Outcome{Retryable: true}, err: errors.New("failed"),
+			expectedCalled: []string{"outcome", "last", "child"}, expectedErrors: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			outcomeHandler := func(ctx context.Context, s testStruct) (thevent.Outcome, error) {
+				called = append(called, "outcome")
+				return tc.outcome, tc.err
+			}
+			last := func(ctx context.Context, s testStruct) error { // nolint: unparam
+				called = append(called, "last")
+				return nil
+			}
+			child := func(ctx context.Context, s testStruct) error { // nolint: unparam
+				called = append(called, "child")
+				return nil
+			}
+			e, err := thevent.New(testStruct{}, outcomeHandler, last, thevent.StopOnHandled())
+			if err != nil {
+				t.Fatal("Unable to create event:", err)
+			}
+			if _, err := e.New(testStruct{}, "", child); err != nil {
+				t.Fatal("Unable to create sub-Event:", err)
+			}
+
+			called = nil
+			res, err := e.DispatchWithResults(context.Background(), testStruct{})
+			if err != nil {
+				t.Fatal("Unexpected error dispatching:", err)
+			}
+			if len(res.Errors) != tc.expectedErrors {
+				t.Error("Expected", tc.expectedErrors, "errors, instead have errors:", res.Errors)
+			}
+			if len(called) != len(tc.expectedCalled) {
+				t.Fatal("Called handlers:", called, "expected:", tc.expectedCalled)
+			}
+			for i := range called {
+				if called[i] != tc.expectedCalled[i] {
+					t.Fatal("Called handlers:", called, "expected:", tc.expectedCalled)
+				}
+			}
+		})
+	}
+}
+
+type testElector struct{ leader bool }
+
+func (e *testElector) IsLeader(context.Context) bool { return e.leader }
+
+func TestWithLeaderOnly(t *testing.T) {
+	e, err := thevent.New(0)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	err = e.AddHandlerWithOptions(intHandler, thevent.WithLeaderOnly(nil))
+	errorMatchesGlob(t, err, "Elector must not be nil")
+
+	elector := &testElector{}
+	called := 0
+	if err := e.AddHandlerWithOptions(func(ctx context.Context, i int) error {
+		called++
+		return nil
+	}, thevent.WithLeaderOnly(elector)); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+
+	ctx := context.Background()
+	if err := e.Dispatch(ctx, 1); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if called != 0 {
+		t.Error("Handler shouldn't be called when not the leader")
+	}
+	elector.leader = true
+	if err := e.Dispatch(ctx, 1); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if called != 1 {
+		t.Error("Handler should be called when the leader")
+	}
+}
+
+func TestSkippedHandlers(t *testing.T) {
+	e, err := thevent.New(0, func(ctx context.Context, i int) (thevent.Outcome, error) {
+		// Only odd numbers are handled
+		return thevent.Outcome{Skipped: i%2 == 0}, nil
+	}, func(ctx context.Context, i int) error {
+		return errors.New("failed")
+	})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if err := e.AddHandlerWithOptions(intHandler, thevent.WithLeaderOnly(&testElector{})); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+
+	ctx := context.Background()
+	res, err := e.DispatchWithResults(ctx, 2)
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if res.NumHandlers != 1 || res.NumSkipped != 2 || res.ErrorRate() != 1 {
+		t.Error("Unexpected results:", res)
+	}
+	res, err = e.DispatchWithResults(ctx, 1)
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if res.NumHandlers != 2 || res.NumSkipped != 1 || res.ErrorRate() != 0.5 {
+		t.Error("Unexpected results:", res)
+	}
+
+	var dispositions []thevent.Disposition
+	var lock sync.Mutex
+	done := make(chan struct{})
+	if err := e.DispatchAsyncWithCallback(ctx, 2, func(hr thevent.HandlerResult) {
+		lock.Lock()
+		defer lock.Unlock()
+		dispositions = append(dispositions, hr.Disposition)
+	}, func() { close(done) }); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	<-done
+	skipped := 0
+	for _, d := range dispositions {
+		if d == thevent.Skipped {
+			skipped++
+		}
+	}
+	if len(dispositions) != 3 || skipped != 2 {
+		t.Error("Unexpected dispositions:", dispositions)
+	}
+}
+
+func TestSkippedHandlersAsync(t *testing.T) {
+	for _, sequential := range []bool{false, true} {
+		t.Run(fmt.Sprint("sequential=", sequential), func(t *testing.T) {
+			var opts []thevent.BusOption
+			if sequential {
+				opts = append(opts, thevent.Sequential())
+			}
+			e, err := thevent.NewBus(opts...).New("a", 0, func(ctx context.Context, i int) (thevent.Outcome, error) {
+				return thevent.Outcome{Skipped: i%2 == 0}, nil
+			}, func(ctx context.Context, i int) error {
+				return errors.New("failed")
+			})
+			if err != nil {
+				t.Fatal("Unable to create event:", err)
+			}
+			if err := e.AddHandlerWithOptions(intHandler, thevent.WithLeaderOnly(&testElector{})); err != nil {
+				t.Fatal("Unable to add handler:", err)
+			}
+
+			ch, err := e.DispatchAsyncWithResults(context.Background(), 2)
+			if err != nil {
+				t.Fatal("Unexpected error dispatching:", err)
+			}
+			res := thevent.HandlersResults{}
+			res.Collect(ch)
+			if res.NumHandlers != 1 || res.NumSkipped != 2 || res.ErrorRate() != 1 {
+				t.Error("Unexpected results:", res)
+			}
+		})
+	}
+}
+
+func TestWithSerialized(t *testing.T) {
+	var lock sync.Mutex
+	running, maxRunning := 0, 0
+	handler := func(ctx context.Context, i int) error { // nolint: unparam
+		lock.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+		time.Sleep(time.Millisecond)
+		lock.Lock()
+		running--
+		lock.Unlock()
+		return nil
+	}
+	e := thevent.Must(thevent.New(0))
+	if err := e.AddHandlerWithOptions(handler, thevent.WithSerialized()); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		if err := e.DispatchAsync(ctx, i); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	}
+	if err := e.Barrier(ctx); err != nil {
+		t.Fatal("Unexpected error waiting for handlers:", err)
+	}
+	if maxRunning != 1 {
+		t.Error("Serialized handler ran concurrently:", maxRunning)
+	}
+}
+
+func TestWithOrderedDelivery(t *testing.T) {
+	var lock sync.Mutex
+	var got []int
+	handler := func(ctx context.Context, i int) error { // nolint: unparam
+		// Later dispatches finish sooner, so unordered deliveries would be reordered
+		time.Sleep(time.Duration(10-i) * time.Millisecond)
+		lock.Lock()
+		defer lock.Unlock()
+		got = append(got, i)
+		return nil
+	}
+	e := thevent.Must(thevent.New(0))
+	if err := e.AddHandlerWithOptions(handler, thevent.WithOrderedDelivery()); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		if err := e.DispatchAsync(ctx, i); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	}
+	if err := e.Dispatch(ctx, 5); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if err := e.Barrier(ctx); err != nil {
+		t.Fatal("Unexpected error waiting for handlers:", err)
+	}
+	for i := range got {
+		if got[i] != i {
+			t.Fatal("Deliveries out of order:", got)
+		}
+	}
+	if len(got) != 6 {
+		t.Error("Unexpected deliveries:", got)
+	}
+}
@@ -0,0 +1,64 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// HandlerFunc is a type-safe handler of event data of type T. Wrappers such as middleware, filters and retries may
+// be composed around HandlerFuncs with compile-time type checks, e.g. using Wrap(), and the result added to a
+// TypedEvent, or to an Event using AsHandler().
+type HandlerFunc[T any] func(ctx context.Context, data T) error
+
+// Handle calls the HandlerFunc
+func (f HandlerFunc[T]) Handle(ctx context.Context, data T) error {
+	return f(ctx, data)
+}
+
+// HandlerWrapper wraps a HandlerFunc with additional behavior, e.g. logging or filtering. See Wrap()
+type HandlerWrapper[T any] func(next HandlerFunc[T]) HandlerFunc[T]
+
+// Wrap wraps the HandlerFunc with the wrappers. The first wrapper is the outermost, so it's called first.
+func Wrap[T any](f HandlerFunc[T], wrappers ...HandlerWrapper[T]) HandlerFunc[T] {
+	for i := len(wrappers) - 1; i >= 0; i-- {
+		f = wrappers[i](f)
+	}
+	return f
+}
+
+// AsHandler adapts the HandlerFunc to a Handler which may be added to an Event with data of type T. The HandlerFunc
+// is called without reflection, like the handlers of a TypedEvent.
+func AsHandler[T any](f HandlerFunc[T]) Handler {
+	if f == nil {
+		return nil
+	}
+	return toHandlers([]func(context.Context, T) error{f})[0]
+}
+
+// HandlerFuncOf adapts a Handler accepted by Event.AddHandlers() for data of type T to a HandlerFunc, so that it may
+// be wrapped with type safety. Handlers returning an Outcome are adapted to only return the error. Handlers with
+// dependencies injected using WithProvider() aren't supported.
+func HandlerFuncOf[T any](h Handler) (HandlerFunc[T], error) {
+	switch f := h.(type) {
+	case nil:
+		return nil, TypeError{errors.New("Handler must not be nil")}
+	case HandlerFunc[T]:
+		return f, nil
+	case func(context.Context, T) error:
+		return f, nil
+	case func(context.Context, T) (Outcome, error):
+		return func(ctx context.Context, data T) error {
+			_, err := f(ctx, data)
+			return err
+		}, nil
+	}
+	fT := reflect.TypeOf((func(context.Context, T) error)(nil))
+	hV := reflect.ValueOf(h)
+	if !hV.Type().ConvertibleTo(fT) {
+		return nil, TypeError{fmt.Errorf("Handler uses incorrect data type. Expected: %s Got: %s", fT.String(),
+			hV.Type().String())}
+	}
+	return hV.Convert(fT).Interface().(func(context.Context, T) error), nil
+}
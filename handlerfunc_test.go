@@ -0,0 +1,136 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+type namedOrderHandler func(context.Context, order) error
+
+func TestWrap(t *testing.T) {
+	var calls []string
+	record := func(name string) thevent.HandlerWrapper[order] {
+		return func(next thevent.HandlerFunc[order]) thevent.HandlerFunc[order] {
+			return func(ctx context.Context, o order) error {
+				calls = append(calls, name)
+				return next(ctx, o)
+			}
+		}
+	}
+	onlyLarge := func(next thevent.HandlerFunc[order]) thevent.HandlerFunc[order] {
+		return func(ctx context.Context, o order) error {
+			if o.Total < 100 {
+				return nil
+			}
+			return next(ctx, o)
+		}
+	}
+	h := thevent.Wrap(func(ctx context.Context, o order) error {
+		calls = append(calls, "handler")
+		return nil
+	}, record("outer"), onlyLarge, record("inner"))
+
+	e, err := thevent.NewTyped[order](h)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	ctx := context.Background()
+	for _, total := range []int{10, 200} {
+		if err := e.Dispatch(ctx, order{Total: total}); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	}
+	if len(calls) != 4 || calls[0] != "outer" || calls[1] != "outer" || calls[2] != "inner" ||
+		calls[3] != "handler" {
+		t.Error("Wrappers called in unexpected order:", calls)
+	}
+}
+
+func TestAsHandler(t *testing.T) {
+	var got order
+	h := thevent.HandlerFunc[order](func(ctx context.Context, o order) error { // nolint: unparam
+		got = o
+		return nil
+	})
+	e, err := thevent.New(order{}, thevent.AsHandler(h))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if err := e.Dispatch(context.Background(), order{ID: 1}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if got.ID != 1 {
+		t.Error("Handler got unexpected data:", got)
+	}
+	_, err = thevent.New(0, thevent.AsHandler(h))
+	errorMatchesGlob(t, err, "Handler uses incorrect data type.*")
+	if thevent.AsHandler[order](nil) != nil {
+		t.Error("Expected nil Handler for nil HandlerFunc")
+	}
+}
+
+func TestAsHandlerDispatchPointerAllocs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping allocation test in short mode")
+	}
+	h := thevent.HandlerFunc[*order](func(ctx context.Context, o *order) error { // nolint: unparam
+		o.Total++
+		return nil
+	})
+	e, err := thevent.New(&order{}, thevent.AsHandler(h))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	ctx := context.Background()
+	o := &order{ID: 1}
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := e.Dispatch(ctx, o); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	})
+	if allocs != 0 {
+		t.Error("Expected 0 allocations per dispatch, got:", allocs)
+	}
+}
+
+func TestHandlerFuncOf(t *testing.T) {
+	failing := errors.New("failed")
+	testCases := []struct {
+		name        string
+		handler     thevent.Handler
+		expectedErr string
+	}{
+		{name: "func", handler: func(ctx context.Context, o order) error { return failing }},
+		{name: "HandlerFunc", handler: thevent.HandlerFunc[order](func(ctx context.Context, o order) error {
+			return failing
+		})},
+		{name: "outcome", handler: func(ctx context.Context, o order) (thevent.Outcome, error) {
+			return thevent.Outcome{Handled: true}, failing
+		}},
+		{name: "named", handler: namedOrderHandler(func(ctx context.Context, o order) error { return failing })},
+		{name: "nil", expectedErr: "Handler must not be nil"},
+		{name: "incorrect data type", handler: func(ctx context.Context, i int) error { return nil },
+			expectedErr: "Handler uses incorrect data type. Expected: func(context.Context, thevent_test.order) " +
+				"error Got: func(context.Context, int) error"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := thevent.HandlerFuncOf[order](tc.handler)
+			if tc.expectedErr != "" {
+				errorMatchesGlob(t, err, tc.expectedErr)
+				return
+			}
+			if err != nil {
+				t.Fatal("Unable to adapt handler:", err)
+			}
+			if err := f.Handle(context.Background(), order{}); err != failing {
+				t.Error("Adapted handler returned unexpected error:", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package thevent
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// HandlerIdentity identifies a handler to detect duplicate handlers. Handlers with equal identities are duplicates.
+// The identity must be comparable, e.g. a string. If nil is returned, the handler's default identity, its function,
+// is used. See WithHandlerIdentity()
+type HandlerIdentity func(HandlerInfo) interface{}
+
+// identityKey distinguishes the identities returned by a HandlerIdentity from the default identities of handlers
+type identityKey struct {
+	id interface{}
+}
+
+// WithHandlerIdentity configures the Event to identify its handlers using the HandlerIdentity instead of by their
+// functions, e.g. so that wrapped handlers, which are distinct functions, are deduplicated by a logical key such as
+// their name, or so that the same function may be added multiple times with different names. Subscriptions are
+// always distinct.
+func WithHandlerIdentity(identity HandlerIdentity) Option {
+	return func(e *Event) error {
+		if identity == nil {
+			return TypeError{errors.New("HandlerIdentity must not be nil")}
+		}
+		e.identity = identity
+		return nil
+	}
+}
+
+// AllowDuplicates configures the Event to allow adding the same handler multiple times, e.g. for method values or
+// generated adapters sharing a function. Every added handler is distinct, and is run as many times as it has been
+// added. RemoveHandlers() removes a single one of the duplicates for every given handler.
+func AllowDuplicates() Option {
+	return func(e *Event) error {
+		e.allowDuplicates = true
+		return nil
+	}
+}
+
+// identify sets the handler's identity using the Event's HandlerIdentity, if any. Handlers of Events allowing
+// duplicates are identified by themselves.
+func (e *Event) identify(h *handler) error {
+	if e.allowDuplicates {
+		h.id = h
+		return nil
+	}
+	if e.identity == nil || h.subscription != nil {
+		return nil
+	}
+	id := e.identity(h.info())
+	if id == nil {
+		return nil
+	}
+	if !reflect.TypeOf(id).Comparable() {
+		return TypeError{fmt.Errorf("Handler identity must be comparable, not: %T", id)}
+	}
+	h.id = identityKey{id: id}
+	return nil
+}
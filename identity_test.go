@@ -0,0 +1,93 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+// logged wraps the handler, creating a distinct function
+func logged(h func(context.Context, int) error) func(context.Context, int) error {
+	return func(ctx context.Context, i int) error { return h(ctx, i) }
+}
+
+func TestWithHandlerIdentity(t *testing.T) {
+	byName := func(info thevent.HandlerInfo) interface{} {
+		if info.Name == "" {
+			return nil
+		}
+		return info.Name
+	}
+	e := thevent.Must(thevent.New(0, thevent.WithHandlerIdentity(byName)))
+	// Wrapped handlers are deduplicated by name
+	if err := e.AddNamedHandler("audit", logged(intHandler)); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	errorMatchesGlob(t, e.AddNamedHandler("audit", logged(intHandler)), "Unable to add duplicate handler")
+	// The same function may be added with different names
+	if err := e.AddNamedHandler("metrics", intHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	if err := e.AddNamedHandler("log", intHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	// Unnamed handlers use their default identity
+	if err := e.AddHandlers(intHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	errorMatchesGlob(t, e.AddHandlers(intHandler), "Unable to add duplicate handler")
+	if n := len(e.Handlers()); n != 4 {
+		t.Error("Expected 4 handlers, got:", n)
+	}
+
+	uncomparable := thevent.Must(thevent.New(0, thevent.WithHandlerIdentity(func(thevent.HandlerInfo) interface{} {
+		return []string{}
+	})))
+	errorMatchesGlob(t, uncomparable.AddHandlers(intHandler), "Handler identity must be comparable, not: \\[]string")
+	_, err := thevent.New(0, thevent.WithHandlerIdentity(nil))
+	errorMatchesGlob(t, err, "HandlerIdentity must not be nil")
+}
+
+func TestWithHandlerIdentityBatch(t *testing.T) {
+	constant := func(thevent.HandlerInfo) interface{} { return "same" }
+	_, err := thevent.New(0, thevent.WithHandlerIdentity(constant), intHandler, testIntHandler2)
+	errorMatchesGlob(t, err, "Unable to add duplicate handler")
+	e := thevent.Must(thevent.New(0, thevent.WithHandlerIdentity(constant)))
+	errorMatchesGlob(t, thevent.CheckHandler(e, intHandler), "")
+	if err := e.AddHandlers(intHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	errorMatchesGlob(t, thevent.CheckHandler(e, testIntHandler2), "Unable to add duplicate handler")
+}
+
+func testIntHandler2(context.Context, int) error { return nil }
+
+func TestAllowDuplicates(t *testing.T) {
+	calls := 0
+	handler := func(ctx context.Context, i int) error { // nolint: unparam
+		calls++
+		return nil
+	}
+	e := thevent.Must(thevent.New(0, thevent.AllowDuplicates(), handler, handler))
+	if err := e.AddHandlers(handler); err != nil {
+		t.Fatal("Unable to add duplicate handler:", err)
+	}
+	if err := e.Dispatch(context.Background(), 1); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if calls != 3 {
+		t.Error("Expected the handler to be called 3 times, got:", calls)
+	}
+	if err := e.RemoveHandlers(handler); err != nil {
+		t.Fatal("Unable to remove handler:", err)
+	}
+	if n := len(e.Handlers()); n != 2 {
+		t.Error("Expected a single duplicate to be removed, got handlers:", n)
+	}
+
+	_, err := thevent.New(0, handler, handler)
+	errorMatchesGlob(t, err, "Unable to add duplicate handler")
+}
@@ -0,0 +1,192 @@
+package thevent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ImportProgress is the progress of an import. See ImportJSONL() and ImportCSV()
+type ImportProgress struct {
+	// Records is the number of records read so far
+	Records int
+	// Failed is the number of records whose dispatch had a failing handler
+	Failed  int
+	Elapsed time.Duration
+}
+
+// ImportOption configures an import. See ImportJSONL() and ImportCSV()
+type ImportOption func(*importer) error
+
+// importer is the configuration of an import
+type importer struct {
+	interval time.Duration
+	every    int
+	progress func(ImportProgress)
+}
+
+// WithImportRate limits the import to dispatching perSecond records per second, e.g. so a backfill doesn't overload
+// the handlers' dependencies
+func WithImportRate(perSecond float64) ImportOption {
+	return func(i *importer) error {
+		if perSecond <= 0 {
+			return TypeError{errors.New("Import rate must be positive")}
+		}
+		i.interval = time.Duration(float64(time.Second) / perSecond)
+		return nil
+	}
+}
+
+// WithImportProgress reports the progress of the import after every n records and once the import is done
+func WithImportProgress(n int, report func(ImportProgress)) ImportOption {
+	return func(i *importer) error {
+		if n < 1 {
+			return TypeError{errors.New("Import progress must be reported after at least 1 record")}
+		}
+		if report == nil {
+			return TypeError{errors.New("Import progress report must not be nil")}
+		}
+		i.every, i.progress = n, report
+		return nil
+	}
+}
+
+// ImportJSONL imports historical records from JSON lines, e.g. to backfill a new projection. Every record is mapped
+// to the Event's data using mapRecord and synchronously dispatched to the Event and its sub-Events. Records are
+// decoded into the Event's data type if mapRecord is nil. Blank lines are skipped. Records whose handlers fail are
+// counted as failed and the import continues. Mapping and dispatch errors stop the import. The progress of the
+// import is returned.
+func ImportJSONL(ctx context.Context, r io.Reader, e *Event, mapRecord func(json.RawMessage) (interface{}, error),
+	opts ...ImportOption) (ImportProgress, error) {
+	if mapRecord == nil {
+		mapRecord = func(record json.RawMessage) (interface{}, error) { return e.decode(record) }
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	line := 0
+	return e.importRecords(ctx, opts, func() (interface{}, error) {
+		for scanner.Scan() {
+			line++
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			// The scanner reuses its buffer, so the record is copied
+			data, err := mapRecord(append(json.RawMessage(nil), scanner.Bytes()...))
+			if err != nil {
+				return nil, fmt.Errorf("Unable to map record on line %d: %v", line, err)
+			}
+			return data, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	})
+}
+
+// ImportCSV imports historical records from CSV like ImportJSONL(). The first row is the header naming the columns
+// and every following row is passed to mapRecord keyed by column name.
+func ImportCSV(ctx context.Context, r io.Reader, e *Event, mapRecord func(map[string]string) (interface{}, error),
+	opts ...ImportOption) (ImportProgress, error) {
+	if mapRecord == nil {
+		return ImportProgress{}, TypeError{errors.New("CSV record mapping must not be nil")}
+	}
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return ImportProgress{}, nil
+	} else if err != nil {
+		return ImportProgress{}, fmt.Errorf("Unable to read CSV header: %v", err)
+	}
+	row := 1
+	return e.importRecords(ctx, opts, func() (interface{}, error) {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			return nil, err
+		} else if err != nil {
+			return nil, fmt.Errorf("Unable to read CSV record: %v", err)
+		}
+		row++
+		record := make(map[string]string, len(header))
+		for i, column := range header {
+			record[column] = fields[i]
+		}
+		data, err := mapRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to map record on row %d: %v", row, err)
+		}
+		return data, nil
+	})
+}
+
+// importRecords dispatches the records returned by next to the Event until next returns io.EOF
+func (e *Event) importRecords(ctx context.Context, opts []ImportOption,
+	next func() (interface{}, error)) (ImportProgress, error) {
+	i := &importer{}
+	for _, opt := range opts {
+		if err := opt(i); err != nil {
+			return ImportProgress{}, err
+		}
+	}
+	start := time.Now()
+	var progress ImportProgress
+	report := func() {
+		if i.progress != nil {
+			progress.Elapsed = time.Since(start)
+			i.progress(progress)
+		}
+	}
+	for {
+		data, err := next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return progress, err
+		}
+		if err := i.wait(ctx, start, progress.Records); err != nil {
+			return progress, err
+		}
+		res, err := e.DispatchWithResults(ctx, data)
+		if err != nil {
+			return progress, fmt.Errorf("Unable to dispatch record %d: %v", progress.Records+1, err)
+		}
+		progress.Records++
+		if len(res.Errors) > 0 {
+			progress.Failed++
+		}
+		res.Release()
+		if i.every > 0 && progress.Records%i.every == 0 {
+			report()
+		}
+	}
+	progress.Elapsed = time.Since(start)
+	if i.every > 0 && progress.Records%i.every != 0 {
+		report()
+	}
+	return progress, nil
+}
+
+// wait waits until the record with the given index may be dispatched at the import's rate
+func (i *importer) wait(ctx context.Context, start time.Time, index int) error {
+	if i.interval <= 0 {
+		return ctx.Err()
+	}
+	d := time.Until(start.Add(time.Duration(index) * i.interval))
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,86 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestImportJSONL(t *testing.T) {
+	var users []fixtureUser
+	var logins []fixtureLogin
+	e, err := thevent.New(fixtureUser{}, func(ctx context.Context, u fixtureUser) error {
+		users = append(users, u)
+		if u.Name == "" {
+			return errors.New("missing name")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if _, err := e.New(fixtureLogin{}, "User", func(ctx context.Context, l fixtureLogin) error { // nolint: unparam
+		logins = append(logins, l)
+		return nil
+	}); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+
+	var reports []thevent.ImportProgress
+	input := "{\"ID\": 1, \"Name\": \"a\"}\n\n{\"ID\": 2}\n{\"ID\": 3, \"Name\": \"c\"}\n"
+	progress, err := thevent.ImportJSONL(context.Background(), strings.NewReader(input), e, nil,
+		thevent.WithImportProgress(2, func(p thevent.ImportProgress) { reports = append(reports, p) }),
+		thevent.WithImportRate(1000))
+	if err != nil {
+		t.Fatal("Unexpected error importing:", err)
+	}
+	if progress.Records != 3 || progress.Failed != 1 {
+		t.Error("Unexpected import progress:", progress)
+	}
+	if len(users) != 3 || len(logins) != 3 || users[2].Name != "c" || logins[2].User.ID != 3 {
+		t.Error("Unexpected imported users:", users, "logins:", logins)
+	}
+	if len(reports) != 2 || reports[0].Records != 2 || reports[1].Records != 3 {
+		t.Error("Unexpected progress reports:", reports)
+	}
+
+	_, err = thevent.ImportJSONL(context.Background(), strings.NewReader("{}\nnot json\n"), e, nil)
+	errorMatchesGlob(t, err, "Unable to map record on line 2: *")
+}
+
+func TestImportCSV(t *testing.T) {
+	var users []fixtureUser
+	e, err := thevent.New(fixtureUser{}, func(ctx context.Context, u fixtureUser) error { // nolint: unparam
+		users = append(users, u)
+		return nil
+	})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	mapUser := func(record map[string]string) (interface{}, error) {
+		id, err := strconv.Atoi(record["id"])
+		if err != nil {
+			return nil, err
+		}
+		return fixtureUser{ID: id, Name: record["name"]}, nil
+	}
+
+	progress, err := thevent.ImportCSV(context.Background(), strings.NewReader("name,id\na,1\nb,2\n"), e, mapUser)
+	if err != nil {
+		t.Fatal("Unexpected error importing:", err)
+	}
+	if progress.Records != 2 || len(users) != 2 || users[1] != (fixtureUser{ID: 2, Name: "b"}) {
+		t.Error("Unexpected import progress:", progress, "users:", users)
+	}
+
+	_, err = thevent.ImportCSV(context.Background(), strings.NewReader("name,id\na,x\n"), e, mapUser)
+	errorMatchesGlob(t, err, "Unable to map record on row 2: *")
+	_, err = thevent.ImportCSV(context.Background(), strings.NewReader("name,id\n"), e, nil)
+	errorMatchesGlob(t, err, "CSV record mapping must not be nil")
+}
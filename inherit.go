@@ -0,0 +1,36 @@
+package thevent
+
+import (
+	"errors"
+)
+
+// Inherited applies the Options to the Event and to the sub-Events created from it using Event.New(),
+// Event.NewVersion() or Sub(), including their own sub-Events, so cross-cutting concerns such as middleware needn't be
+// configured on every Event of a hierarchy. The inherited Options are applied to a sub-Event before the Options
+// passed when creating it, so that the sub-Event's own Options override them, e.g. WithoutMiddleware() drops the
+// inherited middleware.
+func Inherited(opts ...Option) Option {
+	return func(e *Event) error {
+		for _, opt := range opts {
+			if opt == nil {
+				return TypeError{errors.New("Option must not be nil")}
+			}
+			if err := opt(e); err != nil {
+				return err
+			}
+		}
+		e.inherited = append(e.inherited, opts...)
+		return nil
+	}
+}
+
+// inherit prepends the Options inherited by the Event's sub-Events to the handlers and Options of a new sub-Event
+func (e *Event) inherit(handlers []Handler) []Handler {
+	e.lock.RLock()
+	inherited := e.inherited
+	e.lock.RUnlock()
+	if len(inherited) == 0 {
+		return handlers
+	}
+	return append([]Handler{Inherited(inherited...)}, handlers...)
+}
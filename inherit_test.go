@@ -0,0 +1,197 @@
+package thevent_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestInherited(t *testing.T) {
+	var calls []string
+	mw := func(ctx context.Context, call thevent.HandlerCall, next thevent.Next) (thevent.Outcome, error) {
+		calls = append(calls, call.Name)
+		return next(ctx)
+	}
+	e := thevent.Must(thevent.New(testStruct{}, thevent.Inherited(thevent.WithMiddleware(mw))))
+	if err := e.AddNamedHandler("parent", testStructHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	child, err := e.New(testStruct{}, "")
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if err := child.AddNamedHandler("child", testStructHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	grandchild, err := child.New(testStruct{}, "")
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if err := grandchild.AddNamedHandler("grandchild", testStructHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	// The sub-Event's own Options override the inherited Options
+	overridden, err := e.New(testStruct{}, "", thevent.WithoutMiddleware())
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if err := overridden.AddNamedHandler("overridden", testStructHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+
+	if err := e.Dispatch(context.Background(), testStruct{}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	expected := []string{"parent", "child", "grandchild"}
+	if len(calls) != len(expected) {
+		t.Fatal("Got middleware calls:", calls, "instead of:", expected)
+	}
+	for i := range calls {
+		if calls[i] != expected[i] {
+			t.Fatal("Got middleware calls:", calls, "instead of:", expected)
+		}
+	}
+
+	_, err = thevent.New(0, thevent.Inherited(nil))
+	errorMatchesGlob(t, err, "Option must not be nil")
+}
+
+func TestUse(t *testing.T) {
+	var lock sync.Mutex
+	var calls []string
+	mw := func(ctx context.Context, call thevent.HandlerCall, next thevent.Next) (thevent.Outcome, error) {
+		lock.Lock()
+		calls = append(calls, call.Name)
+		lock.Unlock()
+		return next(ctx)
+	}
+	e := thevent.Must(thevent.New(testStruct{}))
+	if err := e.AddNamedHandler("parent", testStructHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	existing, err := e.New(testStruct{}, "")
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if err := existing.AddNamedHandler("existing", testStructHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	// Sub-Events which opted out of the middleware and their sub-Events don't get the middleware
+	optedOut, err := e.New(testStruct{}, "", thevent.WithoutMiddleware())
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if err := optedOut.AddNamedHandler("optedOut", testStructHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	optedOutChild, err := optedOut.New(testStruct{}, "")
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if err := optedOutChild.AddNamedHandler("optedOutChild", testStructHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+
+	// Handlers may run concurrently with adding middleware
+	ctx := context.Background()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			e.Dispatch(ctx, testStruct{}) // nolint: errcheck
+		}
+	}()
+	if err := e.Use(mw); err != nil {
+		t.Fatal("Unable to use middleware:", err)
+	}
+	<-done
+	later, err := e.New(testStruct{}, "")
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if err := later.AddNamedHandler("later", testStructHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	optedOutLater, err := optedOut.New(testStruct{}, "")
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if err := optedOutLater.AddNamedHandler("optedOutLater", testStructHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+
+	lock.Lock()
+	calls = nil
+	lock.Unlock()
+	if err := e.Dispatch(ctx, testStruct{}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	expected := []string{"parent", "existing", "later"}
+	if len(calls) != len(expected) {
+		t.Fatal("Got middleware calls:", calls, "instead of:", expected)
+	}
+	for i := range calls {
+		if calls[i] != expected[i] {
+			t.Fatal("Got middleware calls:", calls, "instead of:", expected)
+		}
+	}
+
+	errorMatchesGlob(t, e.Use(nil), "Middleware must not be nil")
+}
+
+func TestBusUse(t *testing.T) {
+	var lock sync.Mutex
+	var calls []string
+	record := func(name string) thevent.Middleware {
+		return func(ctx context.Context, call thevent.HandlerCall, next thevent.Next) (thevent.Outcome, error) {
+			lock.Lock()
+			calls = append(calls, name+":"+call.Name)
+			lock.Unlock()
+			return next(ctx)
+		}
+	}
+	b := thevent.NewBus()
+	existing, err := b.New("existing", testStruct{}, thevent.WithMiddleware(record("event")))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if err := existing.AddNamedHandler("a", testStructHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	if err := b.Use(record("bus")); err != nil {
+		t.Fatal("Unable to use middleware:", err)
+	}
+	later, err := b.New("later", testStruct{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	sub, err := later.New(testStruct{}, "")
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if err := sub.AddNamedHandler("b", testStructHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+
+	ctx := context.Background()
+	for _, e := range []*thevent.Event{existing, later} {
+		if err := e.Dispatch(ctx, testStruct{}); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	}
+	expected := []string{"bus:a", "event:a", "bus:b"}
+	if len(calls) != len(expected) {
+		t.Fatal("Got middleware calls:", calls, "instead of:", expected)
+	}
+	for i := range calls {
+		if calls[i] != expected[i] {
+			t.Fatal("Got middleware calls:", calls, "instead of:", expected)
+		}
+	}
+
+	errorMatchesGlob(t, b.Use(nil), "Middleware must not be nil")
+}
@@ -0,0 +1,47 @@
+package thevent
+
+import (
+	"time"
+)
+
+// HandlerInfo describes a handler added to an Event, e.g. to display the handlers listening to an Event
+type HandlerInfo struct {
+	Handler Handler `json:"-"`
+	// Func is the name of the handler's function, if available
+	Func string
+	// Name, Labels, Priority and After are configured when adding the handler. See WithName(), WithLabels(),
+	// AddHandlersWithPriority() and After()
+	Name     string
+	Labels   map[string]string
+	Priority int
+	After    []string
+	// AddedAt is when the handler was added
+	AddedAt time.Time
+	// RegisteredAt is where the handler was registered, in builds with the thevent_debug build tag
+	RegisteredAt string
+	// Shadow is true for shadow handlers. See WithShadow()
+	Shadow bool
+	// Usage is the handler's resource consumption, in builds with the thevent_debug build tag
+	Usage HandlerUsage
+}
+
+// Handlers describes the Event's handlers in the order in which they're run by synchronous dispatches. The handlers
+// of sub-Events aren't included.
+func (e *Event) Handlers() []HandlerInfo {
+	e.lock.RLock()
+	handlers := e.handlers
+	e.lock.RUnlock()
+	infos := make([]HandlerInfo, 0, len(handlers))
+	for _, h := range handlers {
+		infos = append(infos, h.info())
+	}
+	return infos
+}
+
+// info describes the handler
+func (h *handler) info() HandlerInfo {
+	fn := h.fn.Interface()
+	return HandlerInfo{Handler: fn, Func: handlerName(fn), Name: h.name, Labels: h.labels, Priority: h.priority,
+		After: append([]string(nil), h.after...), AddedAt: h.addedAt, RegisteredAt: h.registeredAt,
+		Shadow: h.shadow != nil, Usage: h.usage.snapshot()}
+}
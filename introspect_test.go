@@ -0,0 +1,49 @@
+package thevent_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestHandlers(t *testing.T) {
+	start := time.Now()
+	e := thevent.Must(thevent.New(0, intHandler))
+	if err := e.AddNamedHandler("audit", func(ctx context.Context, i int) error { return nil },
+		thevent.WithLabels(map[string]string{"team": "security"}), thevent.After("notify")); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	if err := e.AddHandlersWithPriority(5, func(ctx context.Context, i int) error { return nil }); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+
+	infos := e.Handlers()
+	if len(infos) != 3 {
+		t.Fatal("Expected 3 handlers, got:", len(infos))
+	}
+	if infos[0].Priority != 5 {
+		t.Error("Expected the prioritized handler first, got priority:", infos[0].Priority)
+	}
+	if !strings.HasSuffix(infos[1].Func, ".intHandler") {
+		t.Error("Got handler func:", infos[1].Func)
+	}
+	audit := infos[2]
+	if audit.Name != "audit" || audit.Labels["team"] != "security" || len(audit.After) != 1 ||
+		audit.After[0] != "notify" {
+		t.Error("Unexpected handler info:", audit)
+	}
+	for _, info := range infos {
+		if info.AddedAt.Before(start) || info.Handler == nil || info.Shadow {
+			t.Error("Unexpected handler info:", info)
+		}
+	}
+	if _, err := json.Marshal(infos); err != nil {
+		t.Error("Unable to marshal handler infos:", err)
+	}
+}
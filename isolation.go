@@ -0,0 +1,66 @@
+package thevent
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+)
+
+// PanicError is the error of a handler which panicked. See Isolated() and RecoverPanics()
+type PanicError struct {
+	// Value is the value the handler panicked with
+	Value interface{}
+	Stack []byte
+	// RegisteredAt is where the handler was registered, in builds with the thevent_debug build tag
+	RegisteredAt string
+}
+
+func (p PanicError) Error() string {
+	if p.RegisteredAt != "" {
+		return fmt.Sprintf("Handler registered at %s panicked: %v", p.RegisteredAt, p.Value)
+	}
+	return fmt.Sprintf("Handler panicked: %v", p.Value)
+}
+
+// Isolated configures the Event to isolate its handlers from each other. Every handler is run with its own
+// context.Context derived from the dispatch's context.Context, which is canceled once the handler returns, so
+// goroutines started by the handler don't outlive it. A panicking handler doesn't affect the other handlers: the
+// panic is recovered, reported using the HandlerPanicked meta-Event and returned as the handler's PanicError.
+func Isolated() Option {
+	return func(e *Event) error {
+		e.isolated = true
+		return nil
+	}
+}
+
+// RecoverPanics configures the Event to recover from panicking handlers, so that a panic doesn't crash the program
+// or the goroutine of an asynchronous dispatch. The panic is reported using the HandlerPanicked meta-Event and
+// returned as the handler's PanicError, which includes the stack trace. Unlike Isolated(), the handlers are run
+// with the dispatch's context.Context. Use Inherited() to also recover the panics of sub-Events' handlers.
+func RecoverPanics() Option {
+	return func(e *Event) error {
+		e.recoverPanics = true
+		return nil
+	}
+}
+
+// callIsolated runs the handler with its own context.Context, converting a panic to a PanicError
+func (e *Event) callIsolated(ctx context.Context, h *handler, args []reflect.Value) []reflect.Value {
+	hCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	return e.callRecovered(ctx, h, []reflect.Value{reflect.ValueOf(hCtx), args[1]})
+}
+
+// callRecovered runs the handler, converting a panic to a PanicError. The panic is reported using ctx.
+func (e *Event) callRecovered(ctx context.Context, h *handler, args []reflect.Value) (res []reflect.Value) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			e.dispatchMeta(ctx, HandlerPanicked, HandlerPanic{Event: e, Handler: h.fn.Interface(), Value: r,
+				Stack: stack, RegisteredAt: h.registeredAt})
+			res = errorResults(PanicError{Value: r, Stack: stack, RegisteredAt: h.registeredAt})
+		}
+	}()
+	return e.invoke(args[0].Interface().(context.Context), h, args)
+}
@@ -0,0 +1,70 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestIsolated(t *testing.T) {
+	var handlerCtx context.Context
+	panicking := func(ctx context.Context, s testStruct) error {
+		handlerCtx = ctx
+		panic("boom")
+	}
+	siblingCalled := false
+	sibling := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		siblingCalled = true
+		return nil
+	}
+	e := thevent.Must(thevent.New(testStruct{}, panicking, sibling, thevent.Isolated()))
+	res, err := e.DispatchWithResults(context.Background(), testStruct{})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if !siblingCalled {
+		t.Error("Sibling handler wasn't called")
+	}
+	if res.NumHandlers != 2 || len(res.Errors) != 1 {
+		t.Fatal("Unexpected results:", res)
+	}
+	pe, ok := res.Errors[0].(thevent.PanicError)
+	if !ok || pe.Value != "boom" || len(pe.Stack) == 0 ||
+		(pe.RegisteredAt == "" && pe.Error() != "Handler panicked: boom") {
+		t.Error("Expected PanicError, got:", res.Errors[0])
+	}
+	if handlerCtx == nil || handlerCtx.Err() != context.Canceled {
+		t.Error("Handler's context.Context should be canceled once the handler returns")
+	}
+}
+
+func TestRecoverPanics(t *testing.T) {
+	panicking := func(ctx context.Context, s testStruct) error {
+		panic("boom")
+	}
+	e := thevent.Must(thevent.New(testStruct{}, panicking, thevent.RecoverPanics()))
+	ctx := context.Background()
+	res, err := e.DispatchWithResults(ctx, testStruct{})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(res.Errors) != 1 {
+		t.Fatal("Unexpected results:", res)
+	}
+	if pe, ok := res.Errors[0].(thevent.PanicError); !ok || pe.Value != "boom" || len(pe.Stack) == 0 {
+		t.Error("Expected PanicError, got:", res.Errors[0])
+	}
+
+	errCh, err := e.DispatchAsyncWithResults(ctx, testStruct{})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	for err := range errCh {
+		if _, ok := err.(thevent.PanicError); !ok {
+			t.Error("Expected PanicError, got:", err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package thevent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WithLabels attaches the labels to the handler, e.g. the team owning the handler, so that the handler may be told
+// apart from the Event's other handlers. The labels are surfaced along with the handler's name in its
+// HandlerResults, HandlerCalls and HandlerErrors, and must not be modified. The labels of multiple WithLabels
+// options are merged.
+func WithLabels(labels map[string]string) HandlerOption {
+	return func(h *handler) error {
+		if len(labels) == 0 {
+			return TypeError{errors.New("Handler labels must not be empty")}
+		}
+		merged := make(map[string]string, len(h.labels)+len(labels))
+		for k, v := range h.labels {
+			merged[k] = v
+		}
+		for k, v := range labels {
+			merged[k] = v
+		}
+		h.labels = merged
+		return nil
+	}
+}
+
+// AddNamedHandler adds the Handler configured with the HandlerOptions to the Event with the name, e.g.
+// "billing.invoice". See WithName()
+func (e *Event) AddNamedHandler(name string, h Handler, opts ...HandlerOption) error {
+	return e.AddHandlerWithOptions(h, append([]HandlerOption{WithName(name)}, opts...)...)
+}
+
+// HandlerError is the error of a named or labeled handler, as collected in HandlersResults, so the failing handler
+// may be identified. The handler's own error is in the HandlerResult.
+type HandlerError struct {
+	Name   string
+	Labels map[string]string
+	Err    error
+}
+
+func (e HandlerError) Error() string {
+	if len(e.Labels) == 0 {
+		return fmt.Sprintf("Handler %s failed: %v", e.Name, e.Err)
+	}
+	labels := make([]string, 0, len(e.Labels))
+	for k, v := range e.Labels {
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+	if e.Name == "" {
+		return fmt.Sprintf("Handler {%s} failed: %v", strings.Join(labels, ", "), e.Err)
+	}
+	return fmt.Sprintf("Handler %s {%s} failed: %v", e.Name, strings.Join(labels, ", "), e.Err)
+}
+
+// Unwrap returns the handler's error
+func (e HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (e HandlerError) MarshalText() ([]byte, error) {
+	return []byte(e.Error()), nil
+}
+
+// MarshalJSON implements json.Marshaler. HandlerErrors are marshaled as a JSON object with the kind, handler,
+// labels and message of the error.
+func (e HandlerError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(structuredError{Kind: "HandlerError", Handler: e.Name, Labels: e.Labels,
+		Message: e.Err.Error()})
+}
+
+// wrapError wraps the handler's error in a HandlerError if the handler is named or labeled
+func (h *handler) wrapError(err error) error {
+	if err == nil || h.name == "" && len(h.labels) == 0 {
+		return err
+	}
+	return HandlerError{Name: h.name, Labels: h.labels, Err: err}
+}
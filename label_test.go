@@ -0,0 +1,78 @@
+package thevent_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestAddNamedHandler(t *testing.T) {
+	errFailed := errors.New("failed")
+	e := thevent.Must(thevent.New(0, func(ctx context.Context, i int) error {
+		return errFailed
+	}))
+	if err := e.AddNamedHandler("billing.invoice", func(ctx context.Context, i int) error {
+		return errFailed
+	}, thevent.WithLabels(map[string]string{"team": "billing"}),
+		thevent.WithLabels(map[string]string{"tier": "1"})); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	if err := e.AddHandlerWithOptions(func(ctx context.Context, i int) error {
+		return errFailed
+	}, thevent.WithLabels(map[string]string{"team": "search"})); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+
+	done := make(chan struct{})
+	results, err := e.DispatchWithResults(context.Background(), 1)
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	expected := []string{"failed", "Handler billing.invoice {team=billing, tier=1} failed: failed",
+		"Handler {team=search} failed: failed"}
+	if len(results.Errors) != len(expected) {
+		t.Fatal("Got errors:", results.Errors, "instead of:", expected)
+	}
+	for i, err := range results.Errors {
+		if err.Error() != expected[i] {
+			t.Error("Got error:", err, "instead of:", expected[i])
+		}
+	}
+	if he, ok := results.Errors[1].(thevent.HandlerError); !ok || he.Unwrap() != errFailed {
+		t.Error("Expected a HandlerError wrapping the handler's error, got:", results.Errors[1])
+	}
+	b, err := json.Marshal(results.Errors[1])
+	if err != nil {
+		t.Fatal("Unable to marshal HandlerError:", err)
+	}
+	if expectedJSON := `{"kind":"HandlerError","handler":"billing.invoice",` +
+		`"labels":{"team":"billing","tier":"1"},"message":"failed"}`; string(b) != expectedJSON {
+		t.Error("Got JSON:", string(b), "instead of:", expectedJSON)
+	}
+
+	var lock sync.Mutex
+	names := map[string]bool{}
+	if err := e.DispatchAsyncWithCallback(context.Background(), 1, func(hr thevent.HandlerResult) {
+		lock.Lock()
+		defer lock.Unlock()
+		if hr.Name == "billing.invoice" && hr.Labels["team"] == "billing" && hr.Err == errFailed {
+			names[hr.Name] = true
+		}
+	}, func() { close(done) }); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	<-done
+	if !names["billing.invoice"] {
+		t.Error("Expected the HandlerResult to have the handler's name and labels")
+	}
+
+	errorMatchesGlob(t, e.AddNamedHandler("billing.invoice", intHandler), "Duplicate handler name: billing.invoice")
+	errorMatchesGlob(t, e.AddHandlerWithOptions(intHandler, thevent.WithLabels(nil)),
+		"Handler labels must not be empty")
+}
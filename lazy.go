@@ -0,0 +1,62 @@
+package thevent
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// lazyFactory is a Handler created by Lazy()
+type lazyFactory func() (Handler, error)
+
+// lazy materializes a lazy handler of an Event
+type lazy struct {
+	event   *Event
+	factory lazyFactory
+	lock    sync.Mutex
+	target  *handler
+}
+
+// Lazy creates a Handler which is only created by the factory the first time the Event it's added to is dispatched,
+// so rarely dispatched Events with expensive handler setup, e.g. database pools or API clients, don't pay for it on
+// startup. The created handler is type checked once it's created.
+//
+// If the factory returns an error, or a handler with an incorrect type, the error is returned as the handler's error
+// and the factory is called again on the next dispatch. The factory is called at most once at a time. The results
+// of the lazy handler report the factory as the Handler.
+func Lazy(factory func() (Handler, error)) Handler {
+	if factory == nil {
+		return nil
+	}
+	return lazyFactory(factory)
+}
+
+// newLazyHandler creates the handler which materializes the lazy handler when it's first called
+func (e *Event) newLazyHandler(factory lazyFactory) *handler {
+	l := &lazy{event: e, factory: factory}
+	return &handler{fn: reflect.ValueOf(factory), lazy: l}
+}
+
+// get gets the materialized handler, creating it if needed
+func (l *lazy) get() (*handler, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.target != nil {
+		return l.target, nil
+	}
+	h, err := l.factory()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create lazy handler: %v", err)
+	}
+	if _, ok := h.(lazyFactory); ok {
+		return nil, errors.New("Unable to create lazy handler: Lazy handler factory created a lazy handler")
+	}
+	// Errors aren't returned as TypeErrors, so that they're reported like other handler errors
+	target, err := l.event.newHandler(h)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create lazy handler: %v", err)
+	}
+	l.target = target
+	return target, nil
+}
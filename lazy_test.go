@@ -0,0 +1,67 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestLazy(t *testing.T) {
+	if thevent.Lazy(nil) != nil {
+		t.Error("Expected nil Handler for nil factory")
+	}
+
+	created := 0
+	var handled []int
+	factoryErr := errors.New("db unreachable")
+	e := thevent.Must(thevent.New(0, thevent.Lazy(func() (thevent.Handler, error) {
+		created++
+		switch created {
+		case 1:
+			return nil, factoryErr
+		case 2:
+			return func(ctx context.Context, s string) error { return nil }, nil
+		}
+		return func(ctx context.Context, i int) error { // nolint: unparam
+			handled = append(handled, i)
+			return nil
+		}, nil
+	})))
+	if created != 0 {
+		t.Fatal("Lazy handler created before the first dispatch")
+	}
+
+	ctx := context.Background()
+	res, err := e.DispatchWithResults(ctx, 0)
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if res.NumHandlers != 1 || len(res.Errors) != 1 {
+		t.Fatal("Unexpected results:", res)
+	}
+	errorMatchesGlob(t, res.Errors[0], "Unable to create lazy handler: db unreachable")
+	res, err = e.DispatchWithResults(ctx, 1)
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(res.Errors) != 1 {
+		t.Fatal("Unexpected results:", res)
+	}
+	errorMatchesGlob(t, res.Errors[0], "Unable to create lazy handler: Handler uses incorrect data type. "+
+		"Expected: func(context.Context, int) error Got: func(context.Context, string) error")
+	for i := 2; i < 4; i++ {
+		if err := e.Dispatch(ctx, i); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	}
+	if created != 3 {
+		t.Error("Expected the lazy handler to be created once it succeeds, created:", created)
+	}
+	if len(handled) != 2 || handled[0] != 2 || handled[1] != 3 {
+		t.Error("Unexpected handled data:", handled)
+	}
+}
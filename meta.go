@@ -0,0 +1,88 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"time"
+)
+
+// HandlerPanic is the event data for the HandlerPanicked meta-Event
+type HandlerPanic struct {
+	Event   *Event
+	Handler Handler
+	// Value is the value the handler panicked with
+	Value interface{}
+	Stack []byte
+	// RegisteredAt is where the handler was registered, in builds with the thevent_debug build tag
+	RegisteredAt string
+}
+
+// SlowHandler is the event data for the HandlerSlow meta-Event
+type SlowHandler struct {
+	Event     *Event
+	Handler   Handler
+	Duration  time.Duration
+	Threshold time.Duration
+}
+
+// DispatchFailure is the event data for the DispatchFailed meta-Event
+type DispatchFailure struct {
+	Event *Event
+	Data  Data
+	Err   error
+}
+
+// Meta-Events are dispatched by thevent to report on the health of the event system. Applications may add
+// handlers to the meta-Events like any other Event. Meta-Events are not reported on by other meta-Events.
+var (
+	// HandlerPanicked is dispatched when a handler panics. The panic is propagated once HandlerPanicked has
+	// been dispatched.
+	HandlerPanicked = newMetaEvent(HandlerPanic{})
+	// HandlerSlow is dispatched when a handler takes longer to run than the threshold configured on the Event
+	// using WithSlowHandlerThreshold()
+	HandlerSlow = newMetaEvent(SlowHandler{})
+	// DispatchFailed is dispatched when dispatching an Event fails
+	DispatchFailed = newMetaEvent(DispatchFailure{})
+)
+
+func newMetaEvent(data interface{}) *Event {
+	e := Must(New(data))
+	e.meta = true
+	return e
+}
+
+// WithSlowHandlerThreshold configures the Event to dispatch the HandlerSlow meta-Event whenever one of its
+// handlers takes longer than the threshold to run
+func WithSlowHandlerThreshold(threshold time.Duration) Option {
+	return func(e *Event) error {
+		if threshold <= 0 {
+			return TypeError{errors.New("Slow handler threshold must be positive")}
+		}
+		e.slowHandlerThreshold = threshold
+		return nil
+	}
+}
+
+// dispatchMeta dispatches the meta-Event unless the given Event is itself a meta-Event
+func (e *Event) dispatchMeta(ctx context.Context, metaEvent *Event, data interface{}) {
+	if e.meta {
+		return
+	}
+	metaEvent.Dispatch(ctx, data) // nolint: errcheck
+}
+
+// reportPanic dispatches the HandlerPanicked meta-Event for a recovered panic
+func (e *Event) reportPanic(ctx context.Context, h *handler, r interface{}) {
+	e.dispatchMeta(ctx, HandlerPanicked, HandlerPanic{Event: e, Handler: h.fn.Interface(), Value: r,
+		Stack: debug.Stack(), RegisteredAt: h.registeredAt})
+}
+
+// reportDispatchErr dispatches the DispatchFailed meta-Event if the dispatch failed. Shed dispatches aren't
+// reported so that shedding doesn't add to the load.
+func (e *Event) reportDispatchErr(ctx context.Context, data interface{}, err error) {
+	if err == nil || err == ErrShed {
+		return
+	}
+	e.dispatchMeta(ctx, DispatchFailed, DispatchFailure{Event: e, Data: data, Err: err})
+}
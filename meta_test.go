@@ -0,0 +1,109 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestDispatchFailed(t *testing.T) {
+	e, err := thevent.New(0)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	var failures []thevent.DispatchFailure
+	if err := thevent.DispatchFailed.AddHandlers(func(ctx context.Context, f thevent.DispatchFailure) error {
+		if f.Event == e {
+			failures = append(failures, f)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal("Unable to add handler to meta-Event:", err)
+	}
+
+	ctx := context.Background()
+	if err := e.Dispatch(ctx, 1); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(failures) != 0 {
+		t.Fatal("Got unexpected dispatch failures:", failures)
+	}
+	err = e.Dispatch(ctx, "wrong data type")
+	errorMatchesGlob(t, err, "Dispatch called with incorrect event data type. Expected: int Got: string")
+	if len(failures) != 1 {
+		t.Fatal("Expected 1 dispatch failure, got:", failures)
+	}
+	if failures[0].Data != "wrong data type" || failures[0].Err != err {
+		t.Error("Got unexpected dispatch failure:", failures[0])
+	}
+}
+
+func TestHandlerSlow(t *testing.T) {
+	if _, err := thevent.New(0, thevent.WithSlowHandlerThreshold(0)); err == nil {
+		t.Error("Created event with an invalid slow handler threshold")
+	}
+
+	slowHandler := func(ctx context.Context, i int) error { // nolint: unparam
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}
+	e, err := thevent.New(0, slowHandler, thevent.WithSlowHandlerThreshold(time.Millisecond))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	var slow []thevent.SlowHandler
+	if err := thevent.HandlerSlow.AddHandlers(func(ctx context.Context, s thevent.SlowHandler) error {
+		if s.Event == e {
+			slow = append(slow, s)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal("Unable to add handler to meta-Event:", err)
+	}
+
+	if err := e.Dispatch(context.Background(), 1); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(slow) != 1 {
+		t.Fatal("Expected 1 slow handler, got:", slow)
+	}
+	if slow[0].Duration < 10*time.Millisecond || slow[0].Threshold != time.Millisecond {
+		t.Error("Got unexpected slow handler:", slow[0])
+	}
+}
+
+func TestHandlerPanicked(t *testing.T) {
+	panicHandler := func(ctx context.Context, i int) error { panic("handler panicked") }
+	e, err := thevent.New(0, panicHandler)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	var panics []thevent.HandlerPanic
+	if err := thevent.HandlerPanicked.AddHandlers(func(ctx context.Context, p thevent.HandlerPanic) error {
+		if p.Event == e {
+			panics = append(panics, p)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal("Unable to add handler to meta-Event:", err)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "handler panicked" {
+				t.Error("Expected the panic to be propagated, got:", r)
+			}
+		}()
+		e.Dispatch(context.Background(), 1) // nolint: errcheck
+	}()
+	if len(panics) != 1 {
+		t.Fatal("Expected 1 handler panic, got:", panics)
+	}
+	if panics[0].Value != "handler panicked" || len(panics[0].Stack) == 0 {
+		t.Error("Got unexpected handler panic:", panics[0])
+	}
+}
@@ -0,0 +1,115 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type metadataCtxKey struct{}
+
+// WithMetadata attaches static metadata to the Event which is injected into the context.Context passed to the
+// Event's handlers on every dispatch. Metadata is inherited by sub-Events and may be overridden by a sub-Event.
+func WithMetadata(key, value string) Option {
+	return func(e *Event) error {
+		if e.metadata == nil {
+			e.metadata = map[string]string{}
+		}
+		e.metadata[key] = value
+		return nil
+	}
+}
+
+// MetadataFromContext returns a copy of the Event metadata injected into the given context.Context
+func MetadataFromContext(ctx context.Context) map[string]string {
+	md, _ := ctx.Value(metadataCtxKey{}).(map[string]string)
+	c := make(map[string]string, len(md))
+	for k, v := range md {
+		c[k] = v
+	}
+	return c
+}
+
+// MetadataValueFromContext returns the Event metadata value for the key injected into the given context.Context
+func MetadataValueFromContext(ctx context.Context, key string) (string, bool) {
+	md, _ := ctx.Value(metadataCtxKey{}).(map[string]string)
+	v, ok := md[key]
+	return v, ok
+}
+
+// withMetadata injects the Event's metadata into the context.Context, merging it with any metadata already
+// injected by a parent Event
+func (e *Event) withMetadata(ctx context.Context) context.Context {
+	if len(e.metadata) == 0 {
+		return ctx
+	}
+	parentMd, _ := ctx.Value(metadataCtxKey{}).(map[string]string)
+	if len(parentMd) == 0 {
+		return context.WithValue(ctx, metadataCtxKey{}, e.metadata)
+	}
+	md := make(map[string]string, len(parentMd)+len(e.metadata))
+	for k, v := range parentMd {
+		md[k] = v
+	}
+	for k, v := range e.metadata {
+		md[k] = v
+	}
+	return context.WithValue(ctx, metadataCtxKey{}, md)
+}
+
+// WithContextDecorator configures the Event to decorate the context.Context passed to its handlers on every
+// dispatch. e.g. to add a logger, tenant or feature flags without every caller having to. Decorators are applied in
+// the order in which they're configured, after the Event's metadata is injected. Since sub-Events are dispatched
+// with the decorated context.Context, decorations are inherited by sub-Events.
+func WithContextDecorator(decorate func(context.Context) context.Context) Option {
+	return func(e *Event) error {
+		if decorate == nil {
+			return TypeError{errors.New("Context decorator must not be nil")}
+		}
+		e.contextDecorators = append(e.contextDecorators, decorate)
+		return nil
+	}
+}
+
+// decorateContext applies the Event's context decorators to the context.Context
+func (e *Event) decorateContext(ctx context.Context) context.Context {
+	for _, decorate := range e.contextDecorators {
+		ctx = decorate(ctx)
+	}
+	return ctx
+}
+
+// RequireContext configures the Event to check the context.Context of every dispatch, after the Event's metadata
+// and context decorators have been applied, and fail the dispatch before any handlers are run if the check returns
+// an error. e.g. to catch dispatches made with context.Background() by accident. Checks are also run when the Event
+// is dispatched by a parent Event.
+func RequireContext(check func(context.Context) error) Option {
+	return func(e *Event) error {
+		if check == nil {
+			return TypeError{errors.New("Context check must not be nil")}
+		}
+		e.contextChecks = append(e.contextChecks, check)
+		return nil
+	}
+}
+
+// RequireContextValue configures the Event to fail dispatches made with a context.Context without a value for the
+// key. e.g. a request ID or tenant. The name describes the value in the error. See RequireContext()
+func RequireContextValue(name string, key interface{}) Option {
+	return RequireContext(func(ctx context.Context) error {
+		if ctx.Value(key) == nil {
+			return fmt.Errorf("Missing required context value: %s", name)
+		}
+		return nil
+	})
+}
+
+// checkContext runs the Event's context checks on the context.Context
+func (e *Event) checkContext(ctx context.Context) error {
+	for _, check := range e.contextChecks {
+		if err := check(ctx); err != nil {
+			return fmt.Errorf("Dispatch called with invalid context: %v", err)
+		}
+	}
+	return nil
+}
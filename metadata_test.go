@@ -0,0 +1,173 @@
+package thevent_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestMetadata(t *testing.T) {
+	var parentMd, childMd map[string]string
+	parentHandler := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		parentMd = thevent.MetadataFromContext(ctx)
+		return nil
+	}
+	childHandler := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		childMd = thevent.MetadataFromContext(ctx)
+		return nil
+	}
+	e, err := thevent.New(testStruct{}, parentHandler, thevent.WithMetadata("domain", "billing"),
+		thevent.WithMetadata("team", "payments"))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if _, err := e.New(testStruct{}, "", childHandler, thevent.WithMetadata("team", "invoicing")); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+
+	if err := e.Dispatch(context.Background(), testStruct{}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+
+	testCases := []struct {
+		name     string
+		md       map[string]string
+		expected map[string]string
+	}{
+		{name: "parent", md: parentMd, expected: map[string]string{"domain": "billing", "team": "payments"}},
+		{name: "child", md: childMd, expected: map[string]string{"domain": "billing", "team": "invoicing"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if len(tc.md) != len(tc.expected) {
+				t.Fatal("Got metadata:", tc.md, "expected:", tc.expected)
+			}
+			for k, v := range tc.expected {
+				if tc.md[k] != v {
+					t.Error("Got metadata:", tc.md, "expected:", tc.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestMetadataValueFromContext(t *testing.T) {
+	if _, ok := thevent.MetadataValueFromContext(context.Background(), "domain"); ok {
+		t.Error("Found metadata in a context without metadata")
+	}
+	var v string
+	var ok bool
+	handler := func(ctx context.Context, i int) error { // nolint: unparam
+		v, ok = thevent.MetadataValueFromContext(ctx, "domain")
+		return nil
+	}
+	e, err := thevent.New(0, handler, thevent.WithMetadata("domain", "billing"))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if err := e.Dispatch(context.Background(), 1); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if !ok || v != "billing" {
+		t.Error("Got metadata value:", v, ok, "expected: billing")
+	}
+}
+
+func TestWithContextDecorator(t *testing.T) {
+	type ctxKey string
+	withValue := func(key, value string) func(context.Context) context.Context {
+		return func(ctx context.Context) context.Context { return context.WithValue(ctx, ctxKey(key), value) }
+	}
+	var parentTenant, childTenant, childLogger interface{}
+	parentHandler := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		parentTenant = ctx.Value(ctxKey("tenant"))
+		return nil
+	}
+	childHandler := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		childTenant, childLogger = ctx.Value(ctxKey("tenant")), ctx.Value(ctxKey("logger"))
+		return nil
+	}
+
+	_, err := thevent.New(testStruct{}, thevent.WithContextDecorator(nil))
+	errorMatchesGlob(t, err, "Context decorator must not be nil")
+
+	e, err := thevent.New(testStruct{}, parentHandler, thevent.WithContextDecorator(withValue("tenant", "acme")))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if _, err := e.New(testStruct{}, "", childHandler,
+		thevent.WithContextDecorator(withValue("logger", "child"))); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if err := e.Dispatch(context.Background(), testStruct{}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if parentTenant != "acme" || childTenant != "acme" || childLogger != "child" {
+		t.Error("Unexpected context values. parent tenant:", parentTenant, "child tenant:", childTenant,
+			"child logger:", childLogger)
+	}
+}
+
+func TestRequireContextValue(t *testing.T) {
+	type ctxKey string
+	var called int32
+	handler := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		atomic.AddInt32(&called, 1)
+		return nil
+	}
+	_, err := thevent.New(testStruct{}, thevent.RequireContext(nil))
+	errorMatchesGlob(t, err, "Context check must not be nil")
+
+	e, err := thevent.New(testStruct{}, handler, thevent.RequireContextValue("request ID", ctxKey("requestID")))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	child, err := e.New(testStruct{}, "", thevent.RequireContextValue("tenant", ctxKey("tenant")))
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	decorated, err := thevent.New(testStruct{}, thevent.RequireContextValue("tenant", ctxKey("tenant")),
+		thevent.WithContextDecorator(func(ctx context.Context) context.Context {
+			return context.WithValue(ctx, ctxKey("tenant"), "acme")
+		}))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+
+	ctx := context.Background()
+	withRequestID := context.WithValue(ctx, ctxKey("requestID"), "1")
+	testCases := []struct {
+		name        string
+		event       *thevent.Event
+		ctx         context.Context
+		expectedErr string
+	}{
+		{name: "missing", event: e, ctx: ctx,
+			expectedErr: "Dispatch called with invalid context: Missing required context value: request ID"},
+		{name: "missing in sub-Event", event: e, ctx: withRequestID,
+			expectedErr: "*Missing required context value: tenant*"},
+		{name: "present", event: e, ctx: context.WithValue(withRequestID, ctxKey("tenant"), "acme")},
+		{name: "decorated", event: decorated, ctx: ctx},
+		{name: "sub-Event", event: child, ctx: ctx,
+			expectedErr: "Dispatch called with invalid context: Missing required context value: tenant"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.event.Dispatch(tc.ctx, testStruct{})
+			errorMatchesGlob(t, err, tc.expectedErr)
+			err = tc.event.DispatchAsync(tc.ctx, testStruct{})
+			errorMatchesGlob(t, err, tc.expectedErr)
+		})
+	}
+	if err := e.Barrier(ctx); err != nil {
+		t.Fatal("Unexpected error waiting for handlers:", err)
+	}
+	// Only the dispatches with a request ID run the parent's handler
+	if called := atomic.LoadInt32(&called); called != 4 {
+		t.Error("Unexpected handler calls:", called)
+	}
+}
@@ -0,0 +1,123 @@
+package thevent
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// methodKey identifies a method of a pointer receiver registered as a handler
+type methodKey struct {
+	recvType reflect.Type
+	recv     uintptr
+	index    int
+}
+
+// RegisterMethods adds the exported methods of obj as handlers, reducing the wiring needed for subscriber structs.
+// target must be an *Event or a *Bus.
+//
+// For an *Event, every exported method of obj which is a valid handler for the Event is added to the Event. An error
+// is returned if obj has no such methods.
+//
+// For a *Bus, every exported method of obj named On<Name> is added to the Event registered on the Bus with a name
+// matching <Name>, ignoring case and non-alphanumeric characters. e.g. OnOrderCreated is added to the Event named
+// "order.created". An error is returned if any of the methods doesn't match exactly one Event or isn't a valid handler
+// for the Event. Other methods are ignored. The methods are either added to all of the Events or to none of them,
+// though Events dispatched while the methods are being registered may run the methods added before a failure.
+//
+// Methods are added in lexicographic order. Methods of a pointer obj are considered duplicates if the same obj is
+// registered again. Methods of a non-pointer obj are never considered duplicates.
+func RegisterMethods(target interface{}, obj interface{}) error {
+	v := reflect.ValueOf(obj)
+	if !v.IsValid() {
+		return TypeError{errors.New("Unable to register methods of nil")}
+	}
+	switch t := target.(type) {
+	case *Event:
+		var handlers []*handler
+		for i := 0; i < v.NumMethod(); i++ {
+			if cH, err := t.newMethodHandler(v, i); err == nil {
+				handlers = append(handlers, cH)
+			}
+		}
+		if len(handlers) == 0 {
+			return TypeError{fmt.Errorf("No handler methods found on %T", obj)}
+		}
+		return t.addHandlers(nil, handlers)
+	case *Bus:
+		names, events := t.registered()
+		byEvent := map[*Event][]*handler{}
+		var order []*Event
+		for i := 0; i < v.NumMethod(); i++ {
+			name := v.Type().Method(i).Name
+			if !strings.HasPrefix(name, "On") || len(name) == len("On") {
+				continue
+			}
+			var e *Event
+			for j, n := range names {
+				if normalizeName(n) != normalizeName(name[len("On"):]) {
+					continue
+				}
+				if e != nil {
+					return TypeError{fmt.Errorf("Multiple events registered matching method %s", name)}
+				}
+				e = events[j]
+			}
+			if e == nil {
+				return TypeError{fmt.Errorf("No event registered matching method %s", name)}
+			}
+			cH, err := e.newMethodHandler(v, i)
+			if err != nil {
+				return TypeError{fmt.Errorf("Unable to register method %s: %v", name, err)}
+			}
+			if _, ok := byEvent[e]; !ok {
+				order = append(order, e)
+			}
+			byEvent[e] = append(byEvent[e], cH)
+		}
+		return addEventsHandlers(order, byEvent)
+	}
+	return TypeError{fmt.Errorf("Unable to register methods with target of type %T", target)}
+}
+
+// addEventsHandlers adds the handlers to the Events in order. If adding the handlers to an Event fails, the handlers
+// already added to the previous Events are removed.
+func addEventsHandlers(order []*Event, byEvent map[*Event][]*handler) error {
+	for i, e := range order {
+		if err := e.addHandlers(nil, byEvent[e]); err != nil {
+			for _, added := range order[:i] {
+				for _, h := range byEvent[added] {
+					added.removeHandler(h)
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// newMethodHandler converts the method of the receiver to a handler
+func (e *Event) newMethodHandler(recv reflect.Value, index int) (*handler, error) {
+	cH, err := e.newHandler(recv.Method(index).Interface())
+	if err != nil {
+		return nil, err
+	}
+	if recv.Kind() == reflect.Ptr {
+		cH.method = methodKey{recvType: recv.Type(), recv: recv.Pointer(), index: index}
+	} else {
+		cH.method = cH
+	}
+	return cH, nil
+}
+
+// normalizeName lower cases the name and removes non-alphanumeric characters
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
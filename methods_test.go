@@ -0,0 +1,142 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+type orderCreated struct {
+	ID int
+}
+
+type orderShipped struct {
+	ID int
+}
+
+type orderSubscriber struct {
+	calls []string
+}
+
+func (s *orderSubscriber) OnOrderCreated(ctx context.Context, o orderCreated) error { // nolint: unparam
+	s.calls = append(s.calls, "created")
+	return nil
+}
+
+func (s *orderSubscriber) OnOrderShipped(ctx context.Context, o orderShipped) error { // nolint: unparam
+	s.calls = append(s.calls, "shipped")
+	return nil
+}
+
+func (s *orderSubscriber) AuditOrderCreated(ctx context.Context, o orderCreated) error { // nolint: unparam
+	s.calls = append(s.calls, "audited")
+	return nil
+}
+
+// Reset isn't a handler
+func (s *orderSubscriber) Reset() {
+	s.calls = nil
+}
+
+type invalidSubscriber struct{}
+
+func (invalidSubscriber) OnOrderCreated(ctx context.Context, i int) error { return nil }
+
+type unmatchedSubscriber struct{}
+
+func (unmatchedSubscriber) OnOrderCanceled(ctx context.Context, o orderCreated) error { return nil }
+
+func TestRegisterMethodsEvent(t *testing.T) {
+	e := thevent.Must(thevent.New(orderCreated{}))
+	s := &orderSubscriber{}
+	if err := thevent.RegisterMethods(e, s); err != nil {
+		t.Fatal("Unable to register methods:", err)
+	}
+	if err := e.Dispatch(context.Background(), orderCreated{ID: 1}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(s.calls) != 2 || s.calls[0] != "audited" || s.calls[1] != "created" {
+		t.Error("Unexpected calls:", s.calls)
+	}
+	err := thevent.RegisterMethods(e, s)
+	errorMatchesGlob(t, err, "Unable to add duplicate handler")
+	if err := thevent.RegisterMethods(e, &orderSubscriber{}); err != nil {
+		t.Error("Unable to register methods of another subscriber:", err)
+	}
+	err = thevent.RegisterMethods(e, invalidSubscriber{})
+	errorMatchesGlob(t, err, "No handler methods found on thevent_test.invalidSubscriber")
+	err = thevent.RegisterMethods(e, nil)
+	errorMatchesGlob(t, err, "Unable to register methods of nil")
+	err = thevent.RegisterMethods(1, s)
+	errorMatchesGlob(t, err, "Unable to register methods with target of type int")
+}
+
+func TestRegisterMethodsBus(t *testing.T) {
+	bus := thevent.NewBus()
+	created, err := bus.New("order.created", orderCreated{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	shipped, err := bus.New("order_shipped", orderShipped{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	s := &orderSubscriber{}
+	if err := thevent.RegisterMethods(bus, s); err != nil {
+		t.Fatal("Unable to register methods:", err)
+	}
+	ctx := context.Background()
+	if err := created.Dispatch(ctx, orderCreated{ID: 1}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if err := shipped.Dispatch(ctx, orderShipped{ID: 1}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(s.calls) != 2 || s.calls[0] != "created" || s.calls[1] != "shipped" {
+		t.Error("Unexpected calls:", s.calls)
+	}
+
+	testCases := []struct {
+		name        string
+		obj         interface{}
+		expectedErr string
+	}{
+		{name: "invalid handler", obj: invalidSubscriber{},
+			expectedErr: "Unable to register method OnOrderCreated: Handler uses incorrect data type*"},
+		{name: "unmatched", obj: unmatchedSubscriber{},
+			expectedErr: "No event registered matching method OnOrderCanceled"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errorMatchesGlob(t, thevent.RegisterMethods(bus, tc.obj), tc.expectedErr)
+		})
+	}
+	if _, err := bus.New("OrderCreated", orderCreated{}); err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	err = thevent.RegisterMethods(bus, &orderSubscriber{})
+	errorMatchesGlob(t, err, "Multiple events registered matching method OnOrderCreated")
+}
+
+func TestRegisterMethodsBusRollback(t *testing.T) {
+	bus := thevent.NewBus()
+	created, err := bus.New("order.created", orderCreated{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	shipped, err := bus.New("order_shipped", orderShipped{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	s := &orderSubscriber{}
+	if err := thevent.RegisterMethods(shipped, s); err != nil {
+		t.Fatal("Unable to register methods:", err)
+	}
+	errorMatchesGlob(t, thevent.RegisterMethods(bus, s), "Unable to add duplicate handler")
+	if handlers := created.Handlers(); len(handlers) != 0 {
+		t.Error("Methods added before the failure should be removed:", handlers)
+	}
+}
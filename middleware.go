@@ -0,0 +1,154 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// HandlerCall describes a single run of a handler by a dispatch
+type HandlerCall struct {
+	Event   EventInfo
+	Handler Handler
+	// Name and Labels are the handler's name and labels, if any. See WithName() and WithLabels()
+	Name   string
+	Labels map[string]string
+	Data   interface{}
+	// RegisteredAt is where the handler was registered, in builds with the thevent_debug build tag
+	RegisteredAt string
+}
+
+// Next runs the rest of the middleware chain and the handler with the given context.Context
+type Next func(ctx context.Context) (Outcome, error)
+
+// Middleware wraps every run of an Event's handlers, e.g. for logging, metrics or recovering from panics. A
+// Middleware should call next at most once and return its results, unless it's short-circuiting the handler.
+// Middleware runs for every attempt of a handler with a RetryPolicy. See the theventmiddleware package for common
+// middleware.
+type Middleware func(ctx context.Context, call HandlerCall, next Next) (Outcome, error)
+
+// WithMiddleware configures the Event to run its handlers through the middleware. The first middleware is the
+// outermost, i.e. it's the first to run before the handler and the last to run after it.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(e *Event) error {
+		for _, mw := range middlewares {
+			if mw == nil {
+				return TypeError{errors.New("Middleware must not be nil")}
+			}
+		}
+		e.middlewares = append(e.middlewares, middlewares...)
+		return nil
+	}
+}
+
+// WithoutMiddleware removes the middleware configured so far, e.g. the middleware inherited from the parent Event.
+// The middleware added to the parent Event later using Use() isn't added to the sub-Event or its sub-Events either.
+// See Inherited()
+func WithoutMiddleware() Option {
+	return func(e *Event) error {
+		e.middlewares = nil
+		e.withoutMiddleware = true
+		return nil
+	}
+}
+
+// Use adds the middleware to the end of the Event's middleware chain once the Event has been created, see
+// WithMiddleware(). The middleware is also added to the middleware chains of the Event's sub-Events, including the
+// sub-Events created later, unless they were created using WithoutMiddleware(). Handlers which are already running
+// aren't affected.
+func (e *Event) Use(middlewares ...Middleware) error {
+	return e.use(nil, middlewares)
+}
+
+// use adds the middleware to the Event and its sub-Events. owner is the Capability used to add the middleware, if
+// any.
+func (e *Event) use(owner *Capability, middlewares []Middleware) error {
+	for _, mw := range middlewares {
+		if mw == nil {
+			return TypeError{errors.New("Middleware must not be nil")}
+		}
+	}
+	e.lock.Lock()
+	if e.destroyed {
+		e.lock.Unlock()
+		return ErrDestroyed
+	}
+	if err := e.checkOwner(owner); err != nil {
+		e.lock.Unlock()
+		return err
+	}
+	e.lock.Unlock()
+	e.addMiddleware(middlewares)
+	return nil
+}
+
+// addMiddleware adds the middleware to the Event and its sub-Events, except for the sub-Events which opted out of
+// their parent's middleware
+func (e *Event) addMiddleware(middlewares []Middleware) {
+	e.lock.Lock()
+	// The middleware chain is copied since running handlers may be using it
+	e.middlewares = append(e.middlewares[:len(e.middlewares):len(e.middlewares)], middlewares...)
+	e.inherited = append(e.inherited[:len(e.inherited):len(e.inherited)], WithMiddleware(middlewares...))
+	children := append([]child(nil), e.children...)
+	e.lock.Unlock()
+	for _, c := range children {
+		if !c.event.withoutMiddleware {
+			c.event.addMiddleware(middlewares)
+		}
+	}
+}
+
+// Use adds the middleware to the Bus, so it runs around the handlers of every Event registered on the Bus and of
+// their sub-Events, including the Events registered later, e.g. for tracing. The Bus's middleware runs outside of
+// the Events' own middleware. Handlers which are already running aren't affected.
+func (b *Bus) Use(middlewares ...Middleware) error {
+	for _, mw := range middlewares {
+		if mw == nil {
+			return TypeError{errors.New("Middleware must not be nil")}
+		}
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	// The middleware chain is copied since running handlers may be using it
+	b.middlewares = append(b.middlewares[:len(b.middlewares):len(b.middlewares)], middlewares...)
+	return nil
+}
+
+// withMiddleware prepends the Bus's middleware to the Event's middleware
+func (b *Bus) withMiddleware(middlewares []Middleware) []Middleware {
+	b.lock.RLock()
+	busMiddlewares := b.middlewares
+	b.lock.RUnlock()
+	if len(busMiddlewares) == 0 {
+		return middlewares
+	}
+	return append(busMiddlewares[:len(busMiddlewares):len(busMiddlewares)], middlewares...)
+}
+
+// callWithMiddleware runs the handler once through the Bus's and the Event's middleware
+func (e *Event) callWithMiddleware(ctx context.Context, h *handler, args []reflect.Value) []reflect.Value {
+	e.lock.RLock()
+	middlewares := e.middlewares
+	e.lock.RUnlock()
+	if e.bus != nil {
+		middlewares = e.bus.withMiddleware(middlewares)
+	}
+	if len(middlewares) == 0 {
+		return e.callOnce(ctx, h, args)
+	}
+	data := args[1]
+	call := HandlerCall{Event: EventInfo{Name: e.busName, DataType: e.dataType}, Handler: h.fn.Interface(),
+		Name: h.name, Labels: h.labels, Data: data.Interface(), RegisteredAt: h.registeredAt}
+	next := func(ctx context.Context) (Outcome, error) {
+		res := e.callOnce(ctx, h, []reflect.Value{reflect.ValueOf(ctx), data})
+		return convertToOutcome(res), convertToError(res)
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		mw, inner := middlewares[i], next
+		next = func(ctx context.Context) (Outcome, error) {
+			return mw(ctx, call, inner)
+		}
+	}
+	outcome, err := next(ctx)
+	return []reflect.Value{reflect.ValueOf(outcome), reflect.ValueOf(&err).Elem()}
+}
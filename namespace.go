@@ -0,0 +1,155 @@
+package thevent
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// NamespaceSeparator separates the segments of hierarchical Event names registered on a Bus, e.g.
+// "billing.invoice.created" is in the "billing" and "billing.invoice" namespaces
+const NamespaceSeparator = "."
+
+// namespaceOptions are the default Options of the Events registered under a namespace
+type namespaceOptions struct {
+	namespace string
+	opts      []Option
+}
+
+// WithNamespaceOptions configures the Bus to apply the Options to every Event registered under the namespace, e.g.
+// middleware or metrics shared by all of the Events of a module. Defaults of enclosing namespaces are applied first,
+// and an Event's own Options are applied last, so they may override the defaults.
+func WithNamespaceOptions(namespace string, opts ...Option) BusOption {
+	return func(b *Bus) {
+		b.namespaces = append(b.namespaces, namespaceOptions{namespace: namespace, opts: opts})
+	}
+}
+
+// inNamespace returns true if the name is the namespace or is under the namespace. Every name is in the empty
+// namespace.
+func inNamespace(name, namespace string) bool {
+	return namespace == "" || name == namespace || strings.HasPrefix(name, namespace+NamespaceSeparator)
+}
+
+// withNamespaceOptions prepends the default Options of the namespaces of the name to the handlers, from the
+// outermost namespace to the innermost
+func (b *Bus) withNamespaceOptions(name string, handlers []Handler) []Handler {
+	var matched []namespaceOptions
+	for _, ns := range b.namespaces {
+		if inNamespace(name, ns.namespace) {
+			matched = append(matched, ns)
+		}
+	}
+	if len(matched) == 0 {
+		return handlers
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return namespaceDepth(matched[i].namespace) < namespaceDepth(matched[j].namespace)
+	})
+	var defaults []Handler
+	for _, ns := range matched {
+		for _, opt := range ns.opts {
+			defaults = append(defaults, opt)
+		}
+	}
+	return append(defaults, handlers...)
+}
+
+// namespaceDepth gets the number of segments of the namespace
+func namespaceDepth(namespace string) int {
+	if namespace == "" {
+		return 0
+	}
+	return strings.Count(namespace, NamespaceSeparator) + 1
+}
+
+// Find gets the names of the registered Events matching the pattern in registration order. The pattern is matched
+// against each segment of the names separated by NamespaceSeparator. A "*" segment matches any single segment and a
+// "**" segment matches any number of segments, e.g. "billing.*" matches "billing.paid" and "billing.**" also matches
+// "billing.invoice.created". Other segments are matched using path.Match(), so a malformed pattern returns
+// path.ErrBadPattern.
+func (b *Bus) Find(pattern string) ([]string, error) {
+	patternSegments := strings.Split(pattern, NamespaceSeparator)
+	// Validate the pattern up front, since it may not be matched against every segment
+	for _, p := range patternSegments {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, err
+		}
+	}
+	var matched []string
+	for _, name := range b.Names() {
+		if matchSegments(patternSegments, strings.Split(name, NamespaceSeparator)) {
+			matched = append(matched, name)
+		}
+	}
+	return matched, nil
+}
+
+// matchSegments matches the segments of a name against the segments of a pattern
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// Namespace is a view of the Events registered on a Bus under a namespace. Names used with a Namespace are relative
+// to the namespace.
+type Namespace struct {
+	bus       *Bus
+	namespace string
+}
+
+// Namespace gets the view of the Events registered under the namespace
+func (b *Bus) Namespace(namespace string) *Namespace {
+	return &Namespace{bus: b, namespace: namespace}
+}
+
+// Namespace gets the view of the Events registered under the nested namespace
+func (n *Namespace) Namespace(namespace string) *Namespace {
+	return &Namespace{bus: n.bus, namespace: n.fullName(namespace)}
+}
+
+// fullName gets the name registered on the Bus of the name relative to the namespace
+func (n *Namespace) fullName(name string) string {
+	if n.namespace == "" {
+		return name
+	}
+	return n.namespace + NamespaceSeparator + name
+}
+
+// New creates a new Event and registers it on the Bus under the namespace. See Bus.New()
+func (n *Namespace) New(name string, data interface{}, handlers ...Handler) (*Event, error) {
+	return n.bus.New(n.fullName(name), data, handlers...)
+}
+
+// Event gets the Event registered under the namespace with the given name
+func (n *Namespace) Event(name string) (*Event, bool) {
+	return n.bus.Event(n.fullName(name))
+}
+
+// Names gets the names, relative to the namespace, of the Events registered under the namespace in registration
+// order
+func (n *Namespace) Names() []string {
+	var names []string
+	for _, name := range n.bus.Names() {
+		if name != n.namespace && inNamespace(name, n.namespace) {
+			names = append(names, strings.TrimPrefix(name, n.fullName("")))
+		}
+	}
+	return names
+}
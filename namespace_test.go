@@ -0,0 +1,100 @@
+package thevent_test
+
+import (
+	"context"
+	"path"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestBusFind(t *testing.T) {
+	b := thevent.NewBus()
+	for _, name := range []string{"billing.paid", "billing.invoice.created", "shipping.sent", "billing"} {
+		if _, err := b.New(name, 0); err != nil {
+			t.Fatal("Unable to create event:", err)
+		}
+	}
+	testCases := []struct {
+		pattern  string
+		expected []string
+	}{
+		{pattern: "billing.*", expected: []string{"billing.paid"}},
+		{pattern: "billing.**", expected: []string{"billing.paid", "billing.invoice.created", "billing"}},
+		{pattern: "**.created", expected: []string{"billing.invoice.created"}},
+		{pattern: "*.s*", expected: []string{"shipping.sent"}},
+		{pattern: "**", expected: []string{"billing.paid", "billing.invoice.created", "shipping.sent", "billing"}},
+		{pattern: "orders.*"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			names, err := b.Find(tc.pattern)
+			if err != nil {
+				t.Fatal("Unexpected error finding events:", err)
+			}
+			if len(names) != len(tc.expected) {
+				t.Fatal("Got names:", names, "instead of:", tc.expected)
+			}
+			for i := range names {
+				if names[i] != tc.expected[i] {
+					t.Error("Got names:", names, "instead of:", tc.expected)
+				}
+			}
+		})
+	}
+	if _, err := b.Find("billing.["); err != path.ErrBadPattern {
+		t.Error("Expected ErrBadPattern, got:", err)
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	var metadata []map[string]string
+	handler := func(ctx context.Context, i int) error { // nolint: unparam
+		metadata = append(metadata, thevent.MetadataFromContext(ctx))
+		return nil
+	}
+	b := thevent.NewBus(
+		thevent.WithNamespaceOptions("billing.invoice", thevent.WithMetadata("owner", "invoicing")),
+		thevent.WithNamespaceOptions("billing", thevent.WithMetadata("owner", "billing"),
+			thevent.WithMetadata("team", "payments")),
+		thevent.WithNamespaceOptions("shipping", thevent.WithMetadata("owner", "shipping")),
+	)
+	billing := b.Namespace("billing")
+	paid, err := billing.New("paid", 0, handler)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	created, err := billing.Namespace("invoice").New("created", 0, handler)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	overridden, err := b.New("billing.refunded", 0, handler, thevent.WithMetadata("owner", "refunds"))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if e, ok := b.Event("billing.invoice.created"); !ok || e != created {
+		t.Error("Event not registered with its full name")
+	}
+	if e, ok := billing.Event("invoice.created"); !ok || e != created {
+		t.Error("Unable to get event relative to the namespace")
+	}
+	if names := billing.Names(); len(names) != 3 || names[0] != "paid" || names[1] != "invoice.created" ||
+		names[2] != "refunded" {
+		t.Error("Unexpected names in namespace:", names)
+	}
+
+	ctx := context.Background()
+	for _, e := range []*thevent.Event{paid, created, overridden} {
+		if err := e.Dispatch(ctx, 1); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	}
+	expected := []string{"billing", "invoicing", "refunds"}
+	for i, md := range metadata {
+		if md["owner"] != expected[i] || md["team"] != "payments" {
+			t.Error("Got unexpected metadata:", md)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !thevent_debug
+// +build !thevent_debug
+
+package thevent
+
+// callerStack captures the stack of the caller in debug builds
+func callerStack() []byte {
+	return nil
+}
+
+// registrationSite gets the file and line outside of thevent where a handler is being registered in debug builds
+func registrationSite() string {
+	return ""
+}
+
+// usageAccounting enables accounting the resource consumption of handlers in debug builds. See HandlerUsage
+const usageAccounting = false
@@ -0,0 +1,157 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Handled may be returned by a Handler to signal that it has consumed the event. Handled is never treated as
+// an error, even if it's wrapped. If the Event was created with the StopOnHandled() Option, the Event's remaining
+// handlers are skipped during a synchronous dispatch. e.g. chain-of-responsibility semantics
+var Handled = errors.New("Event handled") // nolint: golint
+
+// ErrStopPropagation may be returned by a Handler to stop the dispatch, so that none of the remaining handlers of
+// the Event, its sub-Events and the remaining sub-Events of its parents are run. e.g. for "first matching handler
+// wins" routing or to short-circuit expensive downstream work. Like Handled, ErrStopPropagation is never treated as
+// an error, even if it's wrapped, e.g. by middleware. Since handlers are run concurrently by asynchronous
+// dispatches, ErrStopPropagation only affects synchronous dispatches.
+var ErrStopPropagation = errors.New("Event propagation stopped")
+
+// Option configures an Event. Options may be passed to New() and Event.New() along with the Event's Handlers.
+type Option func(*Event) error
+
+// StopOnHandled configures the Event to skip the remaining handlers once a handler returns Handled.
+// Handlers are run in the order in which they're added. Since handlers are run concurrently by asynchronous
+// dispatches, StopOnHandled only affects synchronous dispatches. Sub-Events are still dispatched.
+func StopOnHandled() Option {
+	return func(e *Event) error {
+		e.stopOnHandled = true
+		return nil
+	}
+}
+
+// splitOptions separates the Options from the Handlers
+func splitOptions(handlers []Handler) ([]Option, []Handler) {
+	var opts []Option
+	hs := make([]Handler, 0, len(handlers))
+	for _, h := range handlers {
+		if opt, ok := h.(Option); ok {
+			opts = append(opts, opt)
+			continue
+		}
+		hs = append(hs, h)
+	}
+	return opts, hs
+}
+
+// FailFastBehavior configures how a synchronous dispatch reacts to a handler error. Behaviors may be combined.
+type FailFastBehavior uint8
+
+const (
+	// SkipRemainingHandlers skips the Event's remaining handlers and sub-Events
+	SkipRemainingHandlers FailFastBehavior = 1 << iota
+	// SkipRemainingSiblings skips the sub-Events of the parent Event which haven't been dispatched yet
+	SkipRemainingSiblings
+	// PropagateError treats the failure as a failure of the parent Event, which then reacts according to its own
+	// FailFastBehavior
+	PropagateError
+)
+
+// FailFast configures how the Event reacts to one of its handlers erring or a sub-Event propagating a failure
+// during a synchronous dispatch. By default, all handlers and sub-Events are run regardless of errors.
+// Since handlers are run concurrently by asynchronous dispatches, FailFast only affects synchronous dispatches.
+func FailFast(behavior FailFastBehavior) Option {
+	return func(e *Event) error {
+		e.failFast = behavior
+		return nil
+	}
+}
+
+// Propagation specifies how a parent Event's data is propagated to a sub-Event's data field which is a pointer
+type Propagation uint8
+
+const (
+	// ByValue gives each sub-Event its own copy of the parent Event's data. Mutations made by the sub-Event's
+	// handlers aren't visible to other sub-Events. ByValue is the default.
+	ByValue Propagation = iota
+	// ByReference shares a single copy of the parent Event's data with every sub-Event of the same dispatch that
+	// propagates by reference. Mutations made by a sub-Event's handlers are visible to sub-Events dispatched
+	// afterwards. The dispatched data is never modified.
+	ByReference
+)
+
+// WithPropagation configures how a sub-Event receives the parent Event's data when the sub-Event's data field is a
+// pointer to the parent's data. Should only be used with Event.New()
+func WithPropagation(p Propagation) Option {
+	return func(e *Event) error {
+		e.propagation = p
+		return nil
+	}
+}
+
+// BestEffort marks the sub-Event as best-effort. e.g. notifications which should never fail the originating
+// operation. The results of a best-effort sub-Event's handlers and sub-Events aren't included in the results of the
+// dispatch, never cause the parent Event to fail fast and may not stop the dispatch. Dispatch errors of the
+// sub-Event are reported using DispatchFailed. If async is true, the sub-Event is dispatched asynchronously even
+// when the parent Event is dispatched synchronously. Should only be used with Event.New()
+func BestEffort(async bool) Option {
+	return func(e *Event) error {
+		e.bestEffort = true
+		e.bestEffortAsync = async
+		return nil
+	}
+}
+
+// DispatchOption configures a single dispatch
+type DispatchOption func(*dispatchState)
+
+// WithChildData dispatches the sub-Event with the given data instead of data created from the parent Event's data.
+// The sub-Event may be any descendant of the dispatched Event.
+func WithChildData(child *Event, data Data) DispatchOption {
+	return func(d *dispatchState) {
+		if d.childData == nil {
+			d.childData = map[*Event]interface{}{}
+		}
+		d.childData[child] = data
+	}
+}
+
+// WithBudget limits the time a dispatch may spend running the Event's handlers and its sub-Events' handlers, so a
+// slow optional sub-Event can't blow the latency budget of the rest of the dispatch. Should usually be used with
+// Event.New(). The context.Context passed to the handlers is canceled once the budget runs out, and the remaining
+// handlers and sub-Events are skipped during a synchronous dispatch.
+func WithBudget(budget time.Duration) Option {
+	return func(e *Event) error {
+		if budget <= 0 {
+			return TypeError{errors.New("Budget must be positive")}
+		}
+		e.budget = budget
+		return nil
+	}
+}
+
+type budgetCtxKey struct{}
+
+// withBudget derives a context.Context which is canceled once the Event's budget runs out. The returned function
+// must be called once the dispatch returns.
+func (e *Event) withBudget(ctx context.Context, async bool) (context.Context, func()) {
+	if e.budget <= 0 {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithTimeout(ctx, e.budget)
+	ctx = context.WithValue(ctx, budgetCtxKey{}, ctx)
+	if async {
+		// The handlers may still be running once the dispatch returns, so the context.Context is only released
+		// once the budget runs out
+		time.AfterFunc(e.budget, cancel)
+		return ctx, func() {}
+	}
+	return ctx, cancel
+}
+
+// budgetExceeded returns true if the budget of the Event or of an ancestor Event has run out
+func budgetExceeded(ctx context.Context) bool {
+	budgetCtx, ok := ctx.Value(budgetCtxKey{}).(context.Context)
+	return ok && budgetCtx.Err() != nil
+}
@@ -0,0 +1,489 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestStopOnHandled(t *testing.T) {
+	var called []string
+	first := func(ctx context.Context, i int) error { // nolint: unparam
+		called = append(called, "first")
+		return nil
+	}
+	consumer := func(ctx context.Context, i int) error {
+		called = append(called, "consumer")
+		if i > 0 {
+			return thevent.Handled
+		}
+		return errors.New("not handled")
+	}
+	last := func(ctx context.Context, i int) error { // nolint: unparam
+		called = append(called, "last")
+		return nil
+	}
+
+	testCases := []struct {
+		name             string
+		stopOnHandled    bool
+		data             int
+		expectedCalled   []string
+		expectedHandlers uint
+		expectedErrors   int
+	}{
+		{name: "handled - stop", stopOnHandled: true, data: 1, expectedCalled: []string{"first", "consumer"},
+			expectedHandlers: 2},
+		{name: "not handled - stop", stopOnHandled: true, data: 0,
+			expectedCalled: []string{"first", "consumer", "last"}, expectedHandlers: 3, expectedErrors: 1},
+		{name: "handled - no stop", data: 1, expectedCalled: []string{"first", "consumer", "last"},
+			expectedHandlers: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handlers := []thevent.Handler{first, consumer, last}
+			if tc.stopOnHandled {
+				handlers = append(handlers, thevent.StopOnHandled())
+			}
+			e, err := thevent.New(0, handlers...)
+			if err != nil {
+				t.Fatal("Unable to create event:", err)
+			}
+			called = nil
+			res, err := e.DispatchWithResults(context.Background(), tc.data)
+			if err != nil {
+				t.Fatal("Unexpected error dispatching:", err)
+			}
+			if len(called) != len(tc.expectedCalled) {
+				t.Fatal("Called handlers:", called, "expected:", tc.expectedCalled)
+			}
+			for i := range called {
+				if called[i] != tc.expectedCalled[i] {
+					t.Error("Called handlers:", called, "expected:", tc.expectedCalled)
+				}
+			}
+			if res.NumHandlers != tc.expectedHandlers {
+				t.Error("Expected", tc.expectedHandlers, "handlers to be dispatched, not", res.NumHandlers)
+			}
+			if len(res.Errors) != tc.expectedErrors {
+				t.Error("Expected", tc.expectedErrors, "errors, instead have errors:", res.Errors)
+			}
+		})
+	}
+}
+
+func TestStopOnHandledSubEvent(t *testing.T) {
+	consumer := func(ctx context.Context, s testStruct) error { return thevent.Handled }
+	childCalled := false
+	child := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		childCalled = true
+		return nil
+	}
+	e, err := thevent.New(testStruct{}, consumer, thevent.StopOnHandled())
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if _, err := e.New(testStruct{}, "", child); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if err := e.Dispatch(context.Background(), testStruct{}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if !childCalled {
+		t.Error("Sub-Event should still be dispatched after the event is handled")
+	}
+}
+
+func TestFailFast(t *testing.T) {
+	var called []string
+	handler := func(name string, fail bool) func(context.Context, testStruct) error {
+		return func(ctx context.Context, s testStruct) error {
+			called = append(called, name)
+			if fail {
+				return errors.New(name + " failed")
+			}
+			return nil
+		}
+	}
+	// closures created by the same function share a code pointer and would be detected as duplicate handlers
+	a2 := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		called = append(called, "a2")
+		return nil
+	}
+
+	testCases := []struct {
+		name           string
+		rootOpts       []thevent.Handler
+		childOpts      []thevent.Handler
+		expectedCalled []string
+	}{
+		{name: "no fail fast",
+			expectedCalled: []string{"root", "a1", "a2", "aa", "b", "c"}},
+		{name: "skip remaining handlers",
+			childOpts:      []thevent.Handler{thevent.FailFast(thevent.SkipRemainingHandlers)},
+			expectedCalled: []string{"root", "a1", "b", "c"}},
+		{name: "skip remaining siblings",
+			childOpts:      []thevent.Handler{thevent.FailFast(thevent.SkipRemainingSiblings)},
+			expectedCalled: []string{"root", "a1", "a2", "aa"}},
+		{name: "skip remaining handlers and siblings",
+			childOpts: []thevent.Handler{
+				thevent.FailFast(thevent.SkipRemainingHandlers | thevent.SkipRemainingSiblings)},
+			expectedCalled: []string{"root", "a1"}},
+		{name: "propagate error - parent doesn't fail fast",
+			childOpts:      []thevent.Handler{thevent.FailFast(thevent.PropagateError)},
+			expectedCalled: []string{"root", "a1", "a2", "aa", "b", "c"}},
+		{name: "propagate error - parent skips remaining handlers",
+			rootOpts:       []thevent.Handler{thevent.FailFast(thevent.SkipRemainingHandlers)},
+			childOpts:      []thevent.Handler{thevent.FailFast(thevent.PropagateError)},
+			expectedCalled: []string{"root", "a1", "a2", "aa"}},
+		{name: "no propagation - parent skips remaining handlers",
+			rootOpts:       []thevent.Handler{thevent.FailFast(thevent.SkipRemainingHandlers)},
+			childOpts:      []thevent.Handler{thevent.FailFast(thevent.SkipRemainingHandlers)},
+			expectedCalled: []string{"root", "a1", "b", "c"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root, err := thevent.New(testStruct{}, append(tc.rootOpts, handler("root", false))...)
+			if err != nil {
+				t.Fatal("Unable to create event:", err)
+			}
+			a, err := root.New(testStruct{}, "", append(tc.childOpts, handler("a1", true), a2)...)
+			if err != nil {
+				t.Fatal("Unable to create sub-Event:", err)
+			}
+			if _, err := a.New(testStruct{}, "", handler("aa", false)); err != nil {
+				t.Fatal("Unable to create sub-Event:", err)
+			}
+			if _, err := root.New(testStruct{}, "", handler("b", false)); err != nil {
+				t.Fatal("Unable to create sub-Event:", err)
+			}
+			if _, err := root.New(testStruct{}, "", handler("c", false)); err != nil {
+				t.Fatal("Unable to create sub-Event:", err)
+			}
+
+			called = nil
+			if err := root.Dispatch(context.Background(), testStruct{}); err != nil {
+				t.Fatal("Unexpected error dispatching:", err)
+			}
+			if len(called) != len(tc.expectedCalled) {
+				t.Fatal("Called handlers:", called, "expected:", tc.expectedCalled)
+			}
+			for i := range called {
+				if called[i] != tc.expectedCalled[i] {
+					t.Fatal("Called handlers:", called, "expected:", tc.expectedCalled)
+				}
+			}
+		})
+	}
+}
+
+func TestWithPropagation(t *testing.T) {
+	type parentData struct{ V int }
+	type childData struct{ Parent *parentData }
+	type childValueData struct{ Parent parentData }
+
+	testCases := []struct {
+		name     string
+		prop     thevent.Propagation
+		expected []int
+	}{
+		{name: "by value", prop: thevent.ByValue, expected: []int{1, 1}},
+		{name: "by reference", prop: thevent.ByReference, expected: []int{1, 2}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var seen []int
+			incr := func(ctx context.Context, d childData) error { // nolint: unparam
+				d.Parent.V++
+				seen = append(seen, d.Parent.V)
+				return nil
+			}
+			incr2 := func(ctx context.Context, d childData) error { // nolint: unparam
+				d.Parent.V++
+				seen = append(seen, d.Parent.V)
+				return nil
+			}
+			parentHandler := func(ctx context.Context, d parentData) error { // nolint: unparam
+				seen = append(seen, d.V)
+				return nil
+			}
+			e, err := thevent.New(parentData{})
+			if err != nil {
+				t.Fatal("Unable to create event:", err)
+			}
+			if _, err := e.New(childData{}, "Parent", incr, thevent.WithPropagation(tc.prop)); err != nil {
+				t.Fatal("Unable to create sub-Event:", err)
+			}
+			if _, err := e.New(childData{}, "Parent", incr2, thevent.WithPropagation(tc.prop)); err != nil {
+				t.Fatal("Unable to create sub-Event:", err)
+			}
+			if _, err := e.New(parentData{}, "", parentHandler); err != nil {
+				t.Fatal("Unable to create sub-Event:", err)
+			}
+
+			data := parentData{}
+			if err := e.Dispatch(context.Background(), data); err != nil {
+				t.Fatal("Unexpected error dispatching:", err)
+			}
+			if len(seen) != 3 || seen[0] != tc.expected[0] || seen[1] != tc.expected[1] || seen[2] != 0 {
+				t.Error("Handlers saw:", seen, "expected:", append(tc.expected, 0))
+			}
+		})
+	}
+
+	t.Run("by reference requires pointer field", func(t *testing.T) {
+		e, err := thevent.New(parentData{})
+		if err != nil {
+			t.Fatal("Unable to create event:", err)
+		}
+		_, err = e.New(childValueData{}, "Parent", thevent.WithPropagation(thevent.ByReference))
+		errorMatchesGlob(t, err, "Propagating by reference requires a field which is a pointer to the parent's data")
+		_, err = e.New(parentData{}, "", thevent.WithPropagation(thevent.ByReference))
+		errorMatchesGlob(t, err, "Propagating by reference requires a field which is a pointer to the parent's data")
+	})
+}
+
+func TestWithChildData(t *testing.T) {
+	type childData struct{ Parent testStruct }
+	var got []testStruct
+	childHandler := func(ctx context.Context, d childData) error { // nolint: unparam
+		got = append(got, d.Parent)
+		return nil
+	}
+	grandChildHandler := func(ctx context.Context, d childData) error { // nolint: unparam
+		got = append(got, d.Parent)
+		return nil
+	}
+	e, err := thevent.New(testStruct{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	child, err := e.New(childData{}, "Parent", childHandler)
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	grandChild, err := child.New(childData{}, "", grandChildHandler)
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+
+	ctx := context.Background()
+	testCases := []struct {
+		name      string
+		opts      []thevent.DispatchOption
+		expected  []testStruct
+		errorGlob string
+	}{
+		{name: "no override", expected: []testStruct{{v: 1}, {v: 1}}},
+		{name: "override child", opts: []thevent.DispatchOption{
+			thevent.WithChildData(child, childData{Parent: testStruct{v: 2}})},
+			expected: []testStruct{{v: 2}, {v: 2}}},
+		{name: "override grandchild", opts: []thevent.DispatchOption{
+			thevent.WithChildData(grandChild, childData{Parent: testStruct{v: 3}})},
+			expected: []testStruct{{v: 1}, {v: 3}}},
+		{name: "override with wrong data type", opts: []thevent.DispatchOption{thevent.WithChildData(child, 5)},
+			errorGlob: "MultiTypeError: *Dispatch called with incorrect event data type. Expected: * Got: int*"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			err := e.Dispatch(ctx, testStruct{v: 1}, tc.opts...)
+			errorMatchesGlob(t, err, tc.errorGlob)
+			if len(got) != len(tc.expected) {
+				t.Fatal("Handlers got:", got, "expected:", tc.expected)
+			}
+			for i := range got {
+				if got[i] != tc.expected[i] {
+					t.Error("Handlers got:", got, "expected:", tc.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestWithBudget(t *testing.T) {
+	_, err := thevent.New(testStruct{}, thevent.WithBudget(0))
+	errorMatchesGlob(t, err, "Budget must be positive")
+
+	var called []string
+	slow := func(ctx context.Context, s testStruct) error {
+		called = append(called, "slow")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	}
+	skipped := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		called = append(called, "skipped")
+		return nil
+	}
+	skippedChild := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		called = append(called, "skipped child")
+		return nil
+	}
+	sibling := func(ctx context.Context, s testStruct) error {
+		called = append(called, "sibling")
+		return ctx.Err()
+	}
+
+	e := thevent.Must(thevent.New(testStruct{}))
+	optional := thevent.Must(e.New(testStruct{}, "", slow, skipped, thevent.WithBudget(10*time.Millisecond)))
+	thevent.Must(optional.New(testStruct{}, "", skippedChild))
+	thevent.Must(e.New(testStruct{}, "", sibling))
+
+	start := time.Now()
+	res, err := e.DispatchWithResults(context.Background(), testStruct{})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Error("Budget wasn't enforced. Dispatch took:", d)
+	}
+	if len(called) != 2 || called[0] != "slow" || called[1] != "sibling" {
+		t.Error("Called handlers:", called)
+	}
+	if len(res.Errors) != 1 || res.Errors[0] != context.DeadlineExceeded {
+		t.Error("Unexpected errors:", res.Errors)
+	}
+}
+
+func TestBestEffort(t *testing.T) {
+	testCases := []struct {
+		name  string
+		async bool
+	}{
+		{name: "sync"},
+		{name: "async", async: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var lock sync.Mutex
+			var called []string
+			record := func(s string) {
+				lock.Lock()
+				defer lock.Unlock()
+				called = append(called, s)
+			}
+			notify := func(ctx context.Context, s testStruct) error {
+				record("notify")
+				return errors.New("notification failed")
+			}
+			critical := func(ctx context.Context, s testStruct) error { // nolint: unparam
+				record("critical")
+				return nil
+			}
+			e := thevent.Must(thevent.New(testStruct{},
+				thevent.FailFast(thevent.SkipRemainingHandlers|thevent.SkipRemainingSiblings)))
+			notifications := thevent.Must(e.New(testStruct{}, "", notify, thevent.BestEffort(tc.async),
+				thevent.FailFast(thevent.SkipRemainingSiblings|thevent.PropagateError)))
+			thevent.Must(e.New(testStruct{}, "", critical))
+
+			res, err := e.DispatchWithResults(context.Background(), testStruct{})
+			if err != nil {
+				t.Fatal("Unexpected error dispatching:", err)
+			}
+			if err := notifications.Barrier(context.Background()); err != nil {
+				t.Fatal("Unexpected error waiting for handlers:", err)
+			}
+			if res.NumHandlers != 1 || res.Erred() {
+				t.Error("Best-effort results shouldn't be included. Results:", res)
+			}
+			sort.Strings(called)
+			if len(called) != 2 || called[0] != "critical" || called[1] != "notify" {
+				t.Error("Called handlers:", called)
+			}
+		})
+	}
+}
+
+func TestErrStopPropagation(t *testing.T) {
+	var called []string
+	parent := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		called = append(called, "parent")
+		return nil
+	}
+	router := func(ctx context.Context, s testStruct) error {
+		called = append(called, "router")
+		if s.v > 0 {
+			return thevent.ErrStopPropagation
+		}
+		return nil
+	}
+	fallback := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		called = append(called, "fallback")
+		return nil
+	}
+	sibling := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		called = append(called, "sibling")
+		return nil
+	}
+	e, err := thevent.New(testStruct{}, parent)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if _, err := e.New(testStruct{}, "", router, fallback); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if _, err := e.New(testStruct{}, "", sibling); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+
+	ctx := context.Background()
+	res, err := e.DispatchWithResults(ctx, testStruct{v: 1})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if res.NumHandlers != 2 || len(res.Errors) != 0 {
+		t.Error("Unexpected results:", res)
+	}
+	if len(called) != 2 || called[0] != "parent" || called[1] != "router" {
+		t.Error("Unexpected handlers called:", called)
+	}
+
+	called = nil
+	if err := e.Dispatch(ctx, testStruct{}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(called) != 4 {
+		t.Error("Unexpected handlers called:", called)
+	}
+}
+
+func TestErrStopPropagationWrapped(t *testing.T) {
+	var called []string
+	router := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		called = append(called, "router")
+		return fmt.Errorf("routed: %w", thevent.ErrStopPropagation)
+	}
+	fallback := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		called = append(called, "fallback")
+		return nil
+	}
+	e, err := thevent.New(testStruct{}, router, fallback)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+
+	res, err := e.DispatchWithResults(context.Background(), testStruct{})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if res.NumHandlers != 1 || len(res.Errors) != 0 {
+		t.Error("Unexpected results:", res)
+	}
+	if len(called) != 1 || called[0] != "router" {
+		t.Error("Unexpected handlers called:", called)
+	}
+}
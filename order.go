@@ -0,0 +1,113 @@
+package thevent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WithName names the handler, so that other handlers may be ordered relative to it using After(), and so that the
+// handler may be identified by its name in its HandlerResults, HandlerCalls and HandlerErrors. Handler names must
+// be unique within an Event.
+func WithName(name string) HandlerOption {
+	return func(h *handler) error {
+		if name == "" {
+			return TypeError{errors.New("Handler name must not be empty")}
+		}
+		h.name = name
+		return nil
+	}
+}
+
+// After runs the handler after the named handlers of the Event during synchronous dispatches, e.g. to only send an
+// email after the handler writing to the database ran. Handlers are otherwise run by their priority, see
+// AddHandlersWithPriority(), and in the order in which they're added. Names which aren't used by any of the
+// Event's handlers are ignored until a handler with the name is added.
+// Adding a handler which would make the order cyclic fails. Asynchronously run handlers aren't ordered.
+func After(names ...string) HandlerOption {
+	return func(h *handler) error {
+		for _, name := range names {
+			if name == "" {
+				return TypeError{errors.New("Handler name must not be empty")}
+			}
+		}
+		h.after = append(h.after, names...)
+		return nil
+	}
+}
+
+// AddHandlersWithPriority adds the Handlers to the Event with the given priority. During synchronous dispatches,
+// handlers with a higher priority are run first, e.g. so audit handlers run before business handlers. Handlers are
+// added with priority 0 by default and handlers with the same priority are run in the order in which they're added.
+// Ordering constraints declared using After() take precedence over priorities.
+func (e *Event) AddHandlersWithPriority(priority int, handlers ...Handler) error {
+	return e.addHandlersWithPriority(nil, priority, handlers)
+}
+
+// addHandlersWithPriority adds the handlers with the priority. owner is the Capability used to add the handlers, if
+// any.
+func (e *Event) addHandlersWithPriority(owner *Capability, priority int, handlers []Handler) error {
+	convertedHandlers, err := e.convertHandlers(handlers)
+	if err != nil {
+		return err
+	}
+	for _, cH := range convertedHandlers {
+		cH.priority = priority
+	}
+	return e.addHandlers(owner, convertedHandlers)
+}
+
+// orderHandlers orders the handlers so that every handler is run after the handlers it's declared to run after, and
+// otherwise by their priority. Handlers with the same priority keep their registration order. The handlers are
+// returned as is if none are constrained or prioritized.
+func orderHandlers(handlers []*handler) ([]*handler, error) {
+	byName := map[string]int{}
+	constrained := false
+	for i, h := range handlers {
+		if h.name != "" {
+			if _, ok := byName[h.name]; ok {
+				return nil, TypeError{fmt.Errorf("Duplicate handler name: %s", h.name)}
+			}
+			byName[h.name] = i
+		}
+		constrained = constrained || len(h.after) > 0 || h.priority != 0
+	}
+	if !constrained {
+		return handlers, nil
+	}
+
+	ordered := make([]*handler, 0, len(handlers))
+	placed := make([]bool, len(handlers))
+	for len(ordered) < len(handlers) {
+		// Place the earliest registered handler with the highest priority whose predecessors have all been placed
+		next := -1
+		for i, h := range handlers {
+			if placed[i] || next >= 0 && h.priority <= handlers[next].priority {
+				continue
+			}
+			ready := true
+			for _, name := range h.after {
+				if j, ok := byName[name]; ok && !placed[j] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				next = i
+			}
+		}
+		if next < 0 {
+			var cyclic []string
+			for i, h := range handlers {
+				if !placed[i] && h.name != "" {
+					cyclic = append(cyclic, h.name)
+				}
+			}
+			return nil, TypeError{fmt.Errorf("Cyclic handler ordering between handlers: %s",
+				strings.Join(cyclic, ", "))}
+		}
+		placed[next] = true
+		ordered = append(ordered, handlers[next])
+	}
+	return ordered, nil
+}
@@ -0,0 +1,132 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestHandlerOrdering(t *testing.T) {
+	var order []string
+	e := thevent.Must(thevent.New(0))
+	handlers := []struct {
+		handler thevent.Handler
+		opts    []thevent.HandlerOption
+	}{
+		{handler: func(ctx context.Context, i int) error { // nolint: unparam
+			order = append(order, "email")
+			return nil
+		}, opts: []thevent.HandlerOption{thevent.WithName("email"), thevent.After("persist")}},
+		{handler: func(ctx context.Context, i int) error { // nolint: unparam
+			order = append(order, "audit")
+			return nil
+		}, opts: []thevent.HandlerOption{thevent.After("email", "persist")}},
+		{handler: func(ctx context.Context, i int) error { // nolint: unparam
+			order = append(order, "metrics")
+			return nil
+		}},
+		{handler: func(ctx context.Context, i int) error { // nolint: unparam
+			order = append(order, "persist")
+			return nil
+		}, opts: []thevent.HandlerOption{thevent.WithName("persist"), thevent.After("validate")}},
+	}
+	for _, h := range handlers {
+		if err := e.AddHandlerWithOptions(h.handler, h.opts...); err != nil {
+			t.Fatal("Unable to add handler:", err)
+		}
+	}
+	if err := e.Dispatch(context.Background(), 1); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	expected := []string{"metrics", "persist", "email", "audit"}
+	if len(order) != len(expected) {
+		t.Fatal("Got order:", order, "instead of:", expected)
+	}
+	for i := range order {
+		if order[i] != expected[i] {
+			t.Fatal("Got order:", order, "instead of:", expected)
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		opts        []thevent.HandlerOption
+		expectedErr string
+	}{
+		{name: "empty name", opts: []thevent.HandlerOption{thevent.WithName("")},
+			expectedErr: "Handler name must not be empty"},
+		{name: "empty after", opts: []thevent.HandlerOption{thevent.After("")},
+			expectedErr: "Handler name must not be empty"},
+		{name: "duplicate name", opts: []thevent.HandlerOption{thevent.WithName("email")},
+			expectedErr: "Duplicate handler name: email"},
+		{name: "cycle", opts: []thevent.HandlerOption{thevent.WithName("validate"), thevent.After("email")},
+			expectedErr: "Cyclic handler ordering between handlers: persist, email, validate"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := e.AddHandlerWithOptions(func(ctx context.Context, i int) error { return nil }, tc.opts...)
+			errorMatchesGlob(t, err, tc.expectedErr)
+		})
+	}
+	// Failing to add a handler doesn't change the order
+	order = nil
+	if err := e.Dispatch(context.Background(), 1); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(order) != len(expected) {
+		t.Error("Got order:", order, "instead of:", expected)
+	}
+}
+
+func TestAddHandlersWithPriority(t *testing.T) {
+	var order []string
+	e := thevent.Must(thevent.New(0, func(ctx context.Context, i int) error { // nolint: unparam
+		order = append(order, "business")
+		return nil
+	}))
+	if err := e.AddHandlersWithPriority(-1, func(ctx context.Context, i int) error { // nolint: unparam
+		order = append(order, "metrics")
+		return nil
+	}); err != nil {
+		t.Fatal("Unable to add handlers:", err)
+	}
+	if err := e.AddHandlersWithPriority(10, func(ctx context.Context, i int) error { // nolint: unparam
+		order = append(order, "audit")
+		return nil
+	}, func(ctx context.Context, i int) error { // nolint: unparam
+		order = append(order, "log")
+		return nil
+	}); err != nil {
+		t.Fatal("Unable to add handlers:", err)
+	}
+	// Ordering constraints take precedence over priorities
+	if err := e.AddHandlerWithOptions(func(ctx context.Context, i int) error { // nolint: unparam
+		order = append(order, "validate")
+		return nil
+	}, thevent.WithName("validate")); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	if err := e.AddHandlersWithPriority(20, func(ctx context.Context, i int) error { // nolint: unparam
+		order = append(order, "ack")
+		return nil
+	}); err != nil {
+		t.Fatal("Unable to add handlers:", err)
+	}
+	if err := e.Dispatch(context.Background(), 1); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	expected := []string{"ack", "audit", "log", "business", "validate", "metrics"}
+	if len(order) != len(expected) {
+		t.Fatal("Got order:", order, "instead of:", expected)
+	}
+	for i := range order {
+		if order[i] != expected[i] {
+			t.Fatal("Got order:", order, "instead of:", expected)
+		}
+	}
+	errorMatchesGlob(t, e.AddHandlersWithPriority(1, func(ctx context.Context, s string) error { return nil }),
+		"Handler uses incorrect data type.*")
+}
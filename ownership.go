@@ -0,0 +1,131 @@
+package thevent
+
+import (
+	"errors"
+)
+
+// ErrNotOwner is returned when mutating an owned Event without its Capability. See OwnedBy()
+var ErrNotOwner = errors.New("Event is owned and may only be mutated by its owner")
+
+// Capability grants mutating the Events it owns. Capabilities are compared by identity, so a Capability can't be
+// forged by packages it isn't shared with.
+type Capability struct {
+	// id makes every Capability distinct, since pointers to zero-size values may be equal
+	id byte
+}
+
+// NewCapability creates a new Capability
+func NewCapability() *Capability {
+	return &Capability{}
+}
+
+// OwnedBy configures the Event to be owned by the Capability, so that other packages can't tamper with its wiring.
+// Adding or removing handlers of an owned Event, creating or remapping its sub-Events and destroying it require the
+// Capability and are done using the OwnedEvent returned by Event.Owned(). Mutating an owned Event directly fails
+// with ErrNotOwner, and Destroy() is ignored. Dispatching an owned Event isn't restricted. The owner may open the
+// Event to anyone using OwnedEvent.Open().
+func OwnedBy(c *Capability) Option {
+	return func(e *Event) error {
+		if c == nil {
+			return TypeError{errors.New("Capability must not be nil")}
+		}
+		e.owner = c
+		return nil
+	}
+}
+
+// OwnedEvent is an Event being mutated by its owner. See OwnedBy()
+type OwnedEvent struct {
+	*Event
+	capability *Capability
+}
+
+// Owned gets the Event for mutating it using the Capability. ErrNotOwner is returned if the Event is owned by
+// another Capability. Events which aren't owned may be mutated using any Capability.
+func (e *Event) Owned(c *Capability) (*OwnedEvent, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	if err := e.checkOwner(c); err != nil {
+		return nil, err
+	}
+	return &OwnedEvent{Event: e, capability: c}, nil
+}
+
+// checkOwner returns ErrNotOwner if the Event may not be mutated using the Capability. The Event's lock must be
+// held.
+func (e *Event) checkOwner(c *Capability) error {
+	if e.owner != nil && e.owner != c {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+// Open allows anyone to mutate the Event, which is no longer owned
+func (o *OwnedEvent) Open() {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.owner = nil
+}
+
+// AddHandlers is the same as Event.AddHandlers
+func (o *OwnedEvent) AddHandlers(handlers ...Handler) error {
+	convertedHandlers, err := o.convertHandlers(handlers)
+	if err != nil {
+		return err
+	}
+	return o.addHandlers(o.capability, convertedHandlers)
+}
+
+// AddHandlersWithPriority is the same as Event.AddHandlersWithPriority
+func (o *OwnedEvent) AddHandlersWithPriority(priority int, handlers ...Handler) error {
+	return o.addHandlersWithPriority(o.capability, priority, handlers)
+}
+
+// AddHandlerWithOptions is the same as Event.AddHandlerWithOptions
+func (o *OwnedEvent) AddHandlerWithOptions(h Handler, opts ...HandlerOption) error {
+	cH, err := o.newHandlerWithOptions(h, opts)
+	if err != nil {
+		return err
+	}
+	return o.addHandlers(o.capability, []*handler{cH})
+}
+
+// AddNamedHandler is the same as Event.AddNamedHandler
+func (o *OwnedEvent) AddNamedHandler(name string, h Handler, opts ...HandlerOption) error {
+	return o.AddHandlerWithOptions(h, append([]HandlerOption{WithName(name)}, opts...)...)
+}
+
+// Use is the same as Event.Use
+func (o *OwnedEvent) Use(middlewares ...Middleware) error {
+	return o.use(o.capability, middlewares)
+}
+
+// RemoveHandlers is the same as Event.RemoveHandlers
+func (o *OwnedEvent) RemoveHandlers(handlers ...Handler) error {
+	return o.removeHandlers(o.capability, handlers)
+}
+
+// Subscribe is the same as Event.Subscribe
+func (o *OwnedEvent) Subscribe(h Handler, opts ...HandlerOption) (*Subscription, error) {
+	return o.subscribe(o.capability, h, opts)
+}
+
+// New is the same as Event.New
+func (o *OwnedEvent) New(data interface{}, fieldName string, handlers ...Handler) (*Event, error) {
+	return o.newChild(o.capability, data, fieldName, handlers)
+}
+
+// NewVersion is the same as Event.NewVersion
+func (o *OwnedEvent) NewVersion(data interface{}, convert interface{}, handlers ...Handler) (*Event, error) {
+	return o.newVersion(o.capability, data, convert, handlers)
+}
+
+// RemapChild is the same as Event.RemapChild
+func (o *OwnedEvent) RemapChild(child *Event, fieldName string) error {
+	return o.remapChild(o.capability, child, fieldName)
+}
+
+// Destroy is the same as Event.Destroy
+func (o *OwnedEvent) Destroy() {
+	o.destroyOwned(o.capability)
+}
@@ -0,0 +1,85 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestOwnedBy(t *testing.T) {
+	if _, err := thevent.New(testStruct{}, thevent.OwnedBy(nil)); err == nil {
+		t.Error("Created event owned by a nil Capability")
+	}
+
+	called := 0
+	handler := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		called++
+		return nil
+	}
+	other := func(ctx context.Context, s testStruct) error { return nil }
+	c := thevent.NewCapability()
+	// The handlers passed to New() are added by the owner
+	e := thevent.Must(thevent.New(testStruct{}, handler, thevent.OwnedBy(c)))
+	sub := thevent.Must(thevent.New(testStruct{}))
+
+	testCases := []struct {
+		name   string
+		mutate func() error
+	}{
+		{name: "AddHandlers", mutate: func() error { return e.AddHandlers(other) }},
+		{name: "AddHandlerWithOptions", mutate: func() error { return e.AddHandlerWithOptions(other) }},
+		{name: "New", mutate: func() error {
+			_, err := e.New(testStruct{}, "")
+			return err
+		}},
+		{name: "NewVersion", mutate: func() error {
+			_, err := e.NewVersion(0, func(testStruct) int { return 0 })
+			return err
+		}},
+		{name: "RemapChild", mutate: func() error { return e.RemapChild(sub, "") }},
+		{name: "Owned", mutate: func() error {
+			_, err := e.Owned(thevent.NewCapability())
+			return err
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.mutate(); err != thevent.ErrNotOwner {
+				t.Error("Expected ErrNotOwner, got:", err)
+			}
+		})
+	}
+	e.Destroy()
+
+	owned, err := e.Owned(c)
+	if err != nil {
+		t.Fatal("Unable to get owned event:", err)
+	}
+	if err := owned.AddHandlers(func(ctx context.Context, s testStruct) error { // nolint: unparam
+		called++
+		return nil
+	}); err != nil {
+		t.Fatal("Unable to add handler as the owner:", err)
+	}
+	if _, err := owned.New(testStruct{}, "", handler); err != nil {
+		t.Fatal("Unable to create sub-Event as the owner:", err)
+	}
+	if err := e.Dispatch(context.Background(), testStruct{}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if called != 3 {
+		t.Error("Expected the handlers added by the owner to be called, called:", called)
+	}
+
+	owned.Open()
+	if err := e.AddHandlerWithOptions(func(ctx context.Context, s testStruct) error { return nil }); err != nil {
+		t.Error("Unable to add handler to an opened event:", err)
+	}
+	e.Destroy()
+	if err := e.Dispatch(context.Background(), testStruct{}); err != thevent.ErrDestroyed {
+		t.Error("Expected opened event to be destroyed, got:", err)
+	}
+}
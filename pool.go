@@ -0,0 +1,154 @@
+package thevent
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// poolTask is the work of an asynchronously run handler queued on a WorkerPool
+type poolTask struct {
+	run      func()
+	queuedAt time.Time
+}
+
+// WorkerPool runs the asynchronously run handlers of the Events using it on a fixed number of workers, instead of
+// starting a goroutine for every handler, e.g. to bound the concurrency of handlers shared by many Events. When
+// handlers are queued, the handlers of Events with a higher Priority are run first: Critical, then Normal, then
+// Optional. Queued handlers are aged, so handlers of lower Priority Events aren't starved. Handlers of Events with
+// the same Priority are run in the order in which they were queued. See WithWorkerPool()
+//
+// Handlers run by a WorkerPool must not wait on other asynchronously run handlers using the WorkerPool, since all of
+// the workers may be waiting. Handlers with ordered delivery aren't run by the WorkerPool.
+type WorkerPool struct {
+	lock  sync.Mutex
+	cond  *sync.Cond
+	aging time.Duration
+	// queues holds the queued tasks of each Priority, indexed by rank
+	queues  [3][]poolTask
+	queued  int
+	closed  bool
+	workers sync.WaitGroup
+	now     func() time.Time
+}
+
+// NewWorkerPool creates a new WorkerPool with the given number of workers. A queued handler is promoted by one
+// Priority for every aging interval it waits for, e.g. an Optional Event's handler waiting for 2 aging intervals is
+// run before Critical Events' handlers queued since. If aging is 0, queued handlers aren't aged.
+func NewWorkerPool(workers int, aging time.Duration) (*WorkerPool, error) {
+	if workers <= 0 {
+		return nil, TypeError{errors.New("Number of workers must be positive")}
+	}
+	if aging < 0 {
+		return nil, TypeError{errors.New("Aging interval must not be negative")}
+	}
+	p := &WorkerPool{aging: aging, now: time.Now}
+	p.cond = sync.NewCond(&p.lock)
+	p.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p, nil
+}
+
+// WithWorkerPool configures the Event to run its asynchronously run handlers on the WorkerPool, scheduled by the
+// Event's Priority. The same WorkerPool may be used by many Events.
+func WithWorkerPool(p *WorkerPool) Option {
+	return func(e *Event) error {
+		if p == nil {
+			return TypeError{errors.New("WorkerPool must not be nil")}
+		}
+		e.pool = p
+		return nil
+	}
+}
+
+// rank orders the Priorities from the lowest to the highest
+func rank(p Priority) int {
+	switch p {
+	case Optional:
+		return 0
+	case Critical:
+		return 2
+	}
+	return 1
+}
+
+// submit queues the task. false is returned if the WorkerPool has been closed.
+func (p *WorkerPool) submit(priority Priority, run func()) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.closed {
+		return false
+	}
+	r := rank(priority)
+	p.queues[r] = append(p.queues[r], poolTask{run: run, queuedAt: p.now()})
+	p.queued++
+	p.cond.Signal()
+	return true
+}
+
+// next dequeues the task with the highest aged Priority, waiting for a task to be queued. false is returned once
+// the WorkerPool has been closed and all of the queued tasks have been dequeued.
+func (p *WorkerPool) next() (poolTask, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for p.queued == 0 {
+		if p.closed {
+			return poolTask{}, false
+		}
+		p.cond.Wait()
+	}
+	now := p.now()
+	best := -1
+	var bestRank int
+	// The oldest task of each Priority has aged the most, so only the heads of the queues are compared
+	for r, q := range p.queues {
+		if len(q) == 0 {
+			continue
+		}
+		aged := r
+		if p.aging > 0 {
+			aged += int(now.Sub(q[0].queuedAt) / p.aging)
+		}
+		if best < 0 || aged > bestRank || aged == bestRank && q[0].queuedAt.Before(p.queues[best][0].queuedAt) {
+			best, bestRank = r, aged
+		}
+	}
+	t := p.queues[best][0]
+	p.queues[best][0] = poolTask{}
+	p.queues[best] = p.queues[best][1:]
+	p.queued--
+	return t, true
+}
+
+func (p *WorkerPool) work() {
+	defer p.workers.Done()
+	for {
+		t, ok := p.next()
+		if !ok {
+			return
+		}
+		t.run()
+	}
+}
+
+// Close stops the WorkerPool once the queued handlers have run, and waits for them to finish. Handlers of Events
+// using a closed WorkerPool are run in their own goroutines.
+func (p *WorkerPool) Close() {
+	p.lock.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.lock.Unlock()
+	p.workers.Wait()
+}
+
+// goAsync runs the asynchronously run handler's work on the Event's WorkerPool, if any, or in a new goroutine.
+// Handlers with ordered delivery always run in a new goroutine, since waiting for their turn could block all of
+// the workers.
+func (e *Event) goAsync(h *handler, run func()) {
+	if e.pool != nil && h.ordered == nil && e.pool.submit(e.priority, run) {
+		return
+	}
+	go run()
+}
@@ -0,0 +1,99 @@
+package thevent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolScheduling(t *testing.T) {
+	now := time.Now()
+	// The WorkerPool has no workers, so the tasks are only dequeued by the test
+	p := &WorkerPool{aging: time.Minute, now: func() time.Time { return now }}
+	p.cond = sync.NewCond(&p.lock)
+	var order []string
+	queue := func(name string, priority Priority) {
+		p.submit(priority, func() { order = append(order, name) })
+		now = now.Add(time.Second)
+	}
+	queue("optional", Optional)
+	queue("normal", Normal)
+	queue("critical", Critical)
+	run := func() {
+		for p.queued > 0 {
+			task, _ := p.next()
+			task.run()
+		}
+	}
+	run()
+	// The optional task is promoted past critical tasks once it's waited for 2 aging intervals
+	queue("aged optional", Optional)
+	now = now.Add(2 * time.Minute)
+	queue("critical 2", Critical)
+	queue("normal 2", Normal)
+	run()
+	expected := []string{"critical", "normal", "optional", "aged optional", "critical 2", "normal 2"}
+	if len(order) != len(expected) {
+		t.Fatal("Got order:", order, "instead of:", expected)
+	}
+	for i := range order {
+		if order[i] != expected[i] {
+			t.Fatal("Got order:", order, "instead of:", expected)
+		}
+	}
+}
+
+func TestWithWorkerPool(t *testing.T) {
+	if _, err := NewWorkerPool(0, 0); err == nil {
+		t.Error("Created WorkerPool without workers")
+	}
+	if _, err := NewWorkerPool(1, -1); err == nil {
+		t.Error("Created WorkerPool with a negative aging interval")
+	}
+	if _, err := New(0, WithWorkerPool(nil)); err == nil {
+		t.Error("Created event with a nil WorkerPool")
+	}
+
+	p, err := NewWorkerPool(2, time.Second)
+	if err != nil {
+		t.Fatal("Unable to create WorkerPool:", err)
+	}
+	var lock sync.Mutex
+	running, maxRunning, handled := 0, 0, 0
+	handler := func(ctx context.Context, i int) error { // nolint: unparam
+		lock.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+		time.Sleep(time.Millisecond)
+		lock.Lock()
+		running--
+		handled++
+		lock.Unlock()
+		return nil
+	}
+	e := Must(New(0, handler, WithWorkerPool(p), WithPriority(Critical)))
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		if err := e.DispatchAsync(ctx, i); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	}
+	p.Close()
+	if handled != 10 || maxRunning > 2 {
+		t.Error("Unexpected handled dispatches:", handled, "with max concurrency:", maxRunning)
+	}
+	// Handlers are run in their own goroutines once the WorkerPool is closed
+	if err := e.DispatchAsync(ctx, 0); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if err := e.Barrier(ctx); err != nil {
+		t.Fatal("Unexpected error waiting for handlers:", err)
+	}
+	if handled != 11 {
+		t.Error("Handler not run after the WorkerPool was closed")
+	}
+}
@@ -0,0 +1,40 @@
+package thevent
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type handlingCtxKey struct{}
+
+// TrackHandling configures the Event to mark the context.Context passed to its handlers, and the handlers of its
+// sub-Events, so that code called by the handlers can detect that it's running inside a handler using IsHandling().
+// e.g. to dispatch asynchronously instead of recursively dispatching an Event. Marking the context.Context allocates
+// on every dispatch, so it's opt-in.
+func TrackHandling() Option {
+	return func(e *Event) error {
+		e.trackHandling = true
+		return nil
+	}
+}
+
+// IsHandling returns true if the context.Context was passed to a handler of an Event configured with
+// TrackHandling(), or of one of its sub-Events
+func IsHandling(ctx context.Context) bool {
+	handling, _ := ctx.Value(handlingCtxKey{}).(bool)
+	return handling
+}
+
+// markHandling marks the context.Context passed to the Event's handlers, if configured
+func (e *Event) markHandling(ctx context.Context) context.Context {
+	if !e.trackHandling || IsHandling(ctx) {
+		return ctx
+	}
+	return context.WithValue(ctx, handlingCtxKey{}, true)
+}
+
+// InFlight returns the number of the Event's handlers which are currently running, including asynchronously run
+// handlers. Handlers of sub-Events aren't counted.
+func (e *Event) InFlight() int {
+	return int(atomic.LoadInt32(&e.inFlight))
+}
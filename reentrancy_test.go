@@ -0,0 +1,85 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestIsHandling(t *testing.T) {
+	ctx := context.Background()
+	var inHandler, inSubHandler, inUntracked bool
+	e := thevent.Must(thevent.New(testStruct{}, func(ctx context.Context, s testStruct) error { // nolint: unparam
+		inHandler = thevent.IsHandling(ctx)
+		return nil
+	}, thevent.TrackHandling()))
+	if _, err := e.New(testStruct{}, "", func(ctx context.Context, s testStruct) error { // nolint: unparam
+		inSubHandler = thevent.IsHandling(ctx)
+		return nil
+	}); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	untracked := thevent.Must(thevent.New(0, func(ctx context.Context, i int) error { // nolint: unparam
+		inUntracked = thevent.IsHandling(ctx)
+		return nil
+	}))
+
+	if thevent.IsHandling(ctx) {
+		t.Error("Context.Context outside of a handler should not be handling")
+	}
+	if err := e.Dispatch(ctx, testStruct{}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if err := untracked.Dispatch(ctx, 1); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if !inHandler || !inSubHandler {
+		t.Error("Handlers of tracked Events should be handling:", inHandler, inSubHandler)
+	}
+	if inUntracked {
+		t.Error("Handlers of untracked Events should not be handling")
+	}
+}
+
+func TestInFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var e *thevent.Event
+	var inHandler int
+	e = thevent.Must(thevent.New(0, func(ctx context.Context, i int) error { // nolint: unparam
+		if i == 0 {
+			inHandler = e.InFlight()
+			return nil
+		}
+		started <- struct{}{}
+		<-release
+		return nil
+	}))
+
+	ctx := context.Background()
+	if err := e.Dispatch(ctx, 0); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if inHandler != 1 {
+		t.Error("Expected 1 handler in flight while handling, got:", inHandler)
+	}
+	for i := 0; i < 2; i++ {
+		if err := e.DispatchAsync(ctx, 1); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+		<-started
+	}
+	if n := e.InFlight(); n != 2 {
+		t.Error("Expected 2 handlers in flight, got:", n)
+	}
+	close(release)
+	if err := e.Barrier(ctx); err != nil {
+		t.Fatal("Unexpected error waiting for handlers:", err)
+	}
+	if n := e.InFlight(); n != 0 {
+		t.Error("Expected no handlers in flight, got:", n)
+	}
+}
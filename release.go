@@ -0,0 +1,34 @@
+package thevent
+
+import (
+	"sync"
+)
+
+// maxPooledErrors caps the capacity of the Errors of pooled HandlersResults, so a dispatch with many errors doesn't
+// pin their memory
+const maxPooledErrors = 64
+
+var handlersResultsPool = sync.Pool{New: func() interface{} { return &HandlersResults{} }}
+
+// newHandlersResults gets empty HandlersResults, reusing released HandlersResults if possible
+func newHandlersResults() *HandlersResults {
+	return handlersResultsPool.Get().(*HandlersResults)
+}
+
+// Release returns the HandlersResults to a pool, so that later dispatches reuse their memory instead of allocating,
+// e.g. for Events dispatched with results at a high rate. Releasing is optional. The HandlersResults, including its
+// Errors, must not be used once released, so errors which are kept must be copied first. HandlerResults passed to
+// callbacks are values, so they may be kept without copying.
+func (r *HandlersResults) Release() {
+	if r == nil {
+		return
+	}
+	for i := range r.Errors {
+		r.Errors[i] = nil
+	}
+	r.NumHandlers, r.NumSkipped, r.Errors = 0, 0, r.Errors[:0]
+	if cap(r.Errors) > maxPooledErrors {
+		r.Errors = nil
+	}
+	handlersResultsPool.Put(r)
+}
@@ -0,0 +1,58 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestHandlersResultsRelease(t *testing.T) {
+	errFailed := errors.New("failed")
+	e := thevent.Must(thevent.New(0, func(ctx context.Context, i int) error {
+		if i%2 == 1 {
+			return errFailed
+		}
+		return nil
+	}))
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		results, err := e.DispatchWithResults(ctx, i)
+		if err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+		if results.NumHandlers != 1 || (len(results.Errors) == 1) != (i%2 == 1) {
+			t.Error("Got unexpected results of released results:", results.NumHandlers, results.Errors)
+		}
+		results.Release()
+	}
+	var nilResults *thevent.HandlersResults
+	nilResults.Release()
+}
+
+func TestHandlersResultsReleaseAllocs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping allocation test in short mode")
+	}
+	e := thevent.Must(thevent.New(0, intHandler))
+	ctx := context.Background()
+	dispatch := func(release bool) func() {
+		return func() {
+			results, err := e.DispatchWithResults(ctx, 1)
+			if err != nil {
+				t.Fatal("Unexpected error dispatching:", err)
+			}
+			if release {
+				results.Release()
+			}
+		}
+	}
+	kept := testing.AllocsPerRun(100, dispatch(false))
+	released := testing.AllocsPerRun(100, dispatch(true))
+	if released >= kept {
+		t.Error("Expected releasing results to reduce allocations, got:", released, "instead of less than:", kept)
+	}
+}
@@ -0,0 +1,240 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"time"
+)
+
+// Disposition is the final disposition of a handler run by a dispatch
+type Disposition uint8
+
+const (
+	// Succeeded means the handler didn't return an error
+	Succeeded Disposition = iota
+	// Failed means the handler returned an error which wasn't retried. e.g. the error wasn't retryable
+	Failed
+	// Exhausted means the handler kept returning retryable errors until its RetryPolicy ran out of attempts
+	Exhausted
+	// Parked means the handler returned a retryable error and has been parked in a RetryQueue to be retried later
+	Parked
+	// Skipped means the handler didn't handle the event, e.g. a WithLeaderOnly() handler on an instance which isn't
+	// the leader, or a handler returning an Outcome with Skipped set
+	Skipped
+	// DeadLettered means the handler's invocation was parked in a RetryQueue and kept returning retryable errors when
+	// re-driven, until the RetryQueue gave up on it once its RetryPolicy ran out of attempts
+	DeadLettered
+)
+
+func (d Disposition) String() string {
+	switch d {
+	case Succeeded:
+		return "succeeded"
+	case Failed:
+		return "failed"
+	case Exhausted:
+		return "exhausted"
+	case Parked:
+		return "parked"
+	case Skipped:
+		return "skipped"
+	case DeadLettered:
+		return "dead-lettered"
+	}
+	return "unknown"
+}
+
+// RetryPolicy configures how a handler returning a retryable error is retried. An error is retryable if it's
+// classified as transient using Transient() or RetryAfter(), or if the handler returns an Outcome with Retryable
+// set, unless the error is classified as permanent using Permanent().
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times the handler is run, including the first attempt
+	MaxAttempts int
+	// Backoff is the delay before the first retry, which is doubled for every following retry
+	Backoff time.Duration
+	// MaxBackoff caps the delay between retries, if set
+	MaxBackoff time.Duration
+}
+
+// WithRetryPolicy retries the handler according to the RetryPolicy when it returns a retryable error. The retries
+// are run by the dispatch before the next handler is run, or in the handler's goroutine for asynchronous dispatches.
+// Retries stop once the context.Context passed to the handler is done.
+func WithRetryPolicy(policy RetryPolicy) HandlerOption {
+	return func(h *handler) error {
+		if policy.MaxAttempts < 1 {
+			return TypeError{errors.New("RetryPolicy must allow at least 1 attempt")}
+		}
+		if policy.Backoff < 0 || policy.MaxBackoff < 0 {
+			return TypeError{errors.New("RetryPolicy backoff must not be negative")}
+		}
+		h.retry = &policy
+		return nil
+	}
+}
+
+// RetryAfterError is returned by a handler using RetryAfter()
+type RetryAfterError struct {
+	Delay time.Duration
+	Err   error
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter may be returned by a handler to signal that err is transient and that the handler should be retried
+// after the delay, instead of after its RetryPolicy's backoff. e.g. when a downstream service is rate limiting.
+// The error is always retryable, but the handler is only retried if it has a RetryPolicy with attempts left. nil is
+// returned if err is nil.
+func RetryAfter(delay time.Duration, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryAfterError{Delay: delay, Err: err}
+}
+
+// PermanentError is returned by a handler using Permanent()
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// TransientError is returned by a handler using Transient()
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent classifies err as permanent, so the handler returning it is never retried, even if the handler's
+// Outcome is Retryable. e.g. when the event data is invalid. nil is returned if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// Transient classifies err as transient, so the handler returning it is retried if it has a RetryPolicy with
+// attempts left. e.g. when a downstream service is unavailable. nil is returned if err is nil.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &TransientError{Err: err}
+}
+
+// IsPermanent returns true if err, or an error it wraps, has been classified as permanent using Permanent()
+func IsPermanent(err error) bool {
+	permanent, _ := classify(err)
+	return permanent
+}
+
+// IsTransient returns true if err, or an error it wraps, has been classified as transient using Transient() or
+// RetryAfter()
+func IsTransient(err error) bool {
+	_, transient := classify(err)
+	return transient
+}
+
+// classify finds the outermost classification of err
+func classify(err error) (permanent, transient bool) {
+	for err != nil {
+		switch err.(type) {
+		case *PermanentError:
+			return true, false
+		case *TransientError, *RetryAfterError:
+			return false, true
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = u.Unwrap()
+	}
+	return false, false
+}
+
+// retryable returns true if the handler's results are a retryable error
+func retryable(res []reflect.Value) bool {
+	err := convertToError(res)
+	if err == nil {
+		return false
+	}
+	if permanent, transient := classify(err); permanent || transient {
+		return transient
+	}
+	return convertToOutcome(res).Retryable
+}
+
+// shouldRetry waits for the backoff and returns true if the handler should be retried after the given number of
+// attempts
+func (p *RetryPolicy) shouldRetry(ctx context.Context, res []reflect.Value, attempts int) bool {
+	if p == nil || attempts >= p.MaxAttempts || !retryable(res) {
+		return false
+	}
+	backoff := p.backoff(res, attempts)
+	if backoff <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(backoff)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// backoff gets the delay before retrying the handler with the given results after the given number of attempts
+func (p *RetryPolicy) backoff(res []reflect.Value, attempts int) time.Duration {
+	if ra, ok := convertToError(res).(*RetryAfterError); ok {
+		return ra.Delay
+	}
+	backoff := p.Backoff
+	// Without a MaxBackoff, doubling stops before the backoff overflows
+	for i := 1; i < attempts && (p.MaxBackoff == 0 || backoff < p.MaxBackoff) && backoff <= math.MaxInt64/2; i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	return backoff
+}
+
+// disposition returns the final disposition of the handler's results after the given number of attempts
+func (p *RetryPolicy) disposition(res []reflect.Value, attempts int) Disposition {
+	if convertToError(res) == nil {
+		if convertToOutcome(res).Skipped {
+			return Skipped
+		}
+		return Succeeded
+	}
+	if p != nil && attempts >= p.MaxAttempts && p.MaxAttempts > 1 && retryable(res) {
+		return Exhausted
+	}
+	return Failed
+}
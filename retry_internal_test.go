@@ -0,0 +1,22 @@
+package thevent
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoffOverflow(t *testing.T) {
+	res := errorResults(Transient(errors.New("unavailable")))
+	p := &RetryPolicy{MaxAttempts: math.MaxInt32, Backoff: time.Millisecond}
+	for _, attempts := range []int{64, 100, 1000} {
+		if backoff := p.backoff(res, attempts); backoff < time.Duration(math.MaxInt64/2) {
+			t.Error("Backoff overflowed after", attempts, "attempts:", backoff)
+		}
+	}
+	p.MaxBackoff = time.Minute
+	if backoff := p.backoff(res, 1000); backoff != time.Minute {
+		t.Error("Backoff should be capped by MaxBackoff, got:", backoff)
+	}
+}
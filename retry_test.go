@@ -0,0 +1,162 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestWithRetryPolicy(t *testing.T) {
+	testCases := []struct {
+		name                string
+		policy              thevent.RetryPolicy
+		retryable           bool
+		classify            func(error) error
+		errorGlob           string
+		expectedAttempts    int
+		expectedDisposition thevent.Disposition
+	}{
+		{name: "no attempts", policy: thevent.RetryPolicy{},
+			errorGlob: "RetryPolicy must allow at least 1 attempt"},
+		{name: "negative backoff", policy: thevent.RetryPolicy{MaxAttempts: 2, Backoff: -1},
+			errorGlob: "RetryPolicy backoff must not be negative"},
+		{name: "succeeds after retries", retryable: true,
+			policy:           thevent.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+			expectedAttempts: 3, expectedDisposition: thevent.Succeeded},
+		{name: "exhausted", retryable: true, policy: thevent.RetryPolicy{MaxAttempts: 2},
+			expectedAttempts: 2, expectedDisposition: thevent.Exhausted},
+		{name: "not retryable", policy: thevent.RetryPolicy{MaxAttempts: 3},
+			expectedAttempts: 1, expectedDisposition: thevent.Failed},
+		{name: "transient", classify: thevent.Transient, policy: thevent.RetryPolicy{MaxAttempts: 3},
+			expectedAttempts: 3, expectedDisposition: thevent.Succeeded},
+		{name: "permanent", retryable: true, classify: thevent.Permanent, policy: thevent.RetryPolicy{MaxAttempts: 3},
+			expectedAttempts: 1, expectedDisposition: thevent.Failed},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			handler := func(ctx context.Context, s testStruct) (thevent.Outcome, error) {
+				calls++
+				if calls < 3 {
+					err := errors.New("unavailable")
+					if tc.classify != nil {
+						err = tc.classify(err)
+					}
+					return thevent.Outcome{Retryable: tc.retryable}, err
+				}
+				return thevent.Outcome{}, nil
+			}
+			var results []thevent.HandlerResult
+			sink := thevent.ResultSinkFunc(func(ctx context.Context, _ thevent.EventInfo, res thevent.HandlerResult) {
+				results = append(results, res)
+			})
+			e, err := thevent.New(testStruct{}, thevent.WithResultSink(sink))
+			if err != nil {
+				t.Fatal("Unable to create event:", err)
+			}
+			err = e.AddHandlerWithOptions(handler, thevent.WithRetryPolicy(tc.policy))
+			errorMatchesGlob(t, err, tc.errorGlob)
+			if err != nil {
+				return
+			}
+			if err := e.Dispatch(context.Background(), testStruct{}); err != nil {
+				t.Fatal("Unexpected error dispatching:", err)
+			}
+			if len(results) != 1 {
+				t.Fatal("Expected 1 result, got:", results)
+			}
+			res := results[0]
+			if res.Attempts != tc.expectedAttempts || calls != tc.expectedAttempts {
+				t.Error("Expected", tc.expectedAttempts, "attempts, got:", res.Attempts, "calls:", calls)
+			}
+			if res.Disposition != tc.expectedDisposition {
+				t.Error("Expected disposition:", tc.expectedDisposition, "got:", res.Disposition)
+			}
+			if (res.Err == nil) != (tc.expectedDisposition == thevent.Succeeded) {
+				t.Error("Unexpected error:", res.Err)
+			}
+		})
+	}
+}
+
+type wrappedError struct {
+	err error
+}
+
+func (e wrappedError) Error() string { return "wrapped: " + e.err.Error() }
+
+func (e wrappedError) Unwrap() error { return e.err }
+
+func TestErrorClassification(t *testing.T) {
+	err := errors.New("failed")
+	testCases := []struct {
+		name              string
+		err               error
+		expectedPermanent bool
+		expectedTransient bool
+	}{
+		{name: "nil", err: nil},
+		{name: "unclassified", err: err},
+		{name: "permanent", err: thevent.Permanent(err), expectedPermanent: true},
+		{name: "transient", err: thevent.Transient(err), expectedTransient: true},
+		{name: "retry after", err: thevent.RetryAfter(time.Second, err), expectedTransient: true},
+		{name: "wrapped permanent", err: wrappedError{thevent.Permanent(err)}, expectedPermanent: true},
+		{name: "outermost classification", err: thevent.Permanent(wrappedError{thevent.Transient(err)}),
+			expectedPermanent: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if permanent := thevent.IsPermanent(tc.err); permanent != tc.expectedPermanent {
+				t.Error("Expected permanent:", tc.expectedPermanent, "got:", permanent)
+			}
+			if transient := thevent.IsTransient(tc.err); transient != tc.expectedTransient {
+				t.Error("Expected transient:", tc.expectedTransient, "got:", transient)
+			}
+		})
+	}
+	if thevent.Permanent(nil) != nil || thevent.Transient(nil) != nil {
+		t.Error("Classifying nil should return nil")
+	}
+	if msg := thevent.Permanent(err).Error(); msg != "failed" {
+		t.Error("Unexpected error message:", msg)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var calls []time.Time
+	handler := func(ctx context.Context, s testStruct) error {
+		calls = append(calls, time.Now())
+		if len(calls) == 1 {
+			return thevent.RetryAfter(20*time.Millisecond, errors.New("rate limited"))
+		}
+		return nil
+	}
+	e := thevent.Must(thevent.New(testStruct{}))
+	if err := e.AddHandlerWithOptions(handler, thevent.WithRetryPolicy(thevent.RetryPolicy{MaxAttempts: 2,
+		Backoff: time.Hour})); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	res, err := e.DispatchWithResults(context.Background(), testStruct{})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if res.Erred() || len(calls) != 2 {
+		t.Fatal("Handler should be retried. Calls:", len(calls), "errors:", res.Errors)
+	}
+	if d := calls[1].Sub(calls[0]); d < 20*time.Millisecond || d > time.Minute {
+		t.Error("Handler should be retried after the requested delay, not:", d)
+	}
+
+	err = thevent.RetryAfter(time.Second, errors.New("rate limited"))
+	if err.Error() != "rate limited" {
+		t.Error("Unexpected error message:", err)
+	}
+	if err := thevent.RetryAfter(time.Second, nil); err != nil {
+		t.Error("RetryAfter of a nil error should be nil, got:", err)
+	}
+}
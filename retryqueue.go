@@ -0,0 +1,329 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ParkedRetry is a failed handler invocation parked in a RetryQueue to be re-driven later
+type ParkedRetry struct {
+	ID uint64
+	// Event is the name of the handler's Event on its Bus
+	Event string
+	// Handler is the name of the handler, see WithName()
+	Handler string
+	Data    Data
+	// Attempts is the number of times the handler has been run
+	Attempts int
+	// ParkedAt is when the invocation was first parked
+	ParkedAt time.Time
+	// Due is when the invocation is re-driven next
+	Due time.Time
+}
+
+// RetryStore persists the retries parked in a RetryQueue, so that they may be restored after a restart using
+// RetryQueue.Restore(). Only the retries of named handlers of Events registered on a Bus are persisted, since the
+// others can't be found again. Load must return Data of the Events' data types. Implementations must be safe for
+// concurrent use.
+type RetryStore interface {
+	// Save saves the parked retry, replacing the parked retry with the same ID, if any
+	Save(ctx context.Context, r ParkedRetry) error
+	Delete(ctx context.Context, id uint64) error
+	Load(ctx context.Context) ([]ParkedRetry, error)
+}
+
+// parkedRetry is a retry parked in a RetryQueue
+type parkedRetry struct {
+	ParkedRetry
+	event   *Event
+	handler *handler
+	// ctx is the detached context.Context of the dispatch the invocation failed in
+	ctx  context.Context
+	data reflect.Value
+	// persisted is true if the retry has been saved to the RetryStore
+	persisted bool
+}
+
+// redriveKey is the context.Context key of the parked retry being re-driven
+type redriveKey struct{}
+
+// RetryQueueStats are the metrics of a RetryQueue
+type RetryQueueStats struct {
+	// Depth is the number of parked retries
+	Depth int
+	// OldestAge is how long the oldest parked retry has been parked for
+	OldestAge time.Duration
+}
+
+// RetryQueue is a scheduler for delayed retries shared by the Events of a Bus. The failed invocations of handlers
+// using WithDelayedRetry() are parked in the RetryQueue and re-driven once their backoff has passed, so that
+// neither the dispatch nor a worker waits for the backoff. Parked retries are kept in memory, and may also be
+// persisted using a RetryStore. See WithRetryQueue()
+type RetryQueue struct {
+	lock   sync.Mutex
+	policy RetryPolicy
+	store  RetryStore
+	// retries are the parked retries ordered by when they're due
+	retries   []*parkedRetry
+	lastID    uint64
+	closed    bool
+	wake      chan struct{}
+	done      chan struct{}
+	redriving sync.WaitGroup
+	now       func() time.Time
+}
+
+// NewRetryQueue creates a new RetryQueue which re-drives parked retries according to the RetryPolicy. The
+// RetryPolicy's MaxAttempts includes the invocation by the dispatch, so it must allow at least 2 attempts. store
+// is optional. Persisting parked retries is best effort: retries which fail to be saved are still re-driven from
+// memory.
+func NewRetryQueue(policy RetryPolicy, store RetryStore) (*RetryQueue, error) {
+	if policy.MaxAttempts < 2 {
+		return nil, TypeError{errors.New("RetryQueue RetryPolicy must allow at least 2 attempts")}
+	}
+	if policy.Backoff < 0 || policy.MaxBackoff < 0 {
+		return nil, TypeError{errors.New("RetryPolicy backoff must not be negative")}
+	}
+	q := &RetryQueue{policy: policy, store: store, wake: make(chan struct{}, 1), done: make(chan struct{}),
+		now: time.Now}
+	go q.run()
+	return q, nil
+}
+
+// WithRetryQueue configures the Bus to park the failed invocations of handlers using WithDelayedRetry() in the
+// RetryQueue
+func WithRetryQueue(q *RetryQueue) BusOption {
+	return func(b *Bus) {
+		b.retryQueue = q
+	}
+}
+
+// WithDelayedRetry parks the handler's invocations returning a retryable error in the RetryQueue of the Event's
+// Bus, to be re-driven once the backoff of the RetryQueue's RetryPolicy has passed, instead of retrying the handler
+// while the dispatch waits. The handler's result for the dispatch has the Parked Disposition. If the handler also
+// has a RetryPolicy, the invocation is only parked once the RetryPolicy's attempts are used up. Invocations of
+// handlers of Events which aren't registered on a Bus with a RetryQueue aren't parked.
+//
+// Re-driven handlers are run asynchronously with the dispatch's context.Context detached using DetachContext(), and
+// their results are recorded with the ResultSinks. Once the RetryQueue's RetryPolicy runs out of attempts, the
+// RetryQueue gives up on the invocation and its last result has the DeadLettered Disposition.
+func WithDelayedRetry() HandlerOption {
+	return func(h *handler) error {
+		h.delayedRetry = true
+		return nil
+	}
+}
+
+// park parks the handler's failed invocation in the RetryQueue of the Event's Bus, if it should be, returning the
+// disposition of the handler's results
+func (e *Event) park(ctx context.Context, h *handler, data reflect.Value, res []reflect.Value,
+	disposition Disposition) Disposition {
+	if !h.delayedRetry || disposition == Succeeded || e.bus == nil || e.bus.retryQueue == nil || !retryable(res) {
+		return disposition
+	}
+	r, _ := ctx.Value(redriveKey{}).(*parkedRetry)
+	if r == nil || r.event != e || r.handler != h {
+		r = &parkedRetry{event: e, handler: h, ctx: DetachContext(ctx), data: data}
+		r.Event, r.Handler, r.Data = e.busName, h.name, data.Interface()
+	}
+	return e.bus.retryQueue.park(r, res)
+}
+
+// park parks the retry until its backoff has passed, unless it has run out of attempts, in which case the
+// RetryQueue gives up on it, or the RetryQueue has been closed
+func (q *RetryQueue) park(r *parkedRetry, res []reflect.Value) Disposition {
+	r.Attempts++
+	if r.Attempts >= q.policy.MaxAttempts {
+		return DeadLettered
+	}
+	now := q.now()
+	if r.ParkedAt.IsZero() {
+		r.ParkedAt = now
+	}
+	r.Due = now.Add(q.policy.backoff(res, r.Attempts))
+	q.lock.Lock()
+	if q.closed {
+		q.lock.Unlock()
+		return Failed
+	}
+	if r.ID == 0 {
+		q.lastID++
+		r.ID = q.lastID
+	}
+	q.lock.Unlock()
+	// The retry is saved before it's queued so that it can't be deleted before it's saved
+	if q.store != nil && r.Event != "" && r.Handler != "" {
+		if err := q.store.Save(r.ctx, r.ParkedRetry); err == nil {
+			r.persisted = true
+		}
+	}
+	q.lock.Lock()
+	q.insert(r)
+	q.lock.Unlock()
+	q.signal()
+	return Parked
+}
+
+// insert queues the retry after the retries due before or at the same time. The lock must be held.
+func (q *RetryQueue) insert(r *parkedRetry) {
+	i := sort.Search(len(q.retries), func(i int) bool { return q.retries[i].Due.After(r.Due) })
+	q.retries = append(q.retries, nil)
+	copy(q.retries[i+1:], q.retries[i:])
+	q.retries[i] = r
+}
+
+func (q *RetryQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run re-drives the parked retries once they're due until the RetryQueue is closed
+func (q *RetryQueue) run() {
+	defer close(q.done)
+	for {
+		q.lock.Lock()
+		if q.closed {
+			q.lock.Unlock()
+			return
+		}
+		now := q.now()
+		var due []*parkedRetry
+		for len(q.retries) > 0 && !q.retries[0].Due.After(now) {
+			due = append(due, q.retries[0])
+			q.retries[0] = nil
+			q.retries = q.retries[1:]
+		}
+		var wait <-chan time.Time
+		var t *time.Timer
+		if len(q.retries) > 0 {
+			t = time.NewTimer(q.retries[0].Due.Sub(now))
+			wait = t.C
+		}
+		q.lock.Unlock()
+		for _, r := range due {
+			q.redrive(r)
+		}
+		select {
+		case <-wait:
+		case <-q.wake:
+		}
+		if t != nil {
+			t.Stop()
+		}
+	}
+}
+
+// redrive runs the parked retry's handler again, unless its Event has been destroyed
+func (q *RetryQueue) redrive(r *parkedRetry) {
+	q.redriving.Add(1)
+	r.event.goAsync(r.handler, func() {
+		defer q.redriving.Done()
+		r.event.lock.RLock()
+		destroyed := r.event.destroyed
+		r.event.lock.RUnlock()
+		if !destroyed {
+			ctx := context.WithValue(r.ctx, redriveKey{}, r)
+			_, hr := r.event.callHandler(ctx, r.handler, []reflect.Value{reflect.ValueOf(ctx), r.data})
+			if hr.Disposition == Parked {
+				return
+			}
+		}
+		if r.persisted {
+			q.store.Delete(r.ctx, r.ID) // nolint: errcheck
+		}
+	})
+}
+
+// Restore queues the retries persisted in the RetryQueue's RetryStore, e.g. after a restart. The retries' handlers
+// are found by name on the Bus's Events, so Restore should be called once the Events and handlers have been added.
+// Retries whose handler can't be found are left in the RetryStore, and reported in the returned error.
+func (q *RetryQueue) Restore(ctx context.Context, b *Bus) error {
+	if q.store == nil {
+		return TypeError{errors.New("RetryQueue has no RetryStore")}
+	}
+	retries, err := q.store.Load(ctx)
+	if err != nil {
+		return err
+	}
+	var errs MultiTypeError
+	for _, p := range retries {
+		e, ok := b.Event(p.Event)
+		if !ok {
+			errs = append(errs, TypeError{fmt.Errorf("Unable to restore parked retry %d. No Event with name: %s",
+				p.ID, p.Event)})
+			continue
+		}
+		h := e.namedHandler(p.Handler)
+		if h == nil {
+			errs = append(errs, TypeError{fmt.Errorf("Unable to restore parked retry %d. No handler with name: %s",
+				p.ID, p.Handler)})
+			continue
+		}
+		if err := e.checkDataType(p.Data); err != nil {
+			errs = append(errs, TypeError{fmt.Errorf("Unable to restore parked retry %d: %v", p.ID, err)})
+			continue
+		}
+		r := &parkedRetry{ParkedRetry: p, event: e, handler: h, ctx: context.Background(),
+			data: reflect.ValueOf(p.Data), persisted: true}
+		q.lock.Lock()
+		if p.ID > q.lastID {
+			q.lastID = p.ID
+		}
+		q.insert(r)
+		q.lock.Unlock()
+	}
+	q.signal()
+	if len(errs) > 0 {
+		return TypeError{errs}
+	}
+	return nil
+}
+
+// namedHandler gets the Event's handler with the name, if any
+func (e *Event) namedHandler(name string) *handler {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	for _, h := range e.handlers {
+		if h.name == name {
+			return h
+		}
+	}
+	return nil
+}
+
+// Stats gets the metrics of the RetryQueue
+func (q *RetryQueue) Stats() RetryQueueStats {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	s := RetryQueueStats{Depth: len(q.retries)}
+	now := q.now()
+	for _, r := range q.retries {
+		if age := now.Sub(r.ParkedAt); age > s.OldestAge {
+			s.OldestAge = age
+		}
+	}
+	return s
+}
+
+// Close stops re-driving parked retries and waits for the re-driven handlers to finish. Retries parked in memory are
+// dropped, while persisted retries are kept in the RetryStore. Invocations failing once the RetryQueue has been
+// closed aren't parked.
+func (q *RetryQueue) Close() {
+	q.lock.Lock()
+	if q.closed {
+		q.lock.Unlock()
+		return
+	}
+	q.closed = true
+	q.lock.Unlock()
+	q.signal()
+	<-q.done
+	q.redriving.Wait()
+}
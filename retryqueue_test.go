@@ -0,0 +1,209 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+// memRetryStore is an in-memory RetryStore
+type memRetryStore struct {
+	lock    sync.Mutex
+	retries map[uint64]thevent.ParkedRetry
+	saves   int
+}
+
+func newMemRetryStore(retries ...thevent.ParkedRetry) *memRetryStore {
+	s := &memRetryStore{retries: map[uint64]thevent.ParkedRetry{}}
+	for _, r := range retries {
+		s.retries[r.ID] = r
+	}
+	return s
+}
+
+func (s *memRetryStore) Save(ctx context.Context, r thevent.ParkedRetry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.retries[r.ID] = r
+	s.saves++
+	return nil
+}
+
+func (s *memRetryStore) Delete(ctx context.Context, id uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.retries, id)
+	return nil
+}
+
+func (s *memRetryStore) Load(ctx context.Context) ([]thevent.ParkedRetry, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	retries := make([]thevent.ParkedRetry, 0, len(s.retries))
+	for _, r := range s.retries {
+		retries = append(retries, r)
+	}
+	return retries, nil
+}
+
+func (s *memRetryStore) len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.retries)
+}
+
+// newRetryBus creates a Bus with a RetryQueue whose handler results are sent to the returned channel
+func newRetryBus(t *testing.T, maxAttempts int, store thevent.RetryStore) (*thevent.Bus, *thevent.RetryQueue,
+	<-chan thevent.HandlerResult) {
+	q, err := thevent.NewRetryQueue(thevent.RetryPolicy{MaxAttempts: maxAttempts, Backoff: time.Millisecond}, store)
+	if err != nil {
+		t.Fatal("Unable to create RetryQueue:", err)
+	}
+	results := make(chan thevent.HandlerResult, 10)
+	b := thevent.NewBus(thevent.WithRetryQueue(q),
+		thevent.WithBusResultSink(thevent.ResultSinkFunc(func(ctx context.Context, event thevent.EventInfo,
+			res thevent.HandlerResult) {
+			results <- res
+		})))
+	return b, q, results
+}
+
+func TestWithDelayedRetry(t *testing.T) {
+	store := newMemRetryStore()
+	b, q, results := newRetryBus(t, 3, store)
+	defer q.Close()
+	e, err := b.New("order.created", 0)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	var lock sync.Mutex
+	var calls []int
+	if err := e.AddHandlerWithOptions(func(ctx context.Context, i int) error {
+		lock.Lock()
+		defer lock.Unlock()
+		calls = append(calls, i)
+		if len(calls) < 3 {
+			return thevent.Transient(errors.New("unavailable"))
+		}
+		return nil
+	}, thevent.WithName("notify"), thevent.WithDelayedRetry()); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	if err := e.Dispatch(context.Background(), 7); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	for i, expected := range []thevent.Disposition{thevent.Parked, thevent.Parked, thevent.Succeeded} {
+		select {
+		case res := <-results:
+			if res.Disposition != expected {
+				t.Error("Got disposition:", res.Disposition, "for attempt:", i+1, "instead of:", expected)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for attempt:", i+1)
+		}
+	}
+	q.Close()
+	lock.Lock()
+	defer lock.Unlock()
+	if len(calls) != 3 || calls[2] != 7 {
+		t.Error("Unexpected calls:", calls)
+	}
+	if store.len() != 0 || store.saves != 2 {
+		t.Error("Expected the retry to be saved twice and deleted, got saves:", store.saves, "stored:", store.len())
+	}
+	if s := q.Stats(); s.Depth != 0 {
+		t.Error("Expected empty RetryQueue, got depth:", s.Depth)
+	}
+}
+
+func TestWithDelayedRetryDeadLettered(t *testing.T) {
+	b, q, results := newRetryBus(t, 2, nil)
+	defer q.Close()
+	e, err := b.New("order.created", 0)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if err := e.AddHandlerWithOptions(func(ctx context.Context, i int) error {
+		return thevent.Transient(errors.New("unavailable"))
+	}, thevent.WithDelayedRetry()); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	// Non-retryable errors aren't parked
+	if err := e.AddHandlerWithOptions(func(ctx context.Context, i int) error {
+		return errors.New("invalid")
+	}, thevent.WithDelayedRetry()); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	if err := e.Dispatch(context.Background(), 1); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	counts := map[thevent.Disposition]int{}
+	for i := 0; i < 3; i++ {
+		select {
+		case res := <-results:
+			counts[res.Disposition]++
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for results")
+		}
+	}
+	if counts[thevent.Parked] != 1 || counts[thevent.Failed] != 1 || counts[thevent.DeadLettered] != 1 {
+		t.Error("Unexpected dispositions:", counts)
+	}
+	if s := thevent.DeadLettered.String(); s != "dead-lettered" {
+		t.Error("Unexpected disposition string:", s)
+	}
+}
+
+func TestRetryQueueRestore(t *testing.T) {
+	store := newMemRetryStore(
+		thevent.ParkedRetry{ID: 3, Event: "order.created", Handler: "notify", Data: 5, Attempts: 1},
+		thevent.ParkedRetry{ID: 4, Event: "order.created", Handler: "missing", Data: 5, Attempts: 1},
+	)
+	b, q, results := newRetryBus(t, 3, store)
+	defer q.Close()
+	e, err := b.New("order.created", 0)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if err := e.AddHandlerWithOptions(func(ctx context.Context, i int) error {
+		if i != 5 {
+			return errors.New("unexpected data")
+		}
+		return nil
+	}, thevent.WithName("notify"), thevent.WithDelayedRetry()); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	errorMatchesGlob(t, q.Restore(context.Background(), b),
+		`*Unable to restore parked retry 4. No handler with name: missing*`)
+	select {
+	case res := <-results:
+		if res.Err != nil {
+			t.Error("Unexpected error re-driving restored retry:", res.Err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for restored retry")
+	}
+	q.Close()
+	if store.len() != 1 {
+		t.Error("Expected the unrestored retry to be kept, got stored:", store.len())
+	}
+}
+
+func TestNewRetryQueue(t *testing.T) {
+	_, err := thevent.NewRetryQueue(thevent.RetryPolicy{MaxAttempts: 1}, nil)
+	errorMatchesGlob(t, err, "RetryQueue RetryPolicy must allow at least 2 attempts")
+	_, err = thevent.NewRetryQueue(thevent.RetryPolicy{MaxAttempts: 2, Backoff: -1}, nil)
+	errorMatchesGlob(t, err, "RetryPolicy backoff must not be negative")
+	q, err := thevent.NewRetryQueue(thevent.RetryPolicy{MaxAttempts: 2}, nil)
+	if err != nil {
+		t.Fatal("Unable to create RetryQueue:", err)
+	}
+	defer q.Close()
+	errorMatchesGlob(t, q.Restore(context.Background(), thevent.NewBus()), "RetryQueue has no RetryStore")
+}
@@ -0,0 +1,136 @@
+package thevent
+
+import (
+	"context"
+	"sync"
+)
+
+// sequencer runs dispatches one at a time in the order in which they were made
+type sequencer struct {
+	lock    sync.Mutex
+	cond    *sync.Cond
+	next    uint64
+	serving uint64
+}
+
+func newSequencer() *sequencer {
+	s := &sequencer{}
+	s.cond = sync.NewCond(&s.lock)
+	return s
+}
+
+// ticket reserves the dispatch's place in the order
+func (s *sequencer) ticket() uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	t := s.next
+	s.next++
+	return t
+}
+
+// wait blocks until it's the turn of the dispatch with the given ticket
+func (s *sequencer) wait(t uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for s.serving != t {
+		s.cond.Wait()
+	}
+}
+
+// done lets the next dispatch run
+func (s *sequencer) done() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.serving++
+	s.cond.Broadcast()
+}
+
+// Sequential configures the Bus to run the dispatches of all of its Events, including sub-Events, one at a time
+// in the order in which they were made, so every handler observes the same global order of events.
+//
+// The handlers of asynchronous dispatches are run in the background one at a time, after all of the dispatches
+// made before. Since a dispatch waits on all of the dispatches made before it, handlers must not synchronously
+// dispatch Events of the same Bus.
+func Sequential() BusOption {
+	return func(b *Bus) {
+		b.sequencer = newSequencer()
+	}
+}
+
+// dispatchSequenced dispatches the Event in the order of the Event's Bus, if the Bus is sequential
+func (e *Event) dispatchSequenced(ctx context.Context, d *dispatchState, data interface{}) (*HandlersResults,
+	<-chan error, error) {
+	if e.bus == nil || e.bus.sequencer == nil {
+		return e.dispatch(ctx, d, data)
+	}
+	s := e.bus.sequencer
+	if !d.async {
+		s.wait(s.ticket())
+		defer s.done()
+		return e.dispatch(ctx, d, data)
+	}
+
+	if err := e.checkDataType(data); err != nil {
+		return nil, nil, err
+	}
+	if err := e.checkContext(e.decorateContext(e.withMetadata(ctx))); err != nil {
+		return nil, nil, err
+	}
+	if e.shed() {
+		return nil, nil, ErrShed
+	}
+	e.lock.RLock()
+	destroyed := e.destroyed
+	e.lock.RUnlock()
+	if destroyed {
+		return nil, nil, ErrDestroyed
+	}
+	t := s.ticket()
+	seq := e.async.start()
+	// The dispatch state is copied for the goroutine so that synchronous dispatches don't need to allocate it
+	ad := &dispatchState{}
+	*ad = *d
+	var errorsCh chan error
+	if ad.trackResults {
+		errorsCh = make(chan error)
+	}
+	ad.async = false
+	if ad.pending != nil {
+		ad.pending.Add(1)
+	}
+	go func() {
+		defer e.async.finish(seq)
+		if ad.pending != nil {
+			defer ad.pending.Done()
+		}
+		s.wait(t)
+		// The dispatch was already checked for shedding, so it's not shed twice
+		res, _, err := e.dispatchAdmitted(ctx, ad, data)
+		// The results are only sent once the next dispatch may run, so a slow receiver doesn't hold up the Bus
+		s.done()
+		e.reportDispatchErr(ctx, data, err)
+		if errorsCh != nil {
+			res.replay(errorsCh)
+			res.Release()
+			close(errorsCh)
+		}
+	}()
+	return nil, errorsCh, nil
+}
+
+// replay sends the errors of the results to ch, followed by a nil error for every handler which didn't err and
+// ErrSkipped for every skipped handler, like the results of an asynchronous dispatch
+func (r *HandlersResults) replay(ch chan<- error) {
+	if r == nil {
+		return
+	}
+	for _, err := range r.Errors {
+		ch <- err
+	}
+	for i := uint(len(r.Errors)); i < r.NumHandlers; i++ {
+		ch <- nil
+	}
+	for i := uint(0); i < r.NumSkipped; i++ {
+		ch <- ErrSkipped
+	}
+}
@@ -0,0 +1,197 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// WithShadow runs the handler in shadow mode, e.g. to canary a rewritten handler against live traffic before it
+// replaces the current one. A shadow handler receives every dispatch of the Event, but never affects the dispatch:
+// its error isn't returned or counted in the HandlersResults, its Outcome is ignored, its results aren't passed to
+// dispatch callbacks, ResultSinks, the error rate watchdog or the SLO, and it doesn't count towards failing fast.
+// Instead, every result of the handler is passed to record. A panic of the handler is recovered and recorded as
+// a PanicError. The handler still adds to the duration of synchronous dispatches. record must be safe for
+// concurrent use.
+func WithShadow(record func(HandlerResult)) HandlerOption {
+	return func(h *handler) error {
+		if record == nil {
+			return TypeError{errors.New("Shadow result recorder must not be nil")}
+		}
+		h.shadow = record
+		return nil
+	}
+}
+
+// callShadow runs the shadow handler. Asynchronously run shadow handlers aren't waited for by the dispatch. The
+// shadow handler's result completes its pair, if any.
+func (e *Event) callShadow(ctx context.Context, h *handler, args []reflect.Value, async bool, pair *shadowPair) {
+	turn := h.turn()
+	if !async {
+		pair.done(1, e.runShadow(h, func() HandlerResult {
+			_, hr := e.callHandlerInTurn(ctx, h, args, turn)
+			return hr
+		}))
+		return
+	}
+	data := args[1]
+	seq := e.async.start()
+	e.goAsync(h, func() {
+		defer e.async.finish(seq)
+		pair.done(1, e.runShadow(h, func() HandlerResult { return e.callFresh(ctx, h, data, turn) }))
+	})
+}
+
+// runShadow runs the shadow handler, recording a panic as a PanicError instead of propagating it
+func (e *Event) runShadow(h *handler, run func() HandlerResult) (hr HandlerResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			hr = HandlerResult{Event: e, Handler: h.fn.Interface(), Name: h.name, Labels: h.labels,
+				Err:         PanicError{Value: r, Stack: debug.Stack(), RegisteredAt: h.registeredAt},
+				Disposition: Failed, RegisteredAt: h.registeredAt}
+			h.shadow(hr)
+		}
+	}()
+	return run()
+}
+
+// ShadowComparator compares the result of a primary handler with the result of its shadow handler for the same
+// dispatch, returning a description of the difference, or an empty string if the results match
+type ShadowComparator func(primary, shadow HandlerResult) string
+
+// CompareResults is the default ShadowComparator. The results match if both handlers returned the same Outcome and
+// either no error or errors with the same message.
+func CompareResults(primary, shadow HandlerResult) string {
+	var diffs []string
+	if pErr, sErr := errorString(primary.Err), errorString(shadow.Err); pErr != sErr {
+		diffs = append(diffs, fmt.Sprintf("error: %q != %q", pErr, sErr))
+	}
+	if primary.Outcome != shadow.Outcome {
+		diffs = append(diffs, fmt.Sprintf("outcome: %+v != %+v", primary.Outcome, shadow.Outcome))
+	}
+	return strings.Join(diffs, ", ")
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// ShadowMismatch is the event data for the ShadowMismatched meta-Event
+type ShadowMismatch struct {
+	Event   *Event
+	Data    Data
+	Primary HandlerResult
+	Shadow  HandlerResult
+	// Diff describes the difference between the results, as returned by the ShadowComparator
+	Diff string
+}
+
+// ShadowMismatched is dispatched when the result of a shadow handler added using WithShadowOf() doesn't match the
+// result of its primary handler for the same dispatch
+var ShadowMismatched = newMetaEvent(ShadowMismatch{})
+
+// shadowComparison is the configuration of a shadow handler compared with its primary handler
+type shadowComparison struct {
+	// primary is the key of the primary handler
+	primary uintptr
+	compare ShadowComparator
+}
+
+// WithShadowOf runs the handler in shadow mode as a replacement candidate for the primary handler, e.g. to validate
+// a migrated query-style handler against the handler it replaces. Whenever both handlers run for a dispatch, their
+// results are compared using compare, or CompareResults if compare is nil, and mismatches are reported using the
+// ShadowMismatched meta-Event. The primary handler must be added to the same Event, and is matched by its function.
+// The handler's results may also be recorded using WithShadow().
+func WithShadowOf(primary Handler, compare ShadowComparator) HandlerOption {
+	return func(h *handler) error {
+		if dh, ok := primary.(directHandler); ok {
+			primary = dh.handler
+		}
+		if primary == nil {
+			return TypeError{errors.New("Primary handler must not be nil")}
+		}
+		v := reflect.ValueOf(primary)
+		if v.Kind() != reflect.Func {
+			return TypeError{fmt.Errorf("Handler must be a func, not: %s", v.Type().String())}
+		}
+		if compare == nil {
+			compare = CompareResults
+		}
+		h.comparison = &shadowComparison{primary: v.Pointer(), compare: compare}
+		if h.shadow == nil {
+			h.shadow = func(HandlerResult) {}
+		}
+		return nil
+	}
+}
+
+// shadowPair pairs the results of a primary handler and of its shadow handler for a single dispatch, comparing
+// them once both handlers ran
+type shadowPair struct {
+	event  *Event
+	ctx    context.Context
+	data   interface{}
+	shadow *handler
+	lock   sync.Mutex
+	// results are the results of the primary and of the shadow handler
+	results [2]*HandlerResult
+}
+
+// done records the result of the primary handler at index 0 or of the shadow handler at index 1. The pair may be
+// nil.
+func (p *shadowPair) done(i int, hr HandlerResult) {
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	p.results[i] = &hr
+	primary, shadow := p.results[0], p.results[1]
+	p.lock.Unlock()
+	if primary == nil || shadow == nil {
+		return
+	}
+	if diff := p.shadow.comparison.compare(*primary, *shadow); diff != "" {
+		p.event.dispatchMeta(p.ctx, ShadowMismatched, ShadowMismatch{Event: p.event, Data: p.data,
+			Primary: *primary, Shadow: *shadow, Diff: diff})
+	}
+}
+
+// shadowPairs creates the pairs of the dispatch for the compared shadow handlers, if any
+func (e *Event) shadowPairs(ctx context.Context, data interface{}, handlers []*handler) []*shadowPair {
+	var pairs []*shadowPair
+	for _, h := range handlers {
+		if h.comparison != nil {
+			pairs = append(pairs, &shadowPair{event: e, ctx: ctx, data: data, shadow: h})
+		}
+	}
+	return pairs
+}
+
+// shadowPairOf gets the pair of the shadow handler
+func shadowPairOf(pairs []*shadowPair, h *handler) *shadowPair {
+	for _, p := range pairs {
+		if p.shadow == h {
+			return p
+		}
+	}
+	return nil
+}
+
+// primaryDone completes the pairs of the shadow handlers compared with the primary handler
+func primaryDone(pairs []*shadowPair, h *handler, hr HandlerResult) {
+	if len(pairs) == 0 || h.lazy != nil || h.method != nil || h.subscription != nil {
+		return
+	}
+	for _, p := range pairs {
+		if p.shadow.comparison.primary == h.fn.Pointer() {
+			p.done(0, hr)
+		}
+	}
+}
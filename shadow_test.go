@@ -0,0 +1,158 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestWithShadow(t *testing.T) {
+	shadowErr := errors.New("shadow error")
+	var recorded []thevent.HandlerResult
+	record := func(hr thevent.HandlerResult) { recorded = append(recorded, hr) }
+	ran := false
+	e := thevent.Must(thevent.New(0, thevent.FailFast(thevent.SkipRemainingHandlers)))
+	if err := e.AddHandlerWithOptions(func(ctx context.Context, i int) error {
+		return shadowErr
+	}, thevent.WithShadow(record)); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	if err := e.AddHandlerWithOptions(func(ctx context.Context, i int) error {
+		panic("shadow panic")
+	}, thevent.WithShadow(record)); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	if err := e.AddHandlers(func(ctx context.Context, i int) error { // nolint: unparam
+		ran = true
+		return nil
+	}); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	results, err := e.DispatchWithResults(context.Background(), 1)
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if !ran {
+		t.Error("Shadow handler errors should not stop the dispatch")
+	}
+	if results.NumHandlers != 1 || results.Erred() {
+		t.Error("Shadow handlers should not be counted in the results, got:", results.NumHandlers, results.Errors)
+	}
+	if len(recorded) != 2 {
+		t.Fatal("Expected 2 recorded shadow results, got:", len(recorded))
+	}
+	if recorded[0].Err != shadowErr {
+		t.Error("Got shadow error:", recorded[0].Err, "instead of:", shadowErr)
+	}
+	if p, ok := recorded[1].Err.(thevent.PanicError); !ok || p.Value != "shadow panic" {
+		t.Error("Expected shadow panic to be recorded as a PanicError, got:", recorded[1].Err)
+	}
+
+	errorMatchesGlob(t, e.AddHandlerWithOptions(func(ctx context.Context, i int) error { return nil },
+		thevent.WithShadow(nil)), "Shadow result recorder must not be nil")
+}
+
+func TestWithShadowAsync(t *testing.T) {
+	recorded := make(chan thevent.HandlerResult, 1)
+	shadowErr := errors.New("shadow error")
+	e := thevent.Must(thevent.New(0))
+	if err := e.AddHandlerWithOptions(func(ctx context.Context, i int) error {
+		return shadowErr
+	}, thevent.WithShadow(func(hr thevent.HandlerResult) { recorded <- hr })); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	if err := e.AddHandlers(intHandler); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	var callbacks []thevent.HandlerResult
+	done := make(chan struct{})
+	if err := e.DispatchAsyncWithCallback(context.Background(), 1, func(hr thevent.HandlerResult) {
+		callbacks = append(callbacks, hr)
+	}, func() { close(done) }); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	<-done
+	if len(callbacks) != 1 || callbacks[0].Err != nil {
+		t.Error("Shadow handler results should not be passed to callbacks, got:", callbacks)
+	}
+	if hr := <-recorded; hr.Err != shadowErr {
+		t.Error("Got shadow error:", hr.Err, "instead of:", shadowErr)
+	}
+}
+
+func shadowPrimaryHandler(ctx context.Context, i int) error {
+	if i%2 == 1 {
+		return errors.New("odd")
+	}
+	return nil
+}
+
+func TestWithShadowOf(t *testing.T) {
+	e := thevent.Must(thevent.New(0, shadowPrimaryHandler))
+	var mismatches []thevent.ShadowMismatch
+	sub, err := thevent.ShadowMismatched.Subscribe(func(ctx context.Context, m thevent.ShadowMismatch) error {
+		if m.Event == e {
+			mismatches = append(mismatches, m)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal("Unable to subscribe to meta-Event:", err)
+	}
+	defer sub.Unsubscribe()
+	// The rewritten handler only fails for 3
+	if err := e.AddHandlerWithOptions(func(ctx context.Context, i int) error {
+		if i == 3 {
+			return errors.New("odd")
+		}
+		return nil
+	}, thevent.WithShadowOf(shadowPrimaryHandler, nil)); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		if err := e.Dispatch(ctx, i); err != nil && i%2 == 0 {
+			t.Error("Unexpected error dispatching:", err)
+		}
+	}
+	if len(mismatches) != 1 {
+		t.Fatal("Expected 1 mismatch, got:", mismatches)
+	}
+	if m := mismatches[0]; m.Data != 1 || m.Primary.Err == nil || m.Shadow.Err != nil ||
+		m.Diff != `error: "odd" != ""` {
+		t.Error("Got unexpected mismatch:", m)
+	}
+
+	errorMatchesGlob(t, e.AddHandlerWithOptions(func(ctx context.Context, i int) error { return nil },
+		thevent.WithShadowOf(nil, nil)), "Primary handler must not be nil")
+	errorMatchesGlob(t, e.AddHandlerWithOptions(func(ctx context.Context, i int) error { return nil },
+		thevent.WithShadowOf(1, nil)), "Handler must be a func, not: int")
+}
+
+func TestCompareResults(t *testing.T) {
+	testCases := []struct {
+		name            string
+		primary, shadow thevent.HandlerResult
+		expected        string
+	}{
+		{name: "match", primary: thevent.HandlerResult{Err: errors.New("a")},
+			shadow: thevent.HandlerResult{Err: errors.New("a")}},
+		{name: "error", primary: thevent.HandlerResult{Err: errors.New("a")},
+			shadow: thevent.HandlerResult{Err: errors.New("b")}, expected: `error: "a" != "b"`},
+		{name: "outcome", primary: thevent.HandlerResult{Outcome: thevent.Outcome{Handled: true}},
+			expected: "outcome: {Handled:true SkipChildren:false Retryable:false StopPropagation:false Skipped:false} != " +
+				"{Handled:false SkipChildren:false Retryable:false StopPropagation:false Skipped:false}"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := thevent.CompareResults(tc.primary, tc.shadow); diff != tc.expected {
+				t.Error("Got diff:", diff, "instead of:", tc.expected)
+			}
+		})
+	}
+}
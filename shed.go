@@ -0,0 +1,138 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrShed is returned when a dispatch is dropped by the LoadShedder of the Event's Bus
+var ErrShed = errors.New("Event shed")
+
+// Priority classifies an Event for load shedding and for scheduling its handlers. See LoadShedder and WorkerPool
+type Priority uint8
+
+const (
+	// Normal Events are shed once Optional Events are being shed and the load increases further
+	Normal Priority = iota
+	// Optional Events are the first to be shed
+	Optional
+	// Critical Events are never shed
+	Critical
+)
+
+func (p Priority) String() string {
+	switch p {
+	case Normal:
+		return "normal"
+	case Optional:
+		return "optional"
+	case Critical:
+		return "critical"
+	}
+	return "unknown"
+}
+
+// WithPriority sets the Priority of the Event used for load shedding and by WorkerPools. Events are Normal by
+// default. A sub-Event is shed based on its own Priority, so an Optional sub-Event of a Critical Event may still be
+// shed.
+func WithPriority(p Priority) Option {
+	return func(e *Event) error {
+		if p > Critical {
+			return TypeError{errors.New("Unknown priority")}
+		}
+		e.priority = p
+		return nil
+	}
+}
+
+// ShedLevel is the level of load shedding of a LoadShedder
+type ShedLevel uint32
+
+const (
+	// ShedNone doesn't shed any Events
+	ShedNone ShedLevel = iota
+	// ShedOptional sheds Optional Events
+	ShedOptional
+	// ShedNormal sheds Optional and Normal Events
+	ShedNormal
+)
+
+// ShedStats are the number of dispatches of each Priority that have been shed
+type ShedStats struct {
+	Optional uint64
+	Normal   uint64
+}
+
+// LoadShedder drops the dispatches of Events by their Priority while the system is overloaded, so that the
+// dispatches of more important Events are still handled. Critical Events are never shed. Dispatches of shed Events
+// fail with ErrShed without running any handlers, and the sub-Events of a dispatched Event are skipped if shed.
+// The shed level is set using SetLevel() or Watch(). See WithLoadShedder()
+type LoadShedder struct {
+	level    uint32
+	optional uint64
+	normal   uint64
+}
+
+// NewLoadShedder creates a new LoadShedder which isn't shedding any Events
+func NewLoadShedder() *LoadShedder {
+	return &LoadShedder{}
+}
+
+// WithLoadShedder configures the Bus to shed the dispatches of its Events, including sub-Events, using the
+// LoadShedder
+func WithLoadShedder(s *LoadShedder) BusOption {
+	return func(b *Bus) {
+		b.shedder = s
+	}
+}
+
+// SetLevel sets the shed level
+func (s *LoadShedder) SetLevel(level ShedLevel) {
+	atomic.StoreUint32(&s.level, uint32(level))
+}
+
+// Level gets the shed level
+func (s *LoadShedder) Level() ShedLevel {
+	return ShedLevel(atomic.LoadUint32(&s.level))
+}
+
+// Watch sets the shed level to the level returned by detect every interval until the context is done. e.g. based on
+// the number of goroutines or the latency of a downstream service.
+func (s *LoadShedder) Watch(ctx context.Context, interval time.Duration, detect func() ShedLevel) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		s.SetLevel(detect())
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Stats gets the number of dispatches that have been shed
+func (s *LoadShedder) Stats() ShedStats {
+	return ShedStats{Optional: atomic.LoadUint64(&s.optional), Normal: atomic.LoadUint64(&s.normal)}
+}
+
+// shed returns true and counts the dispatch if a dispatch of the given Priority should be shed
+func (s *LoadShedder) shed(p Priority) bool {
+	level := s.Level()
+	switch {
+	case p == Optional && level >= ShedOptional:
+		atomic.AddUint64(&s.optional, 1)
+		return true
+	case p == Normal && level >= ShedNormal:
+		atomic.AddUint64(&s.normal, 1)
+		return true
+	}
+	return false
+}
+
+// shed returns true if the dispatch of the Event should be shed
+func (e *Event) shed() bool {
+	return e.bus != nil && e.bus.shedder != nil && e.bus.shedder.shed(e.priority)
+}
@@ -0,0 +1,113 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestLoadShedder(t *testing.T) {
+	shedder := thevent.NewLoadShedder()
+	bus := thevent.NewBus(thevent.WithLoadShedder(shedder))
+	calls := map[string]int{}
+	newEvent := func(name string, p thevent.Priority) *thevent.Event {
+		e, err := bus.New(name, testStruct{}, thevent.WithPriority(p))
+		if err != nil {
+			t.Fatal("Unable to create event:", err)
+		}
+		if err := e.AddHandlers(func(ctx context.Context, s testStruct) error { // nolint: unparam
+			calls[name]++
+			return nil
+		}); err != nil {
+			t.Fatal("Unable to add handler:", err)
+		}
+		return e
+	}
+	critical := newEvent("critical", thevent.Critical)
+	normal := newEvent("normal", thevent.Normal)
+	optional := newEvent("optional", thevent.Optional)
+	optionalChild, err := critical.New(testStruct{}, "", thevent.WithPriority(thevent.Optional))
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if err := optionalChild.AddHandlers(func(ctx context.Context, s testStruct) error { // nolint: unparam
+		calls["optionalChild"]++
+		return nil
+	}); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+
+	ctx := context.Background()
+	dispatchAll := func() map[string]error {
+		errs := map[string]error{}
+		for name, e := range map[string]*thevent.Event{"critical": critical, "normal": normal, "optional": optional} {
+			errs[name] = e.Dispatch(ctx, testStruct{})
+		}
+		return errs
+	}
+
+	testCases := []struct {
+		level         thevent.ShedLevel
+		expectedShed  []string
+		expectedCalls map[string]int
+		expectedStats thevent.ShedStats
+	}{
+		{level: thevent.ShedNone,
+			expectedCalls: map[string]int{"critical": 1, "normal": 1, "optional": 1, "optionalChild": 1}},
+		{level: thevent.ShedOptional, expectedShed: []string{"optional"},
+			expectedCalls: map[string]int{"critical": 1, "normal": 1},
+			expectedStats: thevent.ShedStats{Optional: 2}},
+		{level: thevent.ShedNormal, expectedShed: []string{"optional", "normal"},
+			expectedCalls: map[string]int{"critical": 1},
+			expectedStats: thevent.ShedStats{Optional: 4, Normal: 1}},
+	}
+	for _, tc := range testCases {
+		calls = map[string]int{}
+		shedder.SetLevel(tc.level)
+		errs := dispatchAll()
+		for _, name := range tc.expectedShed {
+			if errs[name] != thevent.ErrShed {
+				t.Error("Expected", name, "to be shed at level", tc.level, "got:", errs[name])
+			}
+			delete(errs, name)
+		}
+		for name, err := range errs {
+			if err != nil {
+				t.Error("Unexpected error dispatching", name, "at level", tc.level, ":", err)
+			}
+		}
+		if len(calls) != len(tc.expectedCalls) {
+			t.Error("Unexpected calls at level", tc.level, ":", calls)
+		}
+		for name, n := range tc.expectedCalls {
+			if calls[name] != n {
+				t.Error("Unexpected calls at level", tc.level, ":", calls)
+			}
+		}
+		if stats := shedder.Stats(); stats != tc.expectedStats {
+			t.Error("Unexpected stats at level", tc.level, ":", stats)
+		}
+	}
+
+	_, err = thevent.New(testStruct{}, thevent.WithPriority(thevent.Priority(10)))
+	errorMatchesGlob(t, err, "Unknown priority")
+}
+
+func TestLoadShedderWatch(t *testing.T) {
+	shedder := thevent.NewLoadShedder()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		shedder.Watch(ctx, time.Millisecond, func() thevent.ShedLevel { return thevent.ShedNormal })
+	}()
+	for shedder.Level() != thevent.ShedNormal {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,107 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log"
+	"reflect"
+)
+
+// EventInfo describes the Event a handler result is for
+type EventInfo struct {
+	// Name is the name the Event is registered with on a Bus. Name is empty for unregistered Events and sub-Events.
+	Name     string
+	DataType reflect.Type
+}
+
+// String returns the name of the Event, if it's registered, or the Event's data type
+func (i EventInfo) String() string {
+	if i.Name != "" {
+		return i.Name
+	}
+	return i.DataType.String()
+}
+
+// ResultSink records the result of every handler of an Event, including those run by Dispatch and DispatchAsync,
+// so the results are observable without using the WithResults dispatches. Record is called from the goroutine
+// running the handler, so implementations must be safe for concurrent use.
+type ResultSink interface {
+	Record(ctx context.Context, event EventInfo, res HandlerResult)
+}
+
+// ResultSinkFunc is an adapter to allow the use of a function as a ResultSink
+type ResultSinkFunc func(ctx context.Context, event EventInfo, res HandlerResult)
+
+// Record calls f(ctx, event, res)
+func (f ResultSinkFunc) Record(ctx context.Context, event EventInfo, res HandlerResult) {
+	f(ctx, event, res)
+}
+
+// WithResultSink configures the Event to record the result of every one of its handlers with the ResultSink
+func WithResultSink(sink ResultSink) Option {
+	return func(e *Event) error {
+		if sink == nil {
+			return TypeError{errors.New("ResultSink must not be nil")}
+		}
+		e.resultSinks = append(e.resultSinks, sink)
+		return nil
+	}
+}
+
+// WithBusResultSink configures the Bus to record the result of every handler of its Events and their sub-Events
+// with the ResultSink
+func WithBusResultSink(sink ResultSink) BusOption {
+	return func(b *Bus) {
+		b.resultSinks = append(b.resultSinks, sink)
+	}
+}
+
+// recordResult records the handler's result with the Event's and the Bus's ResultSinks
+func (e *Event) recordResult(ctx context.Context, hr HandlerResult) {
+	var busSinks []ResultSink
+	if e.bus != nil {
+		busSinks = e.bus.resultSinks
+	}
+	if len(e.resultSinks) == 0 && len(busSinks) == 0 {
+		return
+	}
+	info := EventInfo{Name: e.busName, DataType: e.dataType}
+	for _, sink := range e.resultSinks {
+		sink.Record(ctx, info, hr)
+	}
+	for _, sink := range busSinks {
+		sink.Record(ctx, info, hr)
+	}
+}
+
+// LogSink logs the result of every handler which returns an error with the logger. The results of all of the
+// handlers are logged if verbose is true.
+func LogSink(logger *log.Logger, verbose bool) ResultSink {
+	return ResultSinkFunc(func(ctx context.Context, event EventInfo, res HandlerResult) {
+		name := res.Name
+		if name == "" {
+			name = handlerName(res.Handler)
+		}
+		if res.Err != nil && res.RegisteredAt != "" {
+			logger.Printf("thevent: event %s handler %s registered at %s failed: %v", event, name, res.RegisteredAt,
+				res.Err)
+		} else if res.Err != nil {
+			logger.Printf("thevent: event %s handler %s failed: %v", event, name, res.Err)
+		} else if verbose {
+			logger.Printf("thevent: event %s handler %s succeeded", event, name)
+		}
+	})
+}
+
+// ExpvarSink counts the results of the handlers of every Event in the expvar.Map. The number of handlers run and
+// the number of handlers which returned an error are counted with the keys "<event>.calls" and "<event>.errors".
+func ExpvarSink(m *expvar.Map) ResultSink {
+	return ResultSinkFunc(func(ctx context.Context, event EventInfo, res HandlerResult) {
+		name := event.String()
+		m.Add(name+".calls", 1)
+		if res.Err != nil {
+			m.Add(name+".errors", 1)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package thevent_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+type recordingSink struct {
+	lock    sync.Mutex
+	results []string
+}
+
+func (s *recordingSink) Record(ctx context.Context, event thevent.EventInfo, res thevent.HandlerResult) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.results = append(s.results, event.String()+":"+fmt.Sprint(res.Err))
+}
+
+func TestResultSinks(t *testing.T) {
+	ok := func(ctx context.Context, s testStruct) error { return nil }
+	failing := func(ctx context.Context, s testStruct) error { return errors.New("failed") }
+
+	_, err := thevent.New(testStruct{}, thevent.WithResultSink(nil))
+	errorMatchesGlob(t, err, "ResultSink must not be nil")
+
+	eventSink, busSink := &recordingSink{}, &recordingSink{}
+	b := thevent.NewBus(thevent.WithBusResultSink(busSink))
+	e, err := b.New("orders", testStruct{}, ok, thevent.WithResultSink(eventSink))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if _, err := e.New(testStruct{}, "", failing); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if err := e.DispatchAsync(context.Background(), testStruct{}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if err := e.Barrier(context.Background()); err != nil {
+		t.Fatal("Unexpected error waiting for handlers:", err)
+	}
+
+	if len(eventSink.results) != 1 || eventSink.results[0] != "orders:<nil>" {
+		t.Error("Unexpected event sink results:", eventSink.results)
+	}
+	sort.Strings(busSink.results)
+	if len(busSink.results) != 2 || busSink.results[0] != "orders:<nil>" ||
+		busSink.results[1] != "thevent_test.testStruct:failed" {
+		t.Error("Unexpected bus sink results:", busSink.results)
+	}
+
+	var buf bytes.Buffer
+	m := new(expvar.Map).Init()
+	logged, err := thevent.New(testStruct{}, ok, failing, thevent.WithResultSink(thevent.LogSink(log.New(&buf, "", 0),
+		false)), thevent.WithResultSink(thevent.ExpvarSink(m)))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if err := logged.Dispatch(context.Background(), testStruct{}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 1 ||
+		!strings.HasPrefix(lines[0], "thevent: event thevent_test.testStruct handler ") ||
+		!strings.HasSuffix(lines[0], " failed: failed") {
+		t.Error("Unexpected log output:", buf.String())
+	}
+	if calls, errs := m.Get("thevent_test.testStruct.calls"), m.Get("thevent_test.testStruct.errors"); calls == nil ||
+		calls.String() != "2" || errs == nil || errs.String() != "1" {
+		t.Error("Unexpected metrics:", m.String())
+	}
+}
@@ -0,0 +1,122 @@
+package thevent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SizeMeasurer measures the size in bytes of event data once serialized. See JSONSize
+type SizeMeasurer func(data interface{}) (int, error)
+
+// byteCounter is an io.Writer counting the bytes written to it without buffering them
+type byteCounter int
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// JSONSize is a SizeMeasurer measuring the size of the data encoded as JSON. The encoded data is counted as it's
+// written, so it's never held in memory.
+func JSONSize(data interface{}) (int, error) {
+	var c byteCounter
+	if err := json.NewEncoder(&c).Encode(data); err != nil {
+		return 0, err
+	}
+	// json.Encoder terminates every value with a newline
+	return int(c) - 1, nil
+}
+
+// DataTooLargeError is returned when dispatching an Event with data larger than the maximum size configured using
+// WithMaxDataSize()
+type DataTooLargeError struct {
+	Size int
+	Max  int
+}
+
+func (e DataTooLargeError) Error() string {
+	return fmt.Sprintf("Event data size %d bytes exceeds maximum: %d bytes", e.Size, e.Max)
+}
+
+// OversizedData is the event data for the DataOversized meta-Event
+type OversizedData struct {
+	Event     *Event
+	Data      Data
+	Size      int
+	Threshold int
+}
+
+// DataOversized is dispatched when an Event is dispatched with data larger than the threshold configured on the
+// Event using WithDataSizeWarning()
+var DataOversized = newMetaEvent(OversizedData{})
+
+// sizeGuard checks the size of the data of every dispatch of an Event
+type sizeGuard struct {
+	limit   int
+	measure SizeMeasurer
+	// warn dispatches the DataOversized meta-Event instead of failing the dispatch
+	warn bool
+}
+
+func newSizeGuard(limit int, measure SizeMeasurer, warn bool) (sizeGuard, error) {
+	if limit <= 0 {
+		return sizeGuard{}, TypeError{errors.New("Data size limit must be positive")}
+	}
+	if measure == nil {
+		measure = JSONSize
+	}
+	return sizeGuard{limit: limit, measure: measure, warn: warn}, nil
+}
+
+// WithMaxDataSize fails dispatches of the Event with a DataTooLargeError if the data is larger than max bytes once
+// measured, before any handlers are run, e.g. so huge data isn't copied to every handler. If measure is nil, the
+// data is measured using JSONSize. Measuring the data serializes it on every dispatch, so the measurer should be
+// cheap compared to the handlers.
+//
+// Asynchronous dispatches on a Sequential Bus are measured once they're run, so their failures are reported using
+// the DispatchFailed meta-Event.
+func WithMaxDataSize(max int, measure SizeMeasurer) Option {
+	return func(e *Event) error {
+		g, err := newSizeGuard(max, measure, false)
+		if err != nil {
+			return err
+		}
+		e.sizeGuards = append(e.sizeGuards, g)
+		return nil
+	}
+}
+
+// WithDataSizeWarning dispatches the DataOversized meta-Event whenever the Event is dispatched with data larger than
+// threshold bytes once measured. The dispatch isn't affected, so a threshold may be rolled out to find oversized
+// data before enforcing it using WithMaxDataSize(). If measure is nil, the data is measured using JSONSize.
+func WithDataSizeWarning(threshold int, measure SizeMeasurer) Option {
+	return func(e *Event) error {
+		g, err := newSizeGuard(threshold, measure, true)
+		if err != nil {
+			return err
+		}
+		e.sizeGuards = append(e.sizeGuards, g)
+		return nil
+	}
+}
+
+// checkDataSize checks the size of the data against the Event's size guards. Data which can't be measured fails the
+// dispatch.
+func (e *Event) checkDataSize(ctx context.Context, data interface{}) error {
+	for _, g := range e.sizeGuards {
+		size, err := g.measure(data)
+		if err != nil {
+			return fmt.Errorf("Unable to measure event data size: %v", err)
+		}
+		if size <= g.limit {
+			continue
+		}
+		if !g.warn {
+			return DataTooLargeError{Size: size, Max: g.limit}
+		}
+		e.dispatchMeta(ctx, DataOversized, OversizedData{Event: e, Data: data, Size: size, Threshold: g.limit})
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestJSONSize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     interface{}
+		expected int
+	}{
+		{name: "int", data: 42, expected: 2},
+		{name: "string", data: "abc", expected: 5},
+		{name: "struct", data: fixtureUser{ID: 1, Name: "a"}, expected: len(`{"ID":1,"Name":"a"}`)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if size, err := thevent.JSONSize(tc.data); err != nil || size != tc.expected {
+				t.Error("Got size:", size, err, "instead of:", tc.expected)
+			}
+		})
+	}
+	if _, err := thevent.JSONSize(func() {}); err == nil {
+		t.Error("Expected error measuring unserializable data")
+	}
+}
+
+func TestWithMaxDataSize(t *testing.T) {
+	if _, err := thevent.New("", thevent.WithMaxDataSize(0, nil)); err == nil {
+		t.Error("Created event with an invalid max data size")
+	}
+
+	called := 0
+	handler := func(ctx context.Context, s string) error { // nolint: unparam
+		called++
+		return nil
+	}
+	e := thevent.Must(thevent.New("", handler, thevent.WithMaxDataSize(10, nil)))
+	ctx := context.Background()
+	if err := e.Dispatch(ctx, "small"); err != nil {
+		t.Error("Unexpected error dispatching:", err)
+	}
+	err := e.Dispatch(ctx, strings.Repeat("a", 20))
+	if tooLarge, ok := err.(thevent.DataTooLargeError); !ok || tooLarge.Size != 22 || tooLarge.Max != 10 {
+		t.Error("Expected DataTooLargeError, got:", err)
+	}
+	errorMatchesGlob(t, err, "Event data size 22 bytes exceeds maximum: 10 bytes")
+	if called != 1 {
+		t.Error("Handler should only be called for data within the limit, called:", called)
+	}
+
+	failing := thevent.Must(thevent.New("", thevent.WithMaxDataSize(10, func(interface{}) (int, error) {
+		return 0, errors.New("unmeasurable")
+	})))
+	errorMatchesGlob(t, failing.Dispatch(ctx, "a"), "Unable to measure event data size: unmeasurable")
+}
+
+func TestWithDataSizeWarning(t *testing.T) {
+	if _, err := thevent.New("", thevent.WithDataSizeWarning(-1, nil)); err == nil {
+		t.Error("Created event with an invalid data size warning threshold")
+	}
+
+	called := 0
+	handler := func(ctx context.Context, s string) error { // nolint: unparam
+		called++
+		return nil
+	}
+	length := func(data interface{}) (int, error) { return len(data.(string)), nil }
+	e := thevent.Must(thevent.New("", handler, thevent.WithDataSizeWarning(3, length)))
+	var oversized []thevent.OversizedData
+	if err := thevent.DataOversized.AddHandlers(func(ctx context.Context, o thevent.OversizedData) error {
+		if o.Event == e {
+			oversized = append(oversized, o)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal("Unable to add handler to meta-Event:", err)
+	}
+
+	ctx := context.Background()
+	for _, s := range []string{"abc", "abcd"} {
+		if err := e.Dispatch(ctx, s); err != nil {
+			t.Error("Unexpected error dispatching:", err)
+		}
+	}
+	if called != 2 {
+		t.Error("Oversized data should still be handled, called:", called)
+	}
+	if len(oversized) != 1 || oversized[0].Data != "abcd" || oversized[0].Size != 4 || oversized[0].Threshold != 3 {
+		t.Error("Got unexpected oversized data:", oversized)
+	}
+}
@@ -0,0 +1,144 @@
+package thevent
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// number of buckets used to track the rolling SLO compliance
+const sloBuckets = 10
+
+// SLO is a service level objective for the handlers of an Event. A handler run is good if it succeeds and, if a
+// latency target is set, runs within the latency target.
+type SLO struct {
+	// Target is the fraction of handler runs which should be good. e.g. 0.999
+	Target float64
+	// Latency is the latency target of a handler run, if set
+	Latency time.Duration
+	// Window is the rolling window the SLO is tracked over
+	Window time.Duration
+}
+
+// SLOStatus is an Event's compliance with its SLO over the SLO's rolling window
+type SLOStatus struct {
+	Event *Event
+	SLO   SLO
+	// Total is the number of handler runs in the window
+	Total uint
+	// Failed is the number of handler runs which returned an error
+	Failed uint
+	// Slow is the number of successful handler runs which missed the latency target
+	Slow uint
+	// SuccessRate is the fraction of good handler runs. SuccessRate is 1.0 if there are no handler runs.
+	SuccessRate float64
+	// BudgetRemaining is the fraction of the error budget, i.e. the allowed fraction of bad handler runs, which
+	// remains. BudgetRemaining is negative once the budget is overspent.
+	BudgetRemaining float64
+}
+
+// Exhausted returns true if the error budget has been spent
+func (s SLOStatus) Exhausted() bool {
+	return s.BudgetRemaining <= 0
+}
+
+type sloBucket struct {
+	epoch  int64
+	total  uint
+	failed uint
+	slow   uint
+}
+
+// sloTracker tracks an Event's rolling SLO compliance using fixed size time buckets
+type sloTracker struct {
+	lock        sync.Mutex
+	event       *Event
+	slo         SLO
+	onExhausted func(SLOStatus)
+	now         func() time.Time
+
+	buckets [sloBuckets]sloBucket
+	// exhausted is true while the error budget is exhausted so onExhausted is only called when the budget is
+	// exhausted
+	exhausted bool
+}
+
+// WithSLO configures the Event to track the rolling compliance of its handlers with the SLO, which is available
+// using Event.SLOStatus(). onExhausted, if not nil, is called whenever the error budget becomes exhausted and is
+// called again only after the budget has recovered. onExhausted is called synchronously by the goroutine running the
+// handler and should not block. The results of all dispatches are tracked.
+func WithSLO(slo SLO, onExhausted func(SLOStatus)) Option {
+	return func(e *Event) error {
+		if slo.Target <= 0.0 || slo.Target >= 1.0 {
+			return TypeError{errors.New("SLO target must be between 0.0 and 1.0 exclusive")}
+		}
+		if slo.Latency < 0 {
+			return TypeError{errors.New("SLO latency target must not be negative")}
+		}
+		if slo.Window < sloBuckets {
+			return TypeError{errors.New("SLO window is too small")}
+		}
+		e.slo = &sloTracker{event: e, slo: slo, onExhausted: onExhausted, now: time.Now}
+		return nil
+	}
+}
+
+// SLOStatus gets the Event's current compliance with its SLO. false is returned if the Event isn't configured with
+// an SLO using WithSLO().
+func (e *Event) SLOStatus() (SLOStatus, bool) {
+	if e.slo == nil {
+		return SLOStatus{}, false
+	}
+	t := e.slo
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.status(t.epoch()), true
+}
+
+func (t *sloTracker) epoch() int64 {
+	return t.now().UnixNano() / int64(t.slo.Window/sloBuckets)
+}
+
+// status computes the SLO compliance. The lock must be held.
+func (t *sloTracker) status(epoch int64) SLOStatus {
+	s := SLOStatus{Event: t.event, SLO: t.slo}
+	for _, b := range t.buckets {
+		if b.epoch > epoch-sloBuckets {
+			s.Total += b.total
+			s.Failed += b.failed
+			s.Slow += b.slow
+		}
+	}
+	if s.Total == 0 {
+		s.SuccessRate, s.BudgetRemaining = 1.0, 1.0
+		return s
+	}
+	bad := float64(s.Failed + s.Slow)
+	s.SuccessRate = 1.0 - bad/float64(s.Total)
+	s.BudgetRemaining = 1.0 - bad/(float64(s.Total)*(1.0-t.slo.Target))
+	return s
+}
+
+func (t *sloTracker) record(err error, d time.Duration) {
+	t.lock.Lock()
+	epoch := t.epoch()
+	b := &t.buckets[epoch%sloBuckets]
+	if b.epoch != epoch {
+		*b = sloBucket{epoch: epoch}
+	}
+	b.total++
+	if err != nil {
+		b.failed++
+	} else if t.slo.Latency > 0 && d > t.slo.Latency {
+		b.slow++
+	}
+	s := t.status(epoch)
+	exhausted := s.Exhausted()
+	crossed := exhausted && !t.exhausted
+	t.exhausted = exhausted
+	t.lock.Unlock()
+
+	if crossed && t.onExhausted != nil {
+		t.onExhausted(s)
+	}
+}
@@ -0,0 +1,103 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithSLOInvalid(t *testing.T) {
+	testCases := []struct {
+		name        string
+		slo         SLO
+		expectedErr string
+	}{
+		{name: "target too low", slo: SLO{Target: 0, Window: time.Minute},
+			expectedErr: "SLO target must be between 0.0 and 1.0 exclusive"},
+		{name: "target too high", slo: SLO{Target: 1, Window: time.Minute},
+			expectedErr: "SLO target must be between 0.0 and 1.0 exclusive"},
+		{name: "negative latency", slo: SLO{Target: 0.9, Latency: -1, Window: time.Minute},
+			expectedErr: "SLO latency target must not be negative"},
+		{name: "window too small", slo: SLO{Target: 0.9, Window: 1}, expectedErr: "SLO window is too small"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(false, WithSLO(tc.slo, nil))
+			if err == nil || err.Error() != tc.expectedErr {
+				t.Error("Expected error:", tc.expectedErr, "got:", err)
+			}
+		})
+	}
+}
+
+func TestWithSLO(t *testing.T) {
+	var exhausted []SLOStatus
+	var delay time.Duration
+	handler := func(ctx context.Context, fail bool) error {
+		time.Sleep(delay)
+		if fail {
+			return errors.New("handler failed")
+		}
+		return nil
+	}
+	slo := SLO{Target: 0.8, Latency: 5 * time.Millisecond, Window: 5 * time.Minute}
+	e, err := New(false, handler, WithSLO(slo, func(s SLOStatus) { exhausted = append(exhausted, s) }))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if _, ok := Must(New(false)).SLOStatus(); ok {
+		t.Error("Event without SLO should not have an SLO status")
+	}
+	now := time.Unix(0, 0)
+	e.slo.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	dispatch := func(fail bool) {
+		if err := e.Dispatch(ctx, fail); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	}
+	if s, ok := e.SLOStatus(); !ok || s.SuccessRate != 1.0 || s.BudgetRemaining != 1.0 {
+		t.Error("Unexpected initial status:", s)
+	}
+	for i := 0; i < 9; i++ {
+		dispatch(false)
+	}
+	dispatch(true) // 1/10 bad - half of the budget spent
+	s, _ := e.SLOStatus()
+	if s.Event != e || s.SLO != slo || s.Total != 10 || s.Failed != 1 || s.Slow != 0 || s.Exhausted() {
+		t.Error("Unexpected status:", s)
+	}
+	if s.SuccessRate < 0.899 || s.SuccessRate > 0.901 || s.BudgetRemaining < 0.499 || s.BudgetRemaining > 0.501 {
+		t.Error("Unexpected rates:", s)
+	}
+	delay = 10 * time.Millisecond
+	dispatch(false) // 2/11 bad - budget not spent
+	delay = 0
+	if len(exhausted) != 0 {
+		t.Fatal("Got unexpected exhaustion:", exhausted)
+	}
+	dispatch(true) // 3/12 bad - budget exhausted
+	if len(exhausted) != 1 {
+		t.Fatal("Expected budget to be exhausted, got:", exhausted)
+	}
+	if s := exhausted[0]; s.Total != 12 || s.Failed != 2 || s.Slow != 1 || !s.Exhausted() {
+		t.Error("Unexpected exhausted status:", s)
+	}
+	dispatch(true) // still exhausted - not called again
+	if len(exhausted) != 1 {
+		t.Fatal("Expected 1 exhaustion, got:", exhausted)
+	}
+
+	// runs fall out of the window
+	now = now.Add(10 * time.Minute)
+	if s, _ := e.SLOStatus(); s.Total != 0 || s.Exhausted() {
+		t.Error("Unexpected status:", s)
+	}
+	dispatch(false)
+	dispatch(true) // 1/2 bad - exhausted again
+	if len(exhausted) != 2 {
+		t.Fatal("Expected 2 exhaustions, got:", exhausted)
+	}
+}
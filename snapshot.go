@@ -0,0 +1,76 @@
+package thevent
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Snapshotter may be implemented by a stateful handler, e.g. a projection, so that its state may be saved and
+// restored by the Bus. e.g. to avoid replaying every event on restart or to rebuild a projection from a known point.
+// See Healther for how a handler may implement Snapshotter.
+type Snapshotter interface {
+	// Snapshot serializes the state of the handler
+	Snapshot(ctx context.Context) ([]byte, error)
+	// Restore replaces the state of the handler with a state serialized by Snapshot()
+	Restore(ctx context.Context, snapshot []byte) error
+}
+
+// BusSnapshot is the state of every handler implementing Snapshotter on a Bus. Each state is keyed by the name of
+// the Event and the position of the handler among the Event's Snapshotters, including those of its sub-Events.
+// e.g. "orders/0"
+type BusSnapshot map[string][]byte
+
+// Snapshot gets the state of every handler implementing Snapshotter of the registered Events and their sub-Events.
+// The first error is returned. No Events should be dispatched while the snapshot is taken for the snapshot to be
+// consistent.
+func (b *Bus) Snapshot(ctx context.Context) (BusSnapshot, error) {
+	snapshot := BusSnapshot{}
+	err := b.eachSnapshotter(func(key string, s Snapshotter) error {
+		state, err := s.Snapshot(ctx)
+		if err != nil {
+			return fmt.Errorf("Unable to snapshot handler %s: %v", key, err)
+		}
+		snapshot[key] = state
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Restore restores the state of every handler implementing Snapshotter of the registered Events and their
+// sub-Events from the snapshot. Handlers without a state in the snapshot are left as they are. The first error is
+// returned. No Events should be dispatched while the snapshot is restored.
+func (b *Bus) Restore(ctx context.Context, snapshot BusSnapshot) error {
+	return b.eachSnapshotter(func(key string, s Snapshotter) error {
+		state, ok := snapshot[key]
+		if !ok {
+			return nil
+		}
+		if err := s.Restore(ctx, state); err != nil {
+			return fmt.Errorf("Unable to restore handler %s: %v", key, err)
+		}
+		return nil
+	})
+}
+
+// eachSnapshotter calls fn for every handler implementing Snapshotter in registration order, stopping on the first
+// error
+func (b *Bus) eachSnapshotter(fn func(key string, s Snapshotter) error) error {
+	names, events := b.registered()
+	for i, e := range events {
+		n := 0
+		for _, h := range e.allHandlers() {
+			if h.snapshotter == nil {
+				continue
+			}
+			if err := fn(names[i]+"/"+strconv.Itoa(n), h.snapshotter); err != nil {
+				return err
+			}
+			n++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+type snapshotKey struct{}
+
+type restoreKey struct{}
+
+// projectionHandler snapshots and restores its state by being called with the snapshot in the context
+type projectionHandler func(ctx context.Context, s testStruct) error
+
+func (h projectionHandler) Snapshot(ctx context.Context) ([]byte, error) {
+	var state []byte
+	err := h(context.WithValue(ctx, snapshotKey{}, &state), testStruct{})
+	return state, err
+}
+
+func (h projectionHandler) Restore(ctx context.Context, snapshot []byte) error {
+	return h(context.WithValue(ctx, restoreKey{}, snapshot), testStruct{})
+}
+
+func newCounter(count *int) projectionHandler {
+	return func(ctx context.Context, s testStruct) error {
+		if state, ok := ctx.Value(snapshotKey{}).(*[]byte); ok {
+			*state = []byte(strconv.Itoa(*count))
+			return nil
+		}
+		if state, ok := ctx.Value(restoreKey{}).([]byte); ok {
+			n, err := strconv.Atoi(string(state))
+			if err != nil {
+				return err
+			}
+			*count = n
+			return nil
+		}
+		*count += s.v
+		return nil
+	}
+}
+
+func TestBusSnapshotAndRestore(t *testing.T) {
+	var a, b int
+	bus := thevent.NewBus()
+	e, err := bus.New("a", testStruct{}, newCounter(&a), func(ctx context.Context, s testStruct) error { return nil })
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if _, err := e.New(testStruct{}, "", newCounter(&b)); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	ctx := context.Background()
+	if err := e.Dispatch(ctx, testStruct{v: 2}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	snapshot, err := bus.Snapshot(ctx)
+	if err != nil {
+		t.Fatal("Unable to snapshot:", err)
+	}
+	if len(snapshot) != 2 || string(snapshot["a/0"]) != "2" || string(snapshot["a/1"]) != "2" {
+		t.Error("Unexpected snapshot:", snapshot)
+	}
+	if err := e.Dispatch(ctx, testStruct{v: 3}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	delete(snapshot, "a/1")
+	if err := bus.Restore(ctx, snapshot); err != nil {
+		t.Fatal("Unable to restore:", err)
+	}
+	if a != 2 || b != 5 {
+		t.Error("Unexpected restored state:", a, b)
+	}
+
+	err = bus.Restore(ctx, thevent.BusSnapshot{"a/0": []byte("x")})
+	errorMatchesGlob(t, err, "Unable to restore handler a/0: *invalid syntax")
+	if _, err := bus.New("failing", testStruct{}, projectionHandler(func(ctx context.Context, s testStruct) error {
+		return errors.New("disk full")
+	})); err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	_, err = bus.Snapshot(ctx)
+	errorMatchesGlob(t, err, "Unable to snapshot handler failing/0: disk full")
+}
@@ -0,0 +1,74 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// ErrStaleContext is the error of an asynchronously run handler skipped since the context.Context of its dispatch
+// was done before the handler started. See WithStaleContextPolicy()
+var ErrStaleContext = errors.New("Handler skipped since the dispatch's context is done")
+
+// StaleContextPolicy configures how an asynchronously run handler is run if the context.Context of its dispatch,
+// e.g. the context.Context of the request which dispatched the Event, is done before the handler starts
+type StaleContextPolicy uint8
+
+const (
+	// StaleContextInherit runs the handler with the done context.Context
+	StaleContextInherit StaleContextPolicy = iota
+	// StaleContextDetach runs the handler with a context.Context which has the values of the done context.Context,
+	// but isn't canceled and has no deadline
+	StaleContextDetach
+	// StaleContextSkip skips the handler, whose error is ErrStaleContext
+	StaleContextSkip
+)
+
+// WithStaleContextPolicy configures how the Event's asynchronously run handlers are run if the context.Context of
+// the dispatch is done before they start, e.g. since the request dispatching the Event finished. By default,
+// handlers inherit the done context.Context.
+func WithStaleContextPolicy(p StaleContextPolicy) Option {
+	return func(e *Event) error {
+		if p > StaleContextSkip {
+			return TypeError{errors.New("Unknown stale context policy")}
+		}
+		e.staleContextPolicy = p
+		return nil
+	}
+}
+
+// freshContext gets the context.Context to run an asynchronously run handler with according to the Event's
+// StaleContextPolicy. false is returned if the handler should be skipped.
+func (e *Event) freshContext(ctx context.Context) (context.Context, bool) {
+	if ctx.Err() == nil {
+		return ctx, true
+	}
+	switch e.staleContextPolicy {
+	case StaleContextDetach:
+		return DetachContext(ctx), true
+	case StaleContextSkip:
+		return ctx, false
+	}
+	return ctx, true
+}
+
+// callFresh calls the asynchronously run handler according to the Event's StaleContextPolicy
+func (e *Event) callFresh(ctx context.Context, h *handler, data reflect.Value, turn uint64) HandlerResult {
+	ctx, ok := e.freshContext(ctx)
+	if !ok {
+		if h.ordered != nil {
+			h.ordered.wait(turn)
+			h.ordered.done()
+		}
+		hr := HandlerResult{Event: e, Handler: h.fn.Interface(), Name: h.name, Labels: h.labels,
+			Err: ErrStaleContext, Disposition: Failed, RegisteredAt: h.registeredAt}
+		if h.shadow != nil {
+			h.shadow(hr)
+			return hr
+		}
+		e.recordResult(ctx, hr)
+		return hr
+	}
+	_, hr := e.callHandlerInTurn(ctx, h, []reflect.Value{reflect.ValueOf(ctx), data}, turn)
+	return hr
+}
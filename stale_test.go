@@ -0,0 +1,62 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+type staleCtxKey struct{}
+
+func TestWithStaleContextPolicy(t *testing.T) {
+	if _, err := thevent.New(0, thevent.WithStaleContextPolicy(10)); err == nil {
+		t.Error("Created event with an unknown stale context policy")
+	}
+
+	testCases := []struct {
+		name        string
+		policy      thevent.StaleContextPolicy
+		expectedErr error
+		called      bool
+		ctxErr      error
+	}{
+		{name: "inherit", policy: thevent.StaleContextInherit, called: true, ctxErr: context.Canceled},
+		{name: "detach", policy: thevent.StaleContextDetach, called: true},
+		{name: "skip", policy: thevent.StaleContextSkip, expectedErr: thevent.ErrStaleContext},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+			var ctxErr error
+			var value interface{}
+			handler := func(ctx context.Context, i int) error { // nolint: unparam
+				called = true
+				ctxErr, value = ctx.Err(), ctx.Value(staleCtxKey{})
+				return nil
+			}
+			e := thevent.Must(thevent.New(0, handler, thevent.WithStaleContextPolicy(tc.policy)))
+			ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), staleCtxKey{}, "v"),
+				time.Hour)
+			cancel()
+			ch, err := e.DispatchAsyncWithResults(ctx, 1)
+			if err != nil {
+				t.Fatal("Unexpected error dispatching:", err)
+			}
+			for err := range ch {
+				if err != tc.expectedErr {
+					t.Error("Got handler error:", err, "instead of:", tc.expectedErr)
+				}
+			}
+			if called != tc.called {
+				t.Fatal("Expected handler to be called:", tc.called)
+			}
+			if called && (ctxErr != tc.ctxErr || value != "v") {
+				t.Error("Handler got unexpected context.Context:", ctxErr, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package thevent
+
+import (
+	"context"
+	"iter"
+)
+
+// DispatchStream synchronously dispatches the Event and sub-Events like Dispatch, but lazily streams the result of
+// every handler as it finishes running. Breaking out of the range loop skips the remaining handlers and cancels the
+// context.Context passed to the handlers. A dispatch error, e.g. a TypeError, is yielded with an empty
+// HandlerResult.
+//
+// Example:
+//
+//	for res, err := range event.DispatchStream(ctx, data) {
+//	    if err != nil || res.Err != nil {
+//	        break
+//	    }
+//	}
+func (e *Event) DispatchStream(ctx context.Context, data interface{},
+	opts ...DispatchOption) iter.Seq2[HandlerResult, error] {
+	return func(yield func(HandlerResult, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		d := newDispatchState(false, false, opts)
+		d.onResult = func(res HandlerResult) bool {
+			if !yield(res, nil) {
+				cancel()
+				return false
+			}
+			return true
+		}
+		_, _, err := e.dispatchRoot(ctx, d, data)
+		e.reportDispatchErr(ctx, data, err)
+		if err != nil && !d.stopped {
+			yield(HandlerResult{}, err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestDispatchStream(t *testing.T) {
+	var called []string
+	var canceled bool
+	handlerA := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		called = append(called, "A")
+		return nil
+	}
+	handlerB := func(ctx context.Context, s testStruct) error {
+		called = append(called, "B")
+		return errors.New("handler B failed")
+	}
+	childHandler := func(ctx context.Context, s testStruct) error { // nolint: unparam
+		called = append(called, "child")
+		canceled = ctx.Err() != nil
+		return nil
+	}
+	e, err := thevent.New(testStruct{}, handlerA, handlerB)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	child, err := e.New(testStruct{}, "", childHandler)
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	ctx := context.Background()
+
+	t.Run("all results", func(t *testing.T) {
+		called = nil
+		var results []thevent.HandlerResult
+		for res, err := range e.DispatchStream(ctx, testStruct{}) {
+			if err != nil {
+				t.Fatal("Unexpected error dispatching:", err)
+			}
+			results = append(results, res)
+		}
+		if len(results) != 3 || len(called) != 3 {
+			t.Fatal("Expected 3 handler results, got:", results)
+		}
+		if results[0].Event != e || results[0].Err != nil {
+			t.Error("Got unexpected result:", results[0])
+		}
+		if results[1].Event != e || results[1].Err == nil {
+			t.Error("Got unexpected result:", results[1])
+		}
+		if results[2].Event != child || results[2].Err != nil || canceled {
+			t.Error("Got unexpected result:", results[2])
+		}
+	})
+
+	t.Run("break", func(t *testing.T) {
+		called = nil
+		for res := range e.DispatchStream(ctx, testStruct{}) {
+			if res.Err != nil {
+				break
+			}
+		}
+		if len(called) != 2 {
+			t.Error("Expected the remaining handlers to be skipped, called:", called)
+		}
+	})
+
+	t.Run("dispatch error", func(t *testing.T) {
+		var errs []error
+		for _, err := range e.DispatchStream(ctx, 5) {
+			errs = append(errs, err)
+		}
+		if len(errs) != 1 {
+			t.Fatal("Expected 1 dispatch error, got:", errs)
+		}
+		errorMatchesGlob(t, errs[0], "Dispatch called with incorrect event data type. Expected: * Got: int")
+	})
+}
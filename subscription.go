@@ -0,0 +1,48 @@
+package thevent
+
+// Subscription is a handler subscribed to an Event using Event.Subscribe()
+type Subscription struct {
+	event   *Event
+	handler *handler
+}
+
+// Subscribe adds the Handler configured with the HandlerOptions to the Event, and returns a Subscription which
+// removes the handler when it's unsubscribed. e.g. for closures, which can't be removed using RemoveHandlers(), or
+// for listeners scoped to a connection. Every Subscription is distinct, so the same Handler may be subscribed
+// multiple times.
+func (e *Event) Subscribe(h Handler, opts ...HandlerOption) (*Subscription, error) {
+	return e.subscribe(nil, h, opts)
+}
+
+// subscribe subscribes the handler to the Event. owner is the Capability used to subscribe the handler, if any.
+func (e *Event) subscribe(owner *Capability, h Handler, opts []HandlerOption) (*Subscription, error) {
+	cH, err := e.newHandlerWithOptions(h, opts)
+	if err != nil {
+		return nil, err
+	}
+	s := &Subscription{event: e, handler: cH}
+	cH.subscription = s
+	if err := e.addHandlers(owner, []*handler{cH}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Unsubscribe removes the handler from the Event. In-flight dispatches aren't affected. Unsubscribing more than once
+// is a no-op.
+func (s *Subscription) Unsubscribe() {
+	s.event.removeHandler(s.handler)
+}
+
+// Active returns true until the Subscription is unsubscribed or its Event is destroyed
+func (s *Subscription) Active() bool {
+	e := s.event
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	for _, h := range e.handlers {
+		if h == s.handler {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestSubscribe(t *testing.T) {
+	e := thevent.Must(thevent.New(0))
+	var received []int
+	subscribe := func(id int) *thevent.Subscription {
+		s, err := e.Subscribe(func(ctx context.Context, i int) error { // nolint: unparam
+			received = append(received, id)
+			return nil
+		})
+		if err != nil {
+			t.Fatal("Unable to subscribe:", err)
+		}
+		return s
+	}
+	// Closures created by the same function literal may be subscribed multiple times
+	first, second := subscribe(1), subscribe(2)
+	if !first.Active() || !second.Active() {
+		t.Error("Subscriptions should be active")
+	}
+
+	ctx := context.Background()
+	if err := e.Dispatch(ctx, 0); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	first.Unsubscribe()
+	first.Unsubscribe()
+	if first.Active() || !second.Active() {
+		t.Error("Only the unsubscribed Subscription should be inactive")
+	}
+	if err := e.Dispatch(ctx, 0); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(received) != 3 || received[0] != 1 || received[1] != 2 || received[2] != 2 {
+		t.Error("Unexpected received dispatches:", received)
+	}
+
+	_, err := e.Subscribe(func(ctx context.Context, s string) error { return nil })
+	errorMatchesGlob(t, err, "Handler uses incorrect data type.*")
+	e.Destroy()
+	if second.Active() {
+		t.Error("Subscriptions of a destroyed Event should be inactive")
+	}
+	second.Unsubscribe()
+}
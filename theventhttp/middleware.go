@@ -0,0 +1,118 @@
+// Package theventhttp provides HTTP middleware which dispatches thevent Events for the lifecycle of requests
+package theventhttp
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+// Names of the Events registered on the Bus by Middleware()
+const (
+	RequestStartedEvent  = "http.RequestStarted"
+	RequestFinishedEvent = "http.RequestFinished"
+)
+
+// RequestStarted is the event data dispatched before a request is handled
+type RequestStarted struct {
+	Method     string
+	Path       string
+	RemoteAddr string
+	Start      time.Time
+}
+
+// RequestFinished is the event data dispatched after a request is handled
+type RequestFinished struct {
+	Method     string
+	Path       string
+	RemoteAddr string
+	// Status is the status code of the response
+	Status int
+	// Bytes is the number of bytes written in the response body
+	Bytes    int64
+	Duration time.Duration
+}
+
+// Middleware creates HTTP middleware which synchronously dispatches the RequestStarted and RequestFinished Events
+// around every request, so access logging, metrics and auditing may be handled by the Events' handlers. The Events
+// are registered on the Bus with the names RequestStartedEvent and RequestFinishedEvent, unless they've already been
+// registered. Dispatches use the request's context.Context and dispatch errors are reported using the
+// DispatchFailed meta-Event.
+func Middleware(bus *thevent.Bus) (func(http.Handler) http.Handler, error) {
+	started, err := event(bus, RequestStartedEvent, RequestStarted{})
+	if err != nil {
+		return nil, err
+	}
+	finished, err := event(bus, RequestFinishedEvent, RequestFinished{})
+	if err != nil {
+		return nil, err
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			start := time.Now()
+			started.Dispatch(ctx, RequestStarted{Method: r.Method, Path: r.URL.Path, // nolint: errcheck
+				RemoteAddr: r.RemoteAddr, Start: start})
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rw, r)
+			finished.Dispatch(ctx, RequestFinished{Method: r.Method, Path: r.URL.Path, // nolint: errcheck
+				RemoteAddr: r.RemoteAddr, Status: rw.status, Bytes: rw.bytes, Duration: time.Since(start)})
+		})
+	}, nil
+}
+
+// event gets the Event registered on the Bus with the given name, registering it if needed
+func event(bus *thevent.Bus, name string, data interface{}) (*thevent.Event, error) {
+	if e, ok := bus.Event(name); ok {
+		return e, thevent.CheckData(e, data)
+	}
+	return bus.New(name, data)
+}
+
+// responseWriter records the status code and the size of the response
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher if the wrapped http.ResponseWriter does
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker if the wrapped http.ResponseWriter does, e.g. for websockets
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	return h.Hijack()
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, so that http.ResponseController may use its optional methods
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
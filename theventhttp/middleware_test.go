@@ -0,0 +1,124 @@
+package theventhttp_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+	"github.com/dhui/thevent/theventhttp"
+)
+
+func TestMiddleware(t *testing.T) {
+	bus := thevent.NewBus()
+	mw, err := theventhttp.Middleware(bus)
+	if err != nil {
+		t.Fatal("Unable to create middleware:", err)
+	}
+	var started []theventhttp.RequestStarted
+	var finished []theventhttp.RequestFinished
+	startedEvent, _ := bus.Event(theventhttp.RequestStartedEvent)
+	finishedEvent, _ := bus.Event(theventhttp.RequestFinishedEvent)
+	if err := startedEvent.AddHandlers(func(ctx context.Context, r theventhttp.RequestStarted) error {
+		started = append(started, r)
+		return nil
+	}); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	if err := finishedEvent.AddHandlers(func(ctx context.Context, r theventhttp.RequestFinished) error {
+		finished = append(finished, r)
+		return nil
+	}); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(started) != 1 {
+			t.Error("RequestStarted should be dispatched before the request is handled")
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created")) // nolint: errcheck
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+
+	if len(started) != 1 || started[0].Method != http.MethodPost || started[0].Path != "/orders" {
+		t.Error("Unexpected RequestStarted events:", started)
+	}
+	if len(finished) != 1 || finished[0].Status != http.StatusCreated || finished[0].Bytes != 7 ||
+		finished[0].Path != "/orders" {
+		t.Error("Unexpected RequestFinished events:", finished)
+	}
+
+	if _, err := theventhttp.Middleware(bus); err != nil {
+		t.Error("Unable to reuse registered events:", err)
+	}
+	conflicting := thevent.NewBus()
+	if _, err := conflicting.New(theventhttp.RequestStartedEvent, 0); err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if _, err := theventhttp.Middleware(conflicting); err == nil {
+		t.Error("Expected an error using a registered event with the wrong data type")
+	}
+}
+
+func TestMiddlewareResponseWriter(t *testing.T) {
+	mw, err := theventhttp.Middleware(thevent.NewBus())
+	if err != nil {
+		t.Fatal("Unable to create middleware:", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/hijack", mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			t.Error("Wrapped http.ResponseWriter doesn't implement http.Hijacker")
+			return
+		}
+		conn, buf, err := hj.Hijack()
+		if err != nil {
+			t.Error("Unable to hijack connection:", err)
+			return
+		}
+		defer conn.Close() // nolint: errcheck
+		res := "HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked"
+		if _, err := buf.WriteString(res); err != nil {
+			t.Error("Unable to write response:", err)
+		}
+		if err := buf.Flush(); err != nil {
+			t.Error("Unable to write response:", err)
+		}
+	})))
+	mux.Handle("/controller", mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Deadlines are only supported by the server's http.ResponseWriter, so they require unwrapping
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(time.Minute)); err != nil {
+			t.Error("Unable to set write deadline:", err)
+		}
+		w.Write([]byte("unwrapped")) // nolint: errcheck
+	})))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for path, expected := range map[string]string{"/hijack": "hijacked", "/controller": "unwrapped"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal("Unable to send request:", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close() // nolint: errcheck
+		if err != nil || string(body) != expected {
+			t.Error("Unexpected response to", path, ":", string(body), err)
+		}
+	}
+
+	// Hijacking fails if the wrapped http.ResponseWriter doesn't support it
+	mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := w.(http.Hijacker).Hijack(); !errors.Is(err, http.ErrNotSupported) {
+			t.Error("Expected hijacking to be unsupported, got:", err)
+		}
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,123 @@
+// Package theventmiddleware provides common thevent handler Middleware. e.g.
+//
+//	e, err := thevent.New(OrderCreated{}, thevent.WithMiddleware(
+//		theventmiddleware.Log(logger),
+//		theventmiddleware.Timeout(5*time.Second),
+//		theventmiddleware.Recover(),
+//	))
+package theventmiddleware
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+// Recover recovers from handler panics, returning a thevent.PanicError as the handler's error instead. Middleware
+// running before Recover, e.g. Timeout, is not protected.
+func Recover() thevent.Middleware {
+	return func(ctx context.Context, call thevent.HandlerCall, next thevent.Next) (outcome thevent.Outcome,
+		err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				outcome, err = thevent.Outcome{}, thevent.PanicError{Value: r, Stack: debug.Stack(),
+					RegisteredAt: call.RegisteredAt}
+			}
+		}()
+		return next(ctx)
+	}
+}
+
+// Log logs every run of a handler with the logger, including its duration and error
+func Log(logger *log.Logger) thevent.Middleware {
+	return func(ctx context.Context, call thevent.HandlerCall, next thevent.Next) (thevent.Outcome, error) {
+		start := time.Now()
+		outcome, err := next(ctx)
+		d := time.Since(start)
+		name := call.Name
+		if name == "" {
+			name = handlerName(call.Handler)
+		}
+		if err != nil {
+			logger.Printf("thevent: handler %s of event %s failed after %v: %v", name, call.Event, d, err)
+		} else {
+			logger.Printf("thevent: handler %s of event %s succeeded after %v", name, call.Event, d)
+		}
+		return outcome, err
+	}
+}
+
+// Metrics records the durations of handler runs. Implementations must be safe for concurrent use.
+type Metrics interface {
+	ObserveHandler(call thevent.HandlerCall, d time.Duration, err error)
+}
+
+// MetricsFunc is an adapter to allow the use of a function as Metrics
+type MetricsFunc func(call thevent.HandlerCall, d time.Duration, err error)
+
+// ObserveHandler calls f(call, d, err)
+func (f MetricsFunc) ObserveHandler(call thevent.HandlerCall, d time.Duration, err error) {
+	f(call, d, err)
+}
+
+// Timing records the duration of every run of a handler with the metrics
+func Timing(metrics Metrics) thevent.Middleware {
+	return func(ctx context.Context, call thevent.HandlerCall, next thevent.Next) (thevent.Outcome, error) {
+		start := time.Now()
+		outcome, err := next(ctx)
+		metrics.ObserveHandler(call, time.Since(start), err)
+		return outcome, err
+	}
+}
+
+// TimeoutError is returned by Timeout when a handler doesn't finish in time. It's the same type as
+// thevent.TimeoutError, which is returned by thevent.WithTimeout().
+type TimeoutError = thevent.TimeoutError
+
+// Timeout cancels the context.Context passed to the handler after the timeout and returns a transient
+// TimeoutError if the handler hasn't finished by then. A handler ignoring the cancelation keeps running in the
+// background, but its results are discarded.
+func Timeout(timeout time.Duration) thevent.Middleware {
+	return func(ctx context.Context, call thevent.HandlerCall, next thevent.Next) (thevent.Outcome, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		type result struct {
+			outcome thevent.Outcome
+			err     error
+		}
+		// Buffered so that a handler finishing after the timeout doesn't leak the goroutine
+		done := make(chan result, 1)
+		go func() {
+			outcome, err := next(ctx)
+			done <- result{outcome, err}
+		}()
+		select {
+		case r := <-done:
+			return r.outcome, r.err
+		case <-ctx.Done():
+			if ctx.Err() != context.DeadlineExceeded {
+				return thevent.Outcome{}, ctx.Err()
+			}
+			return thevent.Outcome{}, thevent.Transient(TimeoutError{Timeout: timeout})
+		}
+	}
+}
+
+// handlerName gets the name of the handler's function
+func handlerName(h thevent.Handler) string {
+	v := reflect.ValueOf(h)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	if f := runtime.FuncForPC(v.Pointer()); f != nil {
+		return f.Name()
+	}
+	return ""
+}
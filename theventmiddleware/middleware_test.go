@@ -0,0 +1,114 @@
+package theventmiddleware_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+	"github.com/dhui/thevent/theventmiddleware"
+)
+
+type order struct {
+	ID int
+}
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New(&buf, "", 0)
+	var timings []string
+	metrics := theventmiddleware.MetricsFunc(func(call thevent.HandlerCall, d time.Duration, err error) {
+		timings = append(timings, call.Event.String())
+	})
+	var results []thevent.HandlerResult
+	sink := thevent.ResultSinkFunc(func(ctx context.Context, _ thevent.EventInfo, res thevent.HandlerResult) {
+		results = append(results, res)
+	})
+	e, err := thevent.New(order{}, thevent.WithResultSink(sink), thevent.WithMiddleware(
+		theventmiddleware.Log(logger),
+		theventmiddleware.Timing(metrics),
+		theventmiddleware.Timeout(20*time.Millisecond),
+		theventmiddleware.Recover(),
+	))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if err := e.AddHandlers(
+		func(ctx context.Context, o order) error { return nil },
+		func(ctx context.Context, o order) error { return errors.New("failed") },
+		func(ctx context.Context, o order) error { panic("boom") },
+		func(ctx context.Context, o order) error {
+			<-ctx.Done()
+			return nil
+		},
+		func(ctx context.Context, o order) (thevent.Outcome, error) { return thevent.Outcome{}, thevent.Handled },
+	); err != nil {
+		t.Fatal("Unable to add handlers:", err)
+	}
+	if err := e.Dispatch(context.Background(), order{ID: 1}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+
+	if len(results) != 5 {
+		t.Fatal("Unexpected results:", results)
+	}
+	if results[0].Err != nil {
+		t.Error("Unexpected error:", results[0].Err)
+	}
+	if results[1].Err == nil || results[1].Err.Error() != "failed" {
+		t.Error("Unexpected error:", results[1].Err)
+	}
+	if _, ok := results[2].Err.(thevent.PanicError); !ok {
+		t.Errorf("Expected PanicError, got: %#v", results[2].Err)
+	}
+	if err := results[3].Err; !thevent.IsTransient(err) || err.Error() != "Handler timed out after 20ms" {
+		t.Error("Expected timeout, got:", err)
+	}
+	var timeoutErr thevent.TimeoutError
+	if !errors.As(results[3].Err, &timeoutErr) || timeoutErr.Timeout != 20*time.Millisecond {
+		t.Error("Expected thevent.TimeoutError, got:", results[3].Err)
+	}
+	if results[4].Err != nil || !results[4].Outcome.Handled {
+		t.Error("Expected handled outcome, got:", results[4])
+	}
+	if len(timings) != 5 || timings[0] != "theventmiddleware_test.order" {
+		t.Error("Unexpected timings:", timings)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []string{
+		"thevent: handler github.com/dhui/thevent/theventmiddleware_test.TestMiddleware.func? of event theventmiddleware_test.order succeeded after *",
+		"thevent: handler github.com/dhui/thevent/theventmiddleware_test.TestMiddleware.func? of event theventmiddleware_test.order failed after *: failed",
+		"thevent: handler github.com/dhui/thevent/theventmiddleware_test.TestMiddleware.func? of event theventmiddleware_test.order failed after *: " +
+			"Handler panicked: boom",
+		"thevent: handler github.com/dhui/thevent/theventmiddleware_test.TestMiddleware.func? of event theventmiddleware_test.order failed after *: " +
+			"Handler timed out after 20ms",
+		"thevent: handler github.com/dhui/thevent/theventmiddleware_test.TestMiddleware.func? of event theventmiddleware_test.order succeeded after *",
+	}
+	if len(lines) != len(expected) {
+		t.Fatal("Unexpected log:", buf.String())
+	}
+	for i, line := range lines {
+		// Builds with the thevent_debug build tag also report where the handler was registered
+		line = registeredAt.ReplaceAllString(line, "")
+		if ok, _ := path.Match(expected[i], line); !ok {
+			t.Errorf("Log line %q does not match %q", line, expected[i])
+		}
+	}
+}
+
+var registeredAt = regexp.MustCompile(` registered at \S+`)
+
+func TestWithMiddlewareNil(t *testing.T) {
+	if _, err := thevent.New(order{}, thevent.WithMiddleware(nil)); err == nil ||
+		err.Error() != "Middleware must not be nil" {
+		t.Error("Unexpected error:", err)
+	}
+}
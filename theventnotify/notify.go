@@ -0,0 +1,144 @@
+// Package theventnotify provides ready-made thevent handlers which send notifications using webhooks, Slack and
+// email
+package theventnotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"strings"
+)
+
+// sendMail is replaced in tests
+var sendMail = smtp.SendMail
+
+// WebhookConfig configures a webhook notification
+type WebhookConfig struct {
+	URL string
+	// Method defaults to POST
+	Method string
+	Header http.Header
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// Webhook creates a handler which sends a request to the webhook for every dispatch. body creates the request body
+// from the event data. The event data is encoded as JSON if body is nil. A response with a non-2xx status code is
+// returned as an error.
+func Webhook[T any](cfg WebhookConfig, body func(T) ([]byte, error)) func(context.Context, T) error {
+	if body == nil {
+		body = func(data T) ([]byte, error) { return json.Marshal(data) }
+	}
+	return func(ctx context.Context, data T) error {
+		b, err := body(data)
+		if err != nil {
+			return err
+		}
+		return cfg.send(ctx, b)
+	}
+}
+
+func (cfg WebhookConfig) send(ctx context.Context, body []byte) error {
+	method := cfg.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequest(method, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	for k, vs := range cfg.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := cfg.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Webhook responded with status: %s %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// SlackConfig configures a Slack notification sent using an incoming webhook
+type SlackConfig struct {
+	WebhookURL string
+	// Channel and Username override the webhook's defaults, if set
+	Channel  string
+	Username string
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// Slack creates a handler which posts a message to Slack for every dispatch. text creates the message from the
+// event data.
+func Slack[T any](cfg SlackConfig, text func(T) string) func(context.Context, T) error {
+	webhook := WebhookConfig{URL: cfg.WebhookURL, Client: cfg.Client}
+	return func(ctx context.Context, data T) error {
+		b, err := json.Marshal(struct {
+			Text     string `json:"text"`
+			Channel  string `json:"channel,omitempty"`
+			Username string `json:"username,omitempty"`
+		}{Text: text(data), Channel: cfg.Channel, Username: cfg.Username})
+		if err != nil {
+			return err
+		}
+		return webhook.send(ctx, b)
+	}
+}
+
+// SMTPConfig configures an email notification
+type SMTPConfig struct {
+	// Addr is the address of the SMTP server. e.g. "smtp.example.com:587"
+	Addr string
+	// Auth is optional
+	Auth smtp.Auth
+	From string
+	To   []string
+}
+
+// Email creates a handler which sends an email for every dispatch. message creates the subject and the plain text
+// body of the email from the event data. The subject is encoded, so it may contain any characters. The
+// context.Context is only checked before the email is sent.
+func Email[T any](cfg SMTPConfig, message func(T) (subject, body string)) func(context.Context, T) error {
+	return func(ctx context.Context, data T) error {
+		if len(cfg.To) == 0 {
+			return errors.New("Email has no recipients")
+		}
+		// Line breaks would allow injecting headers or recipients
+		for _, addr := range append([]string{cfg.From}, cfg.To...) {
+			if strings.ContainsAny(addr, "\r\n") {
+				return fmt.Errorf("Email address contains a line break: %q", addr)
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		subject, body := message(data)
+		var msg bytes.Buffer
+		fmt.Fprintf(&msg, "From: %s\r\n", cfg.From)
+		fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(cfg.To, ", "))
+		fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+		msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+		msg.WriteString(body)
+		return sendMail(cfg.Addr, cfg.Auth, cfg.From, cfg.To, msg.Bytes())
+	}
+}
@@ -0,0 +1,109 @@
+package theventnotify
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+type orderPlaced struct {
+	ID int `json:"id"`
+}
+
+func TestWebhook(t *testing.T) {
+	var gotBody, gotHeader string
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody, gotHeader = string(b), r.Header.Get("X-Token")
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	handler := Webhook[orderPlaced](WebhookConfig{URL: srv.URL, Header: http.Header{"X-Token": {"secret"}}}, nil)
+	e := thevent.Must(thevent.New(orderPlaced{}, handler))
+	if err := e.Dispatch(context.Background(), orderPlaced{ID: 1}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if gotBody != `{"id":1}` || gotHeader != "secret" {
+		t.Error("Unexpected request. Body:", gotBody, "X-Token:", gotHeader)
+	}
+
+	status = http.StatusInternalServerError
+	if err := handler(context.Background(), orderPlaced{ID: 2}); err == nil ||
+		!strings.HasPrefix(err.Error(), "Webhook responded with status: 500") {
+		t.Error("Expected status error, got:", err)
+	}
+}
+
+func TestSlack(t *testing.T) {
+	var gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+	}))
+	defer srv.Close()
+
+	handler := Slack(SlackConfig{WebhookURL: srv.URL, Channel: "#orders"}, func(o orderPlaced) string {
+		return "Order placed"
+	})
+	if err := handler(context.Background(), orderPlaced{ID: 1}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if gotBody != `{"text":"Order placed","channel":"#orders"}` {
+		t.Error("Unexpected Slack message:", gotBody)
+	}
+}
+
+func TestEmail(t *testing.T) {
+	var gotAddr string
+	var gotTo []string
+	var gotMsg string
+	sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotTo, gotMsg = addr, to, string(msg)
+		return nil
+	}
+	defer func() { sendMail = smtp.SendMail }()
+
+	message := func(o orderPlaced) (string, string) { return "Order placed", "An order was placed" }
+	handler := Email(SMTPConfig{Addr: "smtp.example.com:587", From: "a@example.com", To: []string{"b@example.com"}},
+		message)
+	if err := handler(context.Background(), orderPlaced{ID: 1}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if gotAddr != "smtp.example.com:587" || len(gotTo) != 1 || !strings.Contains(gotMsg, "Subject: Order placed\r\n") ||
+		!strings.HasSuffix(gotMsg, "\r\n\r\nAn order was placed") {
+		t.Error("Unexpected email:", gotAddr, gotTo, gotMsg)
+	}
+
+	noRecipients := Email(SMTPConfig{Addr: "smtp.example.com:587"}, message)
+	if err := noRecipients(context.Background(), orderPlaced{}); err == nil ||
+		err.Error() != "Email has no recipients" {
+		t.Error("Expected no recipients error, got:", err)
+	}
+
+	injected := Email(SMTPConfig{Addr: "smtp.example.com:587", From: "a@example.com",
+		To: []string{"b@example.com\r\nBcc: c@example.com"}}, message)
+	if err := injected(context.Background(), orderPlaced{}); err == nil ||
+		!strings.HasPrefix(err.Error(), "Email address contains a line break") {
+		t.Error("Expected line break error, got:", err)
+	}
+
+	gotMsg = ""
+	handler = Email(SMTPConfig{Addr: "smtp.example.com:587", From: "a@example.com", To: []string{"b@example.com"}},
+		func(o orderPlaced) (string, string) { return "Order\r\nBcc: c@example.com", "" })
+	if err := handler(context.Background(), orderPlaced{}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if strings.Contains(gotMsg, "\r\nBcc:") || !strings.Contains(gotMsg, "Subject: =?utf-8?q?") {
+		t.Error("Subject wasn't encoded:", gotMsg)
+	}
+}
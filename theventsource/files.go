@@ -0,0 +1,187 @@
+// Package theventsource provides event sources which dispatch thevent Events, e.g. when files change
+package theventsource
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+// FileOp is the operation which changed a file
+type FileOp uint8
+
+const (
+	// Created means the file was created
+	Created FileOp = iota
+	// Modified means the file's contents, size or mode changed
+	Modified
+	// Removed means the file was removed
+	Removed
+)
+
+func (op FileOp) String() string {
+	switch op {
+	case Created:
+		return "created"
+	case Modified:
+		return "modified"
+	case Removed:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// FileChanged is the event data dispatched by WatchFiles()
+type FileChanged struct {
+	Path string
+	Op   FileOp
+	// ModTime is the file's modification time. ModTime is zero for removed files.
+	ModTime time.Time
+}
+
+// FileWatcherConfig configures WatchFiles()
+type FileWatcherConfig struct {
+	// Paths are the files and directories to watch. The files in a directory are watched, but not the files in its
+	// subdirectories.
+	Paths []string
+	// PollInterval defaults to 1 second
+	PollInterval time.Duration
+	// Debounce delays dispatching a change until the file hasn't changed for the duration, so a burst of writes is
+	// dispatched once. Changes aren't debounced by default.
+	Debounce time.Duration
+	// Clock defaults to RealClock()
+	Clock Clock
+}
+
+// WatchFiles watches the paths for changes and dispatches a FileChanged event for every change. e must be an Event
+// with FileChanged data. The paths are polled so no platform specific APIs are required. WatchFiles blocks until
+// the context.Context is done and returns the context.Context's error. Dispatch errors are reported using the
+// DispatchFailed meta-Event.
+func WatchFiles(ctx context.Context, e *thevent.Event, cfg FileWatcherConfig) error {
+	if err := thevent.CheckData(e, FileChanged{}); err != nil {
+		return err
+	}
+	if len(cfg.Paths) == 0 {
+		return errors.New("No paths to watch")
+	}
+	interval := cfg.PollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	clock := cfg.Clock
+	if clock == nil {
+		clock = RealClock()
+	}
+	w := fileWatcher{paths: cfg.Paths, debounce: cfg.Debounce, pending: map[string]pendingChange{}}
+	w.files = w.scan()
+	t := clock.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-t.C():
+			for _, c := range w.poll(now) {
+				e.Dispatch(ctx, c) // nolint: errcheck
+			}
+		}
+	}
+}
+
+// fileWatcher tracks the state of the watched files between polls
+type fileWatcher struct {
+	paths    []string
+	debounce time.Duration
+	files    map[string]os.FileInfo
+	pending  map[string]pendingChange
+}
+
+// pendingChange is a change waiting to be debounced
+type pendingChange struct {
+	change   FileChanged
+	lastSeen time.Time
+}
+
+// scan gets the current state of the watched files
+func (w *fileWatcher) scan() map[string]os.FileInfo {
+	files := map[string]os.FileInfo{}
+	for _, p := range w.paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		if !fi.IsDir() {
+			files[p] = fi
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			if info, err := entry.Info(); err == nil {
+				files[filepath.Join(p, entry.Name())] = info
+			}
+		}
+	}
+	return files
+}
+
+// poll detects the changes since the last poll and returns the changes which are ready to be dispatched
+func (w *fileWatcher) poll(now time.Time) []FileChanged {
+	files := w.scan()
+	for p, fi := range files {
+		old, ok := w.files[p]
+		switch {
+		case !ok:
+			w.changed(now, FileChanged{Path: p, Op: Created, ModTime: fi.ModTime()})
+		case !old.ModTime().Equal(fi.ModTime()) || old.Size() != fi.Size() || old.Mode() != fi.Mode():
+			w.changed(now, FileChanged{Path: p, Op: Modified, ModTime: fi.ModTime()})
+		}
+	}
+	for p := range w.files {
+		if _, ok := files[p]; !ok {
+			w.changed(now, FileChanged{Path: p, Op: Removed})
+		}
+	}
+	w.files = files
+
+	var ready []FileChanged
+	for p, pc := range w.pending {
+		if now.Sub(pc.lastSeen) >= w.debounce {
+			ready = append(ready, pc.change)
+			delete(w.pending, p)
+		}
+	}
+	sort.Slice(ready, func(i, j int) bool { return ready[i].Path < ready[j].Path })
+	return ready
+}
+
+// changed records a change, merging it with a pending change of the same file
+func (w *fileWatcher) changed(now time.Time, c FileChanged) {
+	if pc, ok := w.pending[c.Path]; ok {
+		switch {
+		case pc.change.Op == Created && c.Op == Modified:
+			// Still a newly created file
+			c.Op = Created
+		case pc.change.Op == Removed && c.Op == Created:
+			// Replaced
+			c.Op = Modified
+		case pc.change.Op == Created && c.Op == Removed:
+			// Never dispatched as created
+			delete(w.pending, c.Path)
+			return
+		}
+	}
+	w.pending[c.Path] = pendingChange{change: c, lastSeen: now}
+}
@@ -0,0 +1,111 @@
+package theventsource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestFileWatcherPoll(t *testing.T) {
+	dir, err := os.MkdirTemp("", "theventsource")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	if err := os.WriteFile(a, []byte("a"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	w := fileWatcher{paths: []string{dir}, debounce: time.Second, pending: map[string]pendingChange{}}
+	w.files = w.scan()
+
+	if err := os.WriteFile(a, []byte("changed"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("b"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if changes := w.poll(start); len(changes) != 0 {
+		t.Error("Changes should be debounced:", changes)
+	}
+	if err := os.WriteFile(b, []byte("bb"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if changes := w.poll(start.Add(500 * time.Millisecond)); len(changes) != 0 {
+		t.Error("Changes should be debounced:", changes)
+	}
+	changes := w.poll(start.Add(1600 * time.Millisecond))
+	if len(changes) != 2 || changes[0].Path != a || changes[0].Op != Modified || changes[1].Path != b ||
+		changes[1].Op != Created {
+		t.Error("Unexpected changes:", changes)
+	}
+
+	if err := os.Remove(a); err != nil {
+		t.Fatal(err)
+	}
+	w.debounce = 0
+	if changes := w.poll(start.Add(2 * time.Second)); len(changes) != 1 || changes[0].Path != a ||
+		changes[0].Op != Removed {
+		t.Error("Unexpected changes:", changes)
+	}
+}
+
+func TestWatchFiles(t *testing.T) {
+	dir, err := os.MkdirTemp("", "theventsource")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	var lock sync.Mutex
+	var got []FileChanged
+	e := thevent.Must(thevent.New(FileChanged{}, func(ctx context.Context, c FileChanged) error {
+		lock.Lock()
+		defer lock.Unlock()
+		got = append(got, c)
+		return nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- WatchFiles(ctx, e, FileWatcherConfig{Paths: []string{dir}, PollInterval: time.Millisecond})
+	}()
+	time.Sleep(20 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dir, "config"), []byte("a"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		lock.Lock()
+		n := len(got)
+		lock.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Error("Expected context.Canceled, got:", err)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if len(got) != 1 || got[0].Op != Created || got[0].Path != filepath.Join(dir, "config") {
+		t.Error("Unexpected changes:", got)
+	}
+
+	wrong := thevent.Must(thevent.New(0))
+	if err := WatchFiles(ctx, wrong, FileWatcherConfig{Paths: []string{dir}}); err == nil {
+		t.Error("Expected an error watching files with an event with the wrong data type")
+	}
+}
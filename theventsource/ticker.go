@@ -0,0 +1,54 @@
+package theventsource
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+// Tick is the event data dispatched by RunTicker()
+type Tick struct {
+	Time time.Time
+	// Count is the number of the tick, starting at 1
+	Count uint64
+}
+
+// TickerConfig configures RunTicker()
+type TickerConfig struct {
+	Interval time.Duration
+	// Clock defaults to RealClock()
+	Clock Clock
+}
+
+// RunTicker dispatches a Tick event every interval, so periodic handlers may be added to an Event instead of
+// running their own goroutine loops. e must be an Event with Tick data. Every Tick is dispatched synchronously, so
+// ticks are dropped while the handlers are running. RunTicker blocks until the context.Context is done and returns
+// the context.Context's error. Dispatch errors are reported using the DispatchFailed meta-Event.
+func RunTicker(ctx context.Context, e *thevent.Event, cfg TickerConfig) error {
+	if err := thevent.CheckData(e, Tick{}); err != nil {
+		return err
+	}
+	if cfg.Interval <= 0 {
+		return errors.New("Ticker interval must be positive")
+	}
+	clock := cfg.Clock
+	if clock == nil {
+		clock = RealClock()
+	}
+	t := clock.NewTicker(cfg.Interval)
+	defer t.Stop()
+	var count uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-t.C():
+			count++
+			e.Dispatch(ctx, Tick{Time: now, Count: count}) // nolint: errcheck
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package theventsource
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestRunTicker(t *testing.T) {
+	start := time.Date(2018, 2, 19, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	ticks := make(chan Tick)
+	e := thevent.Must(thevent.New(Tick{}, func(ctx context.Context, tick Tick) error {
+		ticks <- tick
+		return nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- RunTicker(ctx, e, TickerConfig{Interval: time.Minute, Clock: clock}) }()
+
+	// Wait for the ticker to be created
+	for {
+		clock.lock.Lock()
+		n := len(clock.tickers)
+		clock.lock.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for i := 1; i <= 2; i++ {
+		clock.Advance(time.Minute)
+		tick := <-ticks
+		if tick.Count != uint64(i) || !tick.Time.Equal(start.Add(time.Duration(i)*time.Minute)) {
+			t.Error("Unexpected tick:", tick)
+		}
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Error("Expected context.Canceled, got:", err)
+	}
+	clock.lock.Lock()
+	if len(clock.tickers) != 0 {
+		t.Error("Ticker wasn't stopped")
+	}
+	clock.lock.Unlock()
+
+	if err := RunTicker(ctx, e, TickerConfig{}); err == nil || err.Error() != "Ticker interval must be positive" {
+		t.Error("Expected interval error, got:", err)
+	}
+	if err := RunTicker(ctx, thevent.Must(thevent.New(0)), TickerConfig{Interval: time.Minute}); err == nil {
+		t.Error("Expected an error running a ticker with an event with the wrong data type")
+	}
+}
@@ -0,0 +1,109 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// TypedEvent is a type-safe wrapper around an Event whose data is of type T. Handlers and dispatched data are type
+// checked at compile-time instead of at runtime. The wrapped Event may still be used directly. Handlers added using
+// the TypedEvent are called without reflection, so synchronously dispatching pointer data to them doesn't allocate,
+// unless the Event is configured with options requiring allocations, e.g. metadata or a budget.
+type TypedEvent[T any] struct {
+	*Event
+}
+
+// toHandlers converts the handlers to Handlers which are called without reflection, so that synchronously
+// dispatching pointer data to them doesn't allocate
+func toHandlers[T any](handlers []func(context.Context, T) error) []Handler {
+	hs := make([]Handler, 0, len(handlers))
+	for _, h := range handlers {
+		if h == nil {
+			hs = append(hs, h)
+			continue
+		}
+		h := h
+		hs = append(hs, directHandler{handler: h, call: func(ctx context.Context, data interface{}) error {
+			return h(ctx, data.(T))
+		}})
+	}
+	return hs
+}
+
+// NewTyped creates a new TypedEvent. T must not be an interface type.
+func NewTyped[T any](handlers ...func(context.Context, T) error) (*TypedEvent[T], error) {
+	var data T
+	if reflect.TypeOf(data) == nil {
+		return nil, TypeError{errors.New("TypedEvent data type must not be an interface type")}
+	}
+	e, err := New(data, toHandlers(handlers)...)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedEvent[T]{Event: e}, nil
+}
+
+// Sub creates a new TypedEvent that's also dispatched whenever the parent TypedEvent is dispatched. The sub-Event's
+// data is created from the parent's data using project, which replaces the field name mapping used by Event.New().
+// C must not be an interface type.
+func Sub[P, C any](parent *TypedEvent[P], project func(P) C,
+	handlers ...func(context.Context, C) error) (*TypedEvent[C], error) {
+	var data C
+	if reflect.TypeOf(data) == nil {
+		return nil, TypeError{errors.New("TypedEvent data type must not be an interface type")}
+	}
+	if project == nil {
+		return nil, TypeError{errors.New("Sub-Event projection must not be nil")}
+	}
+	e, err := parent.newProjected(nil, data, func(d interface{}) interface{} { return project(d.(P)) },
+		toHandlers(handlers)...)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedEvent[C]{Event: e}, nil
+}
+
+// AddHandlers adds the handlers to the TypedEvent
+func (e *TypedEvent[T]) AddHandlers(handlers ...func(context.Context, T) error) error {
+	return e.Event.AddHandlers(toHandlers(handlers)...)
+}
+
+// RemoveHandlers removes the handlers from the TypedEvent
+func (e *TypedEvent[T]) RemoveHandlers(handlers ...func(context.Context, T) error) error {
+	return e.Event.RemoveHandlers(toHandlers(handlers)...)
+}
+
+// Dispatch is the same as Event.Dispatch
+func (e *TypedEvent[T]) Dispatch(ctx context.Context, data T, opts ...DispatchOption) error {
+	return e.Event.Dispatch(ctx, data, opts...)
+}
+
+// DispatchWithResults is the same as Event.DispatchWithResults
+func (e *TypedEvent[T]) DispatchWithResults(ctx context.Context, data T,
+	opts ...DispatchOption) (*HandlersResults, error) {
+	return e.Event.DispatchWithResults(ctx, data, opts...)
+}
+
+// DispatchAsync is the same as Event.DispatchAsync
+func (e *TypedEvent[T]) DispatchAsync(ctx context.Context, data T, opts ...DispatchOption) error {
+	return e.Event.DispatchAsync(ctx, data, opts...)
+}
+
+// DispatchAsyncWithResults is the same as Event.DispatchAsyncWithResults
+func (e *TypedEvent[T]) DispatchAsyncWithResults(ctx context.Context, data T,
+	opts ...DispatchOption) (<-chan error, error) {
+	return e.Event.DispatchAsyncWithResults(ctx, data, opts...)
+}
+
+// DispatchAsyncWithCallback is the same as Event.DispatchAsyncWithCallback
+func (e *TypedEvent[T]) DispatchAsyncWithCallback(ctx context.Context, data T, onResult func(HandlerResult),
+	onComplete func(), opts ...DispatchOption) error {
+	return e.Event.DispatchAsyncWithCallback(ctx, data, onResult, onComplete, opts...)
+}
+
+// DispatchBatch is the same as Event.DispatchBatch
+func (e *TypedEvent[T]) DispatchBatch(ctx context.Context, data []T, opts ...DispatchOption) (*HandlersResults,
+	error) {
+	return e.Event.dispatchBatch(ctx, len(data), func(i int) interface{} { return data[i] }, opts)
+}
@@ -0,0 +1,55 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+// BenchmarkTypedDispatchPointer should be run with -benchmem to check that synchronously dispatching pointer data
+// to typed handlers doesn't allocate
+func BenchmarkTypedDispatchPointer(b *testing.B) {
+	handler := func(ctx context.Context, o *order) error { return nil }
+	failing := func(ctx context.Context, o *order) error { return errors.New("failed") }
+	for _, bc := range []struct {
+		name     string
+		handlers []func(context.Context, *order) error
+	}{
+		{name: "1handler", handlers: []func(context.Context, *order) error{handler}},
+		{name: "failing", handlers: []func(context.Context, *order) error{failing}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			e, err := thevent.NewTyped(bc.handlers...)
+			if err != nil {
+				b.Fatal("Unable to create event:", err)
+			}
+			ctx := context.Background()
+			o := &order{}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := e.Dispatch(ctx, o); err != nil {
+					b.Error("Error dispatching:", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDispatchPointer benchmarks synchronously dispatching pointer data to handlers called using reflection
+func BenchmarkDispatchPointer(b *testing.B) {
+	e := thevent.Must(thevent.New(&order{}, func(ctx context.Context, o *order) error { return nil }))
+	ctx := context.Background()
+	o := &order{}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := e.Dispatch(ctx, o); err != nil {
+			b.Error("Error dispatching:", err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+type order struct {
+	ID    int
+	Total int
+}
+
+type invoice struct {
+	OrderID int
+	Amount  int
+}
+
+func TestNewTyped(t *testing.T) {
+	if _, err := thevent.NewTyped[error](); err == nil {
+		t.Error("Created TypedEvent with an interface data type")
+	}
+
+	var got []order
+	e, err := thevent.NewTyped(func(ctx context.Context, o order) error {
+		got = append(got, o)
+		return nil
+	})
+	if err != nil {
+		t.Fatal("Unable to create TypedEvent:", err)
+	}
+	if err := e.Dispatch(context.Background(), order{ID: 1}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	res, err := e.DispatchWithResults(context.Background(), order{ID: 2})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if res.NumHandlers != 1 {
+		t.Error("1 handler should have been dispatched, not", res.NumHandlers)
+	}
+	if len(got) != 2 || got[0].ID != 1 || got[1].ID != 2 {
+		t.Error("Handler got unexpected data:", got)
+	}
+}
+
+func TestSub(t *testing.T) {
+	orderCreated, err := thevent.NewTyped[order]()
+	if err != nil {
+		t.Fatal("Unable to create TypedEvent:", err)
+	}
+	if _, err := thevent.Sub[order, invoice](orderCreated, nil); err == nil {
+		t.Error("Created sub-Event without a projection")
+	}
+
+	var got []invoice
+	invoiceCreated, err := thevent.Sub(orderCreated, func(o order) invoice {
+		return invoice{OrderID: o.ID, Amount: o.Total}
+	})
+	if err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+	if err := invoiceCreated.AddHandlers(func(ctx context.Context, i invoice) error {
+		got = append(got, i)
+		return nil
+	}); err != nil {
+		t.Fatal("Unable to add handler:", err)
+	}
+	// non-struct data may be projected
+	var amounts []int
+	if _, err := thevent.Sub(invoiceCreated, func(i invoice) int { return i.Amount },
+		func(ctx context.Context, amount int) error {
+			amounts = append(amounts, amount)
+			return nil
+		}); err != nil {
+		t.Fatal("Unable to create sub-Event:", err)
+	}
+
+	if err := orderCreated.Dispatch(context.Background(), order{ID: 1, Total: 42}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(got) != 1 || got[0] != (invoice{OrderID: 1, Amount: 42}) {
+		t.Error("Sub-Event handler got unexpected data:", got)
+	}
+	if len(amounts) != 1 || amounts[0] != 42 {
+		t.Error("Sub-Event handler got unexpected data:", amounts)
+	}
+}
+
+func TestTypedDispatchPointerAllocs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping allocation test in short mode")
+	}
+	handler := func(ctx context.Context, o *order) error { // nolint: unparam
+		o.Total++
+		return nil
+	}
+	e, err := thevent.NewTyped(handler)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	ctx := context.Background()
+	o := &order{ID: 1}
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := e.Dispatch(ctx, o); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	})
+	if allocs != 0 {
+		t.Error("Expected 0 allocations per dispatch, got:", allocs)
+	}
+	if o.Total != 101 {
+		t.Error("Unexpected number of dispatches:", o.Total)
+	}
+}
+
+func TestDispatchPointerAllocs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping allocation test in short mode")
+	}
+	e := thevent.Must(thevent.New(&order{}, func(ctx context.Context, o *order) error { // nolint: unparam
+		o.Total++
+		return nil
+	}))
+	ctx := context.Background()
+	o := &order{ID: 1}
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := e.Dispatch(ctx, o); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	})
+	// Only reflect.Value.Call allocates, when calling the handler and for its results
+	if allocs > 3 {
+		t.Error("Expected at most 3 allocations per dispatch, got:", allocs)
+	}
+}
+
+func TestTypedDispatchBatch(t *testing.T) {
+	total := 0
+	e, err := thevent.NewTyped(func(ctx context.Context, o order) error { // nolint: unparam
+		total += o.Total
+		return nil
+	})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	res, err := e.DispatchBatch(context.Background(), []order{{Total: 1}, {Total: 2}})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if res.NumHandlers != 2 || total != 3 {
+		t.Error("Unexpected results:", res, total)
+	}
+}
+
+func TestTypedRemoveHandlers(t *testing.T) {
+	called := 0
+	handler := func(ctx context.Context, o order) error { // nolint: unparam
+		called++
+		return nil
+	}
+	e, err := thevent.NewTyped(handler)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if err := e.RemoveHandlers(handler); err != nil {
+		t.Fatal("Unable to remove handler:", err)
+	}
+	if err := e.Dispatch(context.Background(), order{}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if called != 0 {
+		t.Error("Removed handler called")
+	}
+}
@@ -0,0 +1,60 @@
+package thevent
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// HandlerUsage is the approximate resource consumption attributed to a handler in builds with the thevent_debug
+// build tag, e.g. for capacity planning. Allocations are measured process-wide while the handler runs, so handlers
+// running concurrently are attributed each other's allocations. Goroutines counts the goroutines started by the
+// handler which were still running when it returned, e.g. to find handlers leaking goroutines.
+type HandlerUsage struct {
+	Calls      uint64
+	Allocs     uint64
+	AllocBytes uint64
+	Goroutines uint64
+}
+
+// handlerUsage accumulates the HandlerUsage of a handler. The counters are updated atomically.
+type handlerUsage struct {
+	calls      uint64
+	allocs     uint64
+	allocBytes uint64
+	goroutines uint64
+}
+
+// usageSample is the process' resource consumption before a handler runs
+type usageSample struct {
+	allocs     uint64
+	allocBytes uint64
+	goroutines int
+}
+
+// sampleUsage samples the process' resource consumption. runtime.ReadMemStats() stops the world, so usage is only
+// sampled in debug builds.
+func sampleUsage() usageSample {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return usageSample{allocs: stats.Mallocs, allocBytes: stats.TotalAlloc, goroutines: runtime.NumGoroutine()}
+}
+
+// record attributes the resources consumed since the sample was taken to the handler
+func (u *handlerUsage) record(before usageSample) {
+	after := sampleUsage()
+	atomic.AddUint64(&u.calls, 1)
+	atomic.AddUint64(&u.allocs, after.allocs-before.allocs)
+	atomic.AddUint64(&u.allocBytes, after.allocBytes-before.allocBytes)
+	if after.goroutines > before.goroutines {
+		atomic.AddUint64(&u.goroutines, uint64(after.goroutines-before.goroutines))
+	}
+}
+
+// snapshot gets the HandlerUsage. The usage of handlers which aren't accounted is empty.
+func (u *handlerUsage) snapshot() HandlerUsage {
+	if u == nil {
+		return HandlerUsage{}
+	}
+	return HandlerUsage{Calls: atomic.LoadUint64(&u.calls), Allocs: atomic.LoadUint64(&u.allocs),
+		AllocBytes: atomic.LoadUint64(&u.allocBytes), Goroutines: atomic.LoadUint64(&u.goroutines)}
+}
@@ -0,0 +1,114 @@
+package thevent
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// number of buckets used to track the rolling error rate
+const errorRateBuckets = 10
+
+// default number of handler runs within the window needed before the rolling error rate is evaluated
+const defaultErrorRateMinSamples = 10
+
+// ErrorRateAlert describes an Event's rolling error rate crossing the configured threshold
+type ErrorRateAlert struct {
+	Event *Event
+	// ErrorRate is the error rate of the Event's handlers over the Window
+	ErrorRate   float32
+	NumHandlers uint
+	NumErrors   uint
+	Window      time.Duration
+}
+
+type errorRateBucket struct {
+	epoch       int64
+	numHandlers uint
+	numErrors   uint
+}
+
+// errorRateWatchdog tracks an Event's rolling error rate using fixed size time buckets
+type errorRateWatchdog struct {
+	lock      sync.Mutex
+	event     *Event
+	threshold float32
+	window    time.Duration
+	alert     func(ErrorRateAlert)
+	now       func() time.Time
+
+	buckets [errorRateBuckets]errorRateBucket
+	// exceeded is true while the error rate is above the threshold so alerts are only sent when the threshold is
+	// crossed
+	exceeded bool
+}
+
+// WithErrorRateAlert configures the Event to monitor the rolling error rate of its handlers over the given window
+// and call alert whenever the error rate crosses above the threshold. alert is called again only after the error
+// rate has dropped back to or below the threshold. alert is called synchronously by the dispatching goroutine and
+// should not block. The results of all dispatches are monitored, not just the results of the WithResults variants.
+// The error rate is only evaluated once the handlers have run at least 10 times within the window, so that a few
+// failures don't raise an alert. See WithErrorRateMinSamples()
+func WithErrorRateAlert(threshold float32, window time.Duration, alert func(ErrorRateAlert)) Option {
+	return func(e *Event) error {
+		if threshold < 0.0 || threshold > 1.0 {
+			return TypeError{errors.New("Error rate threshold must be between 0.0 and 1.0")}
+		}
+		if window < errorRateBuckets {
+			return TypeError{errors.New("Error rate window is too small")}
+		}
+		if alert == nil {
+			return TypeError{errors.New("Error rate alert callback must not be nil")}
+		}
+		e.errorRateWatchdog = &errorRateWatchdog{event: e, threshold: threshold, window: window, alert: alert,
+			now: time.Now}
+		return nil
+	}
+}
+
+// WithErrorRateMinSamples configures the number of handler runs within the window needed before the Event's rolling
+// error rate is evaluated. See WithErrorRateAlert()
+func WithErrorRateMinSamples(n uint) Option {
+	return func(e *Event) error {
+		if n < 1 {
+			return TypeError{errors.New("Error rate minimum samples must be at least 1")}
+		}
+		e.errorRateMinSamples = n
+		return nil
+	}
+}
+
+func (w *errorRateWatchdog) record(err error) {
+	bucketWidth := int64(w.window / errorRateBuckets)
+	w.lock.Lock()
+	epoch := w.now().UnixNano() / bucketWidth
+	b := &w.buckets[epoch%errorRateBuckets]
+	if b.epoch != epoch {
+		*b = errorRateBucket{epoch: epoch}
+	}
+	b.numHandlers++
+	if err != nil {
+		b.numErrors++
+	}
+
+	alert := ErrorRateAlert{Event: w.event, Window: w.window}
+	for _, b := range w.buckets {
+		if b.epoch > epoch-errorRateBuckets {
+			alert.NumHandlers += b.numHandlers
+			alert.NumErrors += b.numErrors
+		}
+	}
+	alert.ErrorRate = float32(alert.NumErrors) / float32(alert.NumHandlers)
+	minSamples := w.event.errorRateMinSamples
+	if minSamples == 0 {
+		minSamples = defaultErrorRateMinSamples
+	}
+	exceeded := alert.NumHandlers >= minSamples && alert.ErrorRate > w.threshold
+	crossed := exceeded && !w.exceeded
+	w.exceeded = exceeded
+	w.lock.Unlock()
+
+	if crossed {
+		w.alert(alert)
+	}
+}
@@ -0,0 +1,152 @@
+package thevent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithErrorRateAlertInvalid(t *testing.T) {
+	alert := func(ErrorRateAlert) {}
+	testCases := []struct {
+		name      string
+		threshold float32
+		window    time.Duration
+		alert     func(ErrorRateAlert)
+	}{
+		{name: "negative threshold", threshold: -0.1, window: time.Minute, alert: alert},
+		{name: "threshold too large", threshold: 1.1, window: time.Minute, alert: alert},
+		{name: "window too small", threshold: 0.2, window: 1, alert: alert},
+		{name: "nil alert", threshold: 0.2, window: time.Minute},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := New(0, WithErrorRateAlert(tc.threshold, tc.window, tc.alert)); err == nil {
+				t.Error("Expected an error creating an event with an invalid error rate alert")
+			}
+		})
+	}
+}
+
+func TestWithErrorRateAlert(t *testing.T) {
+	var alerts []ErrorRateAlert
+	handler := func(ctx context.Context, fail bool) error {
+		if fail {
+			return errors.New("handler failed")
+		}
+		return nil
+	}
+	e, err := New(false, handler, WithErrorRateMinSamples(2), WithErrorRateAlert(0.2, 5*time.Minute,
+		func(a ErrorRateAlert) {
+			alerts = append(alerts, a)
+		}))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	now := time.Unix(0, 0)
+	e.errorRateWatchdog.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	dispatch := func(fail bool) {
+		if err := e.Dispatch(ctx, fail); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	}
+
+	for i := 0; i < 4; i++ {
+		dispatch(false)
+	}
+	dispatch(true) // 1/5 errors - not above threshold
+	if len(alerts) != 0 {
+		t.Fatal("Got unexpected alerts:", alerts)
+	}
+	dispatch(true) // 2/6 errors - crosses threshold
+	if len(alerts) != 1 {
+		t.Fatal("Expected 1 alert, got:", alerts)
+	}
+	if alerts[0].Event != e || alerts[0].NumHandlers != 6 || alerts[0].NumErrors != 2 {
+		t.Error("Got unexpected alert:", alerts[0])
+	}
+	dispatch(true) // still above threshold - no new alert
+	if len(alerts) != 1 {
+		t.Fatal("Expected 1 alert, got:", alerts)
+	}
+
+	// errors fall out of the window
+	now = now.Add(10 * time.Minute)
+	dispatch(false)
+	dispatch(true) // 1/2 errors - crosses threshold again
+	if len(alerts) != 2 {
+		t.Fatal("Expected 2 alerts, got:", alerts)
+	}
+	if alerts[1].NumHandlers != 2 || alerts[1].NumErrors != 1 {
+		t.Error("Got unexpected alert:", alerts[1])
+	}
+}
+
+func TestWithErrorRateAlertSkipped(t *testing.T) {
+	var alerts []ErrorRateAlert
+	e, err := New(false, func(ctx context.Context, fail bool) (Outcome, error) {
+		if fail {
+			return Outcome{}, errors.New("handler failed")
+		}
+		return Outcome{Skipped: true}, nil
+	}, WithErrorRateMinSamples(1), WithErrorRateAlert(0.5, 5*time.Minute, func(a ErrorRateAlert) {
+		alerts = append(alerts, a)
+	}))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	now := time.Unix(0, 0)
+	e.errorRateWatchdog.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if err := e.Dispatch(ctx, false); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	}
+	if err := e.Dispatch(ctx, true); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	// Skipped handlers aren't counted, so the only handler failed
+	if len(alerts) != 1 || alerts[0].NumHandlers != 1 || alerts[0].NumErrors != 1 {
+		t.Error("Got unexpected alerts:", alerts)
+	}
+}
+
+func TestWithErrorRateAlertMinSamples(t *testing.T) {
+	_, err := New(false, WithErrorRateMinSamples(0))
+	if err == nil || err.Error() != "Error rate minimum samples must be at least 1" {
+		t.Error("Expected minimum samples error, got:", err)
+	}
+
+	alerts := 0
+	e, err := New(false, func(ctx context.Context, fail bool) error {
+		if fail {
+			return errors.New("handler failed")
+		}
+		return nil
+	}, WithErrorRateAlert(0.5, 5*time.Minute, func(a ErrorRateAlert) { alerts++ }))
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	now := time.Unix(0, 0)
+	e.errorRateWatchdog.now = func() time.Time { return now }
+
+	for i := 0; i < 9; i++ {
+		if err := e.Dispatch(context.Background(), true); err != nil {
+			t.Fatal("Unexpected error dispatching:", err)
+		}
+	}
+	if alerts != 0 {
+		t.Fatal("Alerted before the minimum number of samples")
+	}
+	if err := e.Dispatch(context.Background(), true); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if alerts != 1 {
+		t.Error("Expected 1 alert once the minimum number of samples is reached, got:", alerts)
+	}
+}
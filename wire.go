@@ -0,0 +1,60 @@
+package thevent
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Wire adds the handlers declared by the func fields of the subscriber struct, or pointer to a struct, to the Events
+// registered on the Bus. Fields declare the names of the Events they handle using the subscribe tag, separating
+// multiple names with commas. e.g.
+//
+//	type subscriber struct {
+//		OrderCreated func(ctx context.Context, o OrderCreated) error `subscribe:"order.created"`
+//		Audit        func(ctx context.Context, a AuditRecord) error  `subscribe:"audit.user,audit.order"`
+//	}
+//
+// An error is returned and no handlers are added if a tagged field isn't an exported non-nil func, no Event is
+// registered with a tagged name, a field isn't a valid handler for the Event or adding a handler fails, e.g. since
+// it's a duplicate. Handlers added to other Events before adding a handler failed are removed, though Events
+// dispatched in the meantime may run them.
+func (b *Bus) Wire(subscriber interface{}) error {
+	v := reflect.Indirect(reflect.ValueOf(subscriber))
+	if v.Kind() != reflect.Struct {
+		return TypeError{fmt.Errorf("Unable to wire subscriber of type %T, expected a struct", subscriber)}
+	}
+	byEvent := map[*Event][]*handler{}
+	var order []*Event
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		tag, ok := f.Tag.Lookup("subscribe")
+		if !ok {
+			continue
+		}
+		if f.PkgPath != "" {
+			return TypeError{fmt.Errorf("Unable to wire unexported field %s", f.Name)}
+		}
+		fv := v.Field(i)
+		if fv.Kind() != reflect.Func || fv.IsNil() {
+			return TypeError{fmt.Errorf("Unable to wire field %s, expected a non-nil func", f.Name)}
+		}
+		for _, name := range strings.Split(tag, ",") {
+			name = strings.TrimSpace(name)
+			e, ok := b.Event(name)
+			if !ok {
+				return TypeError{fmt.Errorf("Unable to wire field %s, no event registered with name: %s", f.Name,
+					name)}
+			}
+			cH, err := e.newHandler(fv.Interface())
+			if err != nil {
+				return TypeError{fmt.Errorf("Unable to wire field %s to event %s: %v", f.Name, name, err)}
+			}
+			if _, ok := byEvent[e]; !ok {
+				order = append(order, e)
+			}
+			byEvent[e] = append(byEvent[e], cH)
+		}
+	}
+	return addEventsHandlers(order, byEvent)
+}
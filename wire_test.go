@@ -0,0 +1,103 @@
+package thevent_test
+
+import (
+	"context"
+	"testing"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestBusWire(t *testing.T) {
+	bus := thevent.NewBus()
+	created, err := bus.New("order.created", orderCreated{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	updated, err := bus.New("order.updated", orderCreated{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if _, err := bus.New("order.shipped", orderShipped{}); err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+
+	var calls []int
+	subscriber := struct {
+		Changed func(ctx context.Context, o orderCreated) error `subscribe:"order.created, order.updated"`
+		Ignored func(ctx context.Context, o orderCreated) error
+	}{
+		Changed: func(ctx context.Context, o orderCreated) error { // nolint: unparam
+			calls = append(calls, o.ID)
+			return nil
+		},
+	}
+	if err := bus.Wire(&subscriber); err != nil {
+		t.Fatal("Unable to wire subscriber:", err)
+	}
+	ctx := context.Background()
+	if err := created.Dispatch(ctx, orderCreated{ID: 1}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if err := updated.Dispatch(ctx, orderCreated{ID: 2}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(calls) != 2 || calls[0] != 1 || calls[1] != 2 {
+		t.Error("Unexpected calls:", calls)
+	}
+
+	handler := func(ctx context.Context, o orderCreated) error { return nil }
+	testCases := []struct {
+		name        string
+		subscriber  interface{}
+		expectedErr string
+	}{
+		{name: "not a struct", subscriber: 1, expectedErr: "Unable to wire subscriber of type int, expected a struct"},
+		{name: "unexported", subscriber: struct {
+			changed func(ctx context.Context, o orderCreated) error `subscribe:"order.created"`
+		}{handler}, expectedErr: "Unable to wire unexported field changed"},
+		{name: "nil", subscriber: struct {
+			Changed func(ctx context.Context, o orderCreated) error `subscribe:"order.created"`
+		}{}, expectedErr: "Unable to wire field Changed, expected a non-nil func"},
+		{name: "unregistered", subscriber: struct {
+			Changed func(ctx context.Context, o orderCreated) error `subscribe:"order.deleted"`
+		}{handler}, expectedErr: "Unable to wire field Changed, no event registered with name: order.deleted"},
+		{name: "invalid handler", subscriber: struct {
+			Changed func(ctx context.Context, o orderCreated) error `subscribe:"order.created,order.shipped"`
+		}{handler}, expectedErr: "Unable to wire field Changed to event order.shipped: Handler uses incorrect data type*"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errorMatchesGlob(t, bus.Wire(tc.subscriber), tc.expectedErr)
+		})
+	}
+	// No handlers are added when wiring fails
+	calls = nil
+	if err := created.Dispatch(ctx, orderCreated{ID: 3}); err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if len(calls) != 1 {
+		t.Error("Unexpected calls:", calls)
+	}
+}
+
+func TestBusWireRollback(t *testing.T) {
+	bus := thevent.NewBus()
+	created, err := bus.New("order.created", orderCreated{})
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	if _, err := bus.New("order.updated", orderCreated{}); err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	subscriber := struct {
+		Changed func(ctx context.Context, o orderCreated) error `subscribe:"order.created,order.updated,order.updated"`
+	}{
+		Changed: func(ctx context.Context, o orderCreated) error { return nil },
+	}
+	errorMatchesGlob(t, bus.Wire(subscriber), "Unable to add duplicate handler")
+	if handlers := created.Handlers(); len(handlers) != 0 {
+		t.Error("Handlers added before the failure should be removed:", handlers)
+	}
+}
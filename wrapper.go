@@ -0,0 +1,76 @@
+package thevent
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"time"
+)
+
+// TimeoutError is returned by a HandlerFunc wrapped using WithTimeout() when it doesn't finish in time. The Timeout
+// middleware of the theventmiddleware package also returns TimeoutErrors.
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("Handler timed out after %v", e.Timeout)
+}
+
+// WithTimeout cancels the context.Context passed to the HandlerFunc after the timeout and returns a transient
+// TimeoutError if the HandlerFunc hasn't finished by then, so that it may be retried using WithRetry() or
+// WithRetryPolicy(). A HandlerFunc ignoring the cancelation keeps running in the background, but its error is
+// discarded.
+func WithTimeout[T any](timeout time.Duration) HandlerWrapper[T] {
+	return func(next HandlerFunc[T]) HandlerFunc[T] {
+		return func(ctx context.Context, data T) error {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			// The HandlerFunc's error is never received after the timeout, so it mustn't block sending it
+			done := make(chan error, 1)
+			go func() { done <- next(ctx, data) }()
+			select {
+			case err := <-done:
+				return err
+			case <-ctx.Done():
+				if ctx.Err() != context.DeadlineExceeded {
+					return ctx.Err()
+				}
+				return Transient(TimeoutError{Timeout: timeout})
+			}
+		}
+	}
+}
+
+// WithRetry retries the HandlerFunc according to the RetryPolicy when it returns a retryable error, see
+// RetryPolicy. Unlike WithRetryPolicy(), the retries are run within the wrapped HandlerFunc, so they may be composed
+// with other wrappers, e.g. to time out every attempt using WithTimeout(). The last attempt's error is returned.
+// Policies allowing less than 1 attempt run the HandlerFunc once.
+func WithRetry[T any](policy RetryPolicy) HandlerWrapper[T] {
+	return func(next HandlerFunc[T]) HandlerFunc[T] {
+		return func(ctx context.Context, data T) error {
+			for attempts := 1; ; attempts++ {
+				err := next(ctx, data)
+				if !policy.shouldRetry(ctx, []reflect.Value{reflect.ValueOf(&err).Elem()}, attempts) {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// WithRecover recovers from panics of the HandlerFunc, returning a PanicError instead. Unlike Isolated(), only the
+// wrapped HandlerFunc is protected.
+func WithRecover[T any]() HandlerWrapper[T] {
+	return func(next HandlerFunc[T]) HandlerFunc[T] {
+		return func(ctx context.Context, data T) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = PanicError{Value: r, Stack: debug.Stack()}
+				}
+			}()
+			return next(ctx, data)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package thevent_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dhui/thevent"
+)
+
+func TestWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	h := thevent.Wrap(func(ctx context.Context, o order) error {
+		if o.Total > 0 {
+			<-release
+		}
+		return nil
+	}, thevent.WithTimeout[order](10*time.Millisecond))
+
+	ctx := context.Background()
+	if err := h(ctx, order{}); err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	err := h(ctx, order{Total: 1})
+	if !thevent.IsTransient(err) {
+		t.Error("Expected a transient error, got:", err)
+	}
+	errorMatchesGlob(t, err, "Handler timed out after 10ms")
+}
+
+func TestWithRetry(t *testing.T) {
+	attempts := 0
+	transientErr := errors.New("unavailable")
+	h := thevent.Wrap(func(ctx context.Context, o order) error {
+		attempts++
+		if attempts < o.Total {
+			return thevent.Transient(transientErr)
+		}
+		return nil
+	}, thevent.WithRetry[order](thevent.RetryPolicy{MaxAttempts: 3}))
+
+	e, err := thevent.NewTyped[order](h)
+	if err != nil {
+		t.Fatal("Unable to create event:", err)
+	}
+	ctx := context.Background()
+	if err := e.Dispatch(ctx, order{Total: 3}); err != nil {
+		t.Error("Unexpected error dispatching:", err)
+	}
+	if attempts != 3 {
+		t.Error("Expected 3 attempts, got:", attempts)
+	}
+
+	attempts = 0
+	res, err := e.DispatchWithResults(ctx, order{Total: 5})
+	if err != nil {
+		t.Fatal("Unexpected error dispatching:", err)
+	}
+	if attempts != 3 {
+		t.Error("Expected 3 attempts, got:", attempts)
+	}
+	if len(res.Errors) != 1 || !thevent.IsTransient(res.Errors[0]) {
+		t.Error("Expected the last attempt's error, got:", res.Errors)
+	}
+}
+
+func TestWithRecover(t *testing.T) {
+	h := thevent.Wrap(func(ctx context.Context, o order) error {
+		panic("boom")
+	}, thevent.WithRecover[order]())
+
+	err := h(context.Background(), order{})
+	p, ok := err.(thevent.PanicError)
+	if !ok {
+		t.Fatal("Expected a PanicError, got:", err)
+	}
+	if p.Value != "boom" || len(p.Stack) == 0 {
+		t.Error("Unexpected PanicError:", p)
+	}
+}